@@ -0,0 +1,140 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserNotFound       = errors.New("user not found")
+)
+
+// UserIdentity is the subset of a user that auth-service needs to issue tokens.
+type UserIdentity struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// UserClient talks to user-service's internal credential API, which owns the
+// users table and password hashes.
+type UserClient struct {
+	baseURL      string
+	serviceToken string
+	client       *http.Client
+}
+
+func NewUserClient(baseURL, serviceToken string) *UserClient {
+	return &UserClient{
+		baseURL:      baseURL,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NewUserClientFromEnv reads USER_SERVICE_URL and INTERNAL_SERVICE_TOKEN.
+func NewUserClientFromEnv() *UserClient {
+	baseURL := os.Getenv("USER_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://user-service:8080"
+	}
+	return NewUserClient(baseURL, os.Getenv("INTERNAL_SERVICE_TOKEN"))
+}
+
+func (c *UserClient) VerifyCredentials(ctx context.Context, email, password string) (*UserIdentity, error) {
+	var identity UserIdentity
+	status, err := c.do(ctx, http.MethodPost, "/internal/credentials/verify", map[string]string{
+		"email":    email,
+		"password": password,
+	}, &identity)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return &identity, nil
+	case http.StatusUnauthorized, http.StatusBadRequest:
+		return nil, ErrInvalidCredentials
+	default:
+		return nil, fmt.Errorf("verify credentials: unexpected status %d", status)
+	}
+}
+
+func (c *UserClient) GetUser(ctx context.Context, id uint) (*UserIdentity, error) {
+	var identity UserIdentity
+	status, err := c.do(ctx, http.MethodGet, fmt.Sprintf("/internal/users/%d", id), nil, &identity)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return &identity, nil
+	case http.StatusNotFound:
+		return nil, ErrUserNotFound
+	default:
+		return nil, fmt.Errorf("get user: unexpected status %d", status)
+	}
+}
+
+func (c *UserClient) ChangePassword(ctx context.Context, id uint, currentPassword, newPassword string) error {
+	status, err := c.do(ctx, http.MethodPut, fmt.Sprintf("/internal/users/%d/password", id), map[string]string{
+		"currentPassword": currentPassword,
+		"newPassword":     newPassword,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrUserNotFound
+	case http.StatusUnauthorized:
+		return ErrInvalidCredentials
+	default:
+		return fmt.Errorf("change password: unexpected status %d", status)
+	}
+}
+
+func (c *UserClient) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", c.serviceToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return 0, err
+		}
+	}
+	return resp.StatusCode, nil
+}
@@ -1,30 +1,25 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/auth-service/clients"
 	"github.com/ridhotamma/yourkasa/auth-service/dto"
 	"github.com/ridhotamma/yourkasa/auth-service/models"
 	"github.com/ridhotamma/yourkasa/auth-service/utils"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthController struct {
-	db *gorm.DB
+	db    *gorm.DB
+	users *clients.UserClient
 }
 
-func NewAuthController(db *gorm.DB) *AuthController {
-	return &AuthController{db: db}
-}
-
-type User struct {
-	ID           uint `gorm:"primarykey"`
-	Email        string
-	PasswordHash string
-	Role         string
+func NewAuthController(db *gorm.DB, users *clients.UserClient) *AuthController {
+	return &AuthController{db: db, users: users}
 }
 
 func (c *AuthController) Login(ctx *gin.Context) {
@@ -34,14 +29,13 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	var user User
-	if err := c.db.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
-		return
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+	user, err := c.users.VerifyCredentials(ctx.Request.Context(), input.Email, input.Password)
+	if err != nil {
+		if errors.Is(err, clients.ErrInvalidCredentials) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			return
+		}
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "User service unavailable"})
 		return
 	}
 
@@ -69,9 +63,6 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	// Update last logged in
-	c.db.Model(&user).Update("last_logged_in", time.Now())
-
 	ctx.JSON(http.StatusOK, dto.TokenResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -101,9 +92,13 @@ func (c *AuthController) RefreshToken(ctx *gin.Context) {
 	}
 
 	// Get user details
-	var user User
-	if err := c.db.First(&user, claims.UserID).Error; err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	user, err := c.users.GetUser(ctx.Request.Context(), claims.UserID)
+	if err != nil {
+		if errors.Is(err, clients.ErrUserNotFound) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return
+		}
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "User service unavailable"})
 		return
 	}
 
@@ -129,28 +124,16 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 		return
 	}
 
-	var user User
-	if err := c.db.First(&user, userID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-		return
-	}
-
-	// Verify current password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)); err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
-		return
-	}
-
-	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
-	}
-
-	// Update password
-	if err := c.db.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+	// Verify current password and update it in user-service
+	if err := c.users.ChangePassword(ctx.Request.Context(), userID, input.CurrentPassword, input.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, clients.ErrUserNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case errors.Is(err, clients.ErrInvalidCredentials):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		default:
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to update password"})
+		}
 		return
 	}
 
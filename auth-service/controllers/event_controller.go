@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/auth-service/dto"
+	"github.com/ridhotamma/yourkasa/auth-service/models"
+	"gorm.io/gorm"
+)
+
+type EventController struct {
+	db *gorm.DB
+}
+
+func NewEventController(db *gorm.DB) *EventController {
+	return &EventController{db: db}
+}
+
+// Receive handles user events delivered by user-service's webhook transport
+// and revokes refresh tokens of users whose role changed or who were deleted.
+func (c *EventController) Receive(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+
+	if !validSignature(os.Getenv("EVENT_WEBHOOK_SECRET"), body, ctx.GetHeader("X-Signature")) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	var event dto.UserEventDTO
+	if err := json.Unmarshal(body, &event); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event payload"})
+		return
+	}
+
+	switch event.Type {
+	case "user.role_changed", "user.deleted":
+		if err := c.db.Where("user_id = ?", event.AggregateID).Delete(&models.RefreshToken{}).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke refresh tokens"})
+			return
+		}
+		log.Printf("Revoked refresh tokens for user %d after %s", event.AggregateID, event.Type)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Event processed"})
+}
+
+func validSignature(secret string, body []byte, header string) bool {
+	if secret == "" {
+		return false
+	}
+
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
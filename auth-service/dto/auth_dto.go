@@ -19,3 +19,9 @@ type ChangePasswordDTO struct {
 	CurrentPassword string `json:"currentPassword" binding:"required"`
 	NewPassword     string `json:"newPassword" binding:"required,min=6"`
 }
+
+type UserEventDTO struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	AggregateID uint   `json:"aggregateId"`
+}
@@ -2,13 +2,21 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/auth-service/clients"
 	"github.com/ridhotamma/yourkasa/auth-service/controllers"
 	"github.com/ridhotamma/yourkasa/auth-service/middleware"
 	"gorm.io/gorm"
 )
 
 func SetupRoutes(r *gin.Engine, db *gorm.DB) {
-	authController := controllers.NewAuthController(db)
+	authController := controllers.NewAuthController(db, clients.NewUserClientFromEnv())
+	eventController := controllers.NewEventController(db)
+
+	// Service-to-service routes, not exposed through the gateway
+	internal := r.Group("/internal")
+	{
+		internal.POST("/events", eventController.Receive)
+	}
 
 	api := r.Group("/api/v1")
 	{
@@ -0,0 +1,384 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationConflict = errors.New("reservation conflict")
+	ErrBundleUnavailable   = errors.New("bundle unavailable")
+	ErrNotSellable         = errors.New("item not available")
+	ErrInvalidQuantity     = errors.New("invalid quantity")
+)
+
+// ReservationItem is a quantity of a product, variant or addon to hold, in
+// the unit it is sold in.
+type ReservationItem struct {
+	ItemType string  `json:"itemType"`
+	ItemID   uint    `json:"itemId"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ReservedItem is a held item with its cost price at the time it was
+// reserved. Quantity and UnitCost are per stock unit, e.g. grams, while
+// SellQuantity is in the unit it is sold in, e.g. kg.
+type ReservedItem struct {
+	ItemType     string  `json:"itemType"`
+	ItemID       uint    `json:"itemId"`
+	Quantity     int     `json:"quantity"`
+	SellQuantity float64 `json:"sellQuantity"`
+	UnitCost     float64 `json:"unitCost"`
+}
+
+// SelectedAddon is an addon picked for one unit of a product.
+type SelectedAddon struct {
+	AddonID  uint `json:"addonId"`
+	Quantity int  `json:"quantity"`
+}
+
+type ModifierError struct {
+	ModifierGroupID *uint  `json:"modifierGroupId,omitempty"`
+	AddonID         *uint  `json:"addonId,omitempty"`
+	Message         string `json:"message"`
+}
+
+// ModifierValidation is product-service's verdict on a cart item's addons.
+// AddonsPrice is the price of the addons for one unit of the product.
+type ModifierValidation struct {
+	Valid       bool            `json:"valid"`
+	Errors      []ModifierError `json:"errors"`
+	AddonsPrice float64         `json:"addonsPrice"`
+}
+
+type BundleSwap struct {
+	ComponentID uint `json:"componentId"`
+	ChoiceID    uint `json:"choiceId"`
+}
+
+// BundleLine is a product or variant sold as part of one bundle.
+type BundleLine struct {
+	ComponentID uint    `json:"componentId"`
+	ItemType    string  `json:"itemType"`
+	ItemID      uint    `json:"itemId"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unitPrice"`
+}
+
+// BundleQuote is the price of one bundle with the customer's swaps and how
+// many can be made from available stock.
+type BundleQuote struct {
+	GroupID uint         `json:"groupId"`
+	Name    string       `json:"name"`
+	Price   float64      `json:"price"`
+	Stock   int          `json:"stock"`
+	Lines   []BundleLine `json:"lines"`
+}
+
+// PriceItem identifies a product or variant to price.
+type PriceItem struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+}
+
+// ItemPrice is what an item sells for, with any scheduled price applied.
+type ItemPrice struct {
+	ItemType     string  `json:"itemType"`
+	ItemID       uint    `json:"itemId"`
+	RegularPrice float64 `json:"regularPrice"`
+	Price        float64 `json:"price"`
+	ScheduleID   *uint   `json:"scheduleId"`
+}
+
+// SellableItem identifies a product, variant or group (bundle) to check.
+type SellableItem struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+}
+
+// Sellability tells whether an item can be sold and, when not, why.
+type Sellability struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+	Sellable bool   `json:"sellable"`
+	Reason   string `json:"reason"`
+}
+
+// TaxItem identifies a product, variant, addon or group (bundle) to tax.
+type TaxItem struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+}
+
+// ItemTax is the tax class an item is sold under. Rate is a percentage;
+// Inclusive means the item's price already contains the tax.
+type ItemTax struct {
+	ItemType   string  `json:"itemType"`
+	ItemID     uint    `json:"itemId"`
+	TaxClassID *uint   `json:"taxClassId"`
+	Code       string  `json:"code"`
+	Rate       float64 `json:"rate"`
+	Inclusive  bool    `json:"inclusive"`
+}
+
+// ProductClient talks to product-service's internal stock reservation,
+// modifier validation, bundle, pricing and availability API.
+type ProductClient struct {
+	baseURL      string
+	serviceToken string
+	client       *http.Client
+}
+
+func NewProductClient(baseURL, serviceToken string) *ProductClient {
+	return &ProductClient{
+		baseURL:      baseURL,
+		serviceToken: serviceToken,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// NewProductClientFromEnv reads PRODUCT_SERVICE_URL and INTERNAL_SERVICE_TOKEN.
+func NewProductClientFromEnv() *ProductClient {
+	baseURL := os.Getenv("PRODUCT_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://product-service:8080"
+	}
+	return NewProductClient(baseURL, os.Getenv("INTERNAL_SERVICE_TOKEN"))
+}
+
+// Reserve holds stock for all items under key and returns the held items with
+// their cost prices. The stock leaves outlet, when set, on commit. The hold
+// does not expire: it backs a pending order until Commit or Release. Retrying
+// with the same key and items is safe.
+func (c *ProductClient) Reserve(ctx context.Context, key, reference, outlet string, items []ReservationItem) ([]ReservedItem, error) {
+	var reservation struct {
+		Items []ReservedItem `json:"items"`
+	}
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/reservations", map[string]interface{}{
+		"key":       key,
+		"reference": reference,
+		"outlet":    outlet,
+		"held":      true,
+		"items":     items,
+	}, &reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		return reservation.Items, nil
+	case http.StatusConflict:
+		if message == "" {
+			return nil, ErrInsufficientStock
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, message)
+	case http.StatusUnprocessableEntity:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidQuantity, message)
+	default:
+		return nil, fmt.Errorf("reserve stock: unexpected status %d: %s", status, message)
+	}
+}
+
+// Commit turns the reservation into sales once the order is fulfilled and
+// returns the items with the cost the sales took out of stock.
+func (c *ProductClient) Commit(ctx context.Context, key string) ([]ReservedItem, error) {
+	var reservation struct {
+		Items []ReservedItem `json:"items"`
+	}
+	if err := c.settle(ctx, key, "commit", &reservation); err != nil {
+		return nil, err
+	}
+	return reservation.Items, nil
+}
+
+// Release gives the reserved stock back, e.g. when an order is cancelled.
+func (c *ProductClient) Release(ctx context.Context, key string) error {
+	return c.settle(ctx, key, "release", nil)
+}
+
+func (c *ProductClient) settle(ctx context.Context, key, action string, out interface{}) error {
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/reservations/"+url.PathEscape(key)+"/"+action, nil, out)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrReservationNotFound
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrReservationConflict, message)
+	default:
+		return fmt.Errorf("%s reservation: unexpected status %d: %s", action, status, message)
+	}
+}
+
+// ValidateModifiers checks the addons picked for a product against its
+// modifier groups and addon settings.
+func (c *ProductClient) ValidateModifiers(ctx context.Context, productID uint, addons []SelectedAddon) (*ModifierValidation, error) {
+	var validation ModifierValidation
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/modifiers/validate", map[string]interface{}{
+		"productId": productID,
+		"addons":    addons,
+	}, &validation)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("validate modifiers: unexpected status %d: %s", status, message)
+	}
+	return &validation, nil
+}
+
+// QuoteBundle prices a bundle with the given swaps and resolves the items it
+// is made of.
+func (c *ProductClient) QuoteBundle(ctx context.Context, groupID uint, swaps []BundleSwap) (*BundleQuote, error) {
+	var quote BundleQuote
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/bundles/quote", map[string]interface{}{
+		"groupId": groupID,
+		"swaps":   swaps,
+	}, &quote)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return &quote, nil
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict:
+		return nil, fmt.Errorf("%w: %s", ErrBundleUnavailable, message)
+	default:
+		return nil, fmt.Errorf("quote bundle: unexpected status %d: %s", status, message)
+	}
+}
+
+// ResolvePrices returns the current price of each item, in the order given.
+func (c *ProductClient) ResolvePrices(ctx context.Context, items []PriceItem) ([]ItemPrice, error) {
+	var resolved struct {
+		Prices []ItemPrice `json:"prices"`
+	}
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/prices/resolve", map[string]interface{}{
+		"items": items,
+	}, &resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("resolve prices: unexpected status %d: %s", status, message)
+	}
+	if len(resolved.Prices) != len(items) {
+		return nil, fmt.Errorf("resolve prices: got %d prices for %d items", len(resolved.Prices), len(items))
+	}
+	return resolved.Prices, nil
+}
+
+// ResolveTaxes returns the tax class of each item, in the order given.
+func (c *ProductClient) ResolveTaxes(ctx context.Context, items []TaxItem) ([]ItemTax, error) {
+	var resolved struct {
+		Taxes []ItemTax `json:"taxes"`
+	}
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/taxes/resolve", map[string]interface{}{
+		"items": items,
+	}, &resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("resolve taxes: unexpected status %d: %s", status, message)
+	}
+	if len(resolved.Taxes) != len(items) {
+		return nil, fmt.Errorf("resolve taxes: got %d taxes for %d items", len(resolved.Taxes), len(items))
+	}
+	return resolved.Taxes, nil
+}
+
+// CheckSellable checks items against their status and availability rules at
+// an outlet. It returns ErrNotSellable, with the reasons, when any of them
+// cannot be sold now.
+func (c *ProductClient) CheckSellable(ctx context.Context, outlet string, items []SellableItem) error {
+	var check struct {
+		Sellable bool          `json:"sellable"`
+		Items    []Sellability `json:"items"`
+	}
+	status, message, err := c.do(ctx, http.MethodPost, "/internal/availability/check", map[string]interface{}{
+		"outlet": outlet,
+		"items":  items,
+	}, &check)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("check availability: unexpected status %d: %s", status, message)
+	}
+	if check.Sellable {
+		return nil
+	}
+
+	var reasons []string
+	for _, item := range check.Items {
+		if !item.Sellable {
+			reasons = append(reasons, fmt.Sprintf("%s %d: %s", item.ItemType, item.ItemID, item.Reason))
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotSellable, strings.Join(reasons, "; "))
+}
+
+// do sends the request and decodes a successful response into out. For failed
+// requests it returns the error message from the response body.
+func (c *ProductClient) do(ctx context.Context, method, path string, body, out interface{}) (int, string, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, "", err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Service-Token", c.serviceToken)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiError)
+		return resp.StatusCode, apiError.Error, nil
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return 0, "", err
+		}
+	}
+	return resp.StatusCode, "", nil
+}
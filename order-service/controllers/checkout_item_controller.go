@@ -2,20 +2,24 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/order-service/clients"
 	"github.com/ridhotamma/yourkasa/order-service/dto"
 	"github.com/ridhotamma/yourkasa/order-service/models"
 	"gorm.io/gorm"
 )
 
 type CheckoutController struct {
-	db *gorm.DB
+	db       *gorm.DB
+	products *clients.ProductClient
 }
 
-func NewCheckoutController(db *gorm.DB) *CheckoutController {
-	return &CheckoutController{db: db}
+func NewCheckoutController(db *gorm.DB, products *clients.ProductClient) *CheckoutController {
+	return &CheckoutController{db: db, products: products}
 }
 
 func (c *CheckoutController) AddToCart(ctx *gin.Context) {
@@ -27,38 +31,74 @@ func (c *CheckoutController) AddToCart(ctx *gin.Context) {
 
 	customerID := ctx.GetString("customer_id")
 
-	// Verify product exists and get its price
+	// Reject items that are off the menu right now, e.g. breakfast sets in
+	// the evening
+	item := clients.SellableItem{ItemType: "product", ItemID: input.ProductID}
+	switch {
+	case input.BundleID != nil:
+		item = clients.SellableItem{ItemType: "group", ItemID: *input.BundleID}
+	case input.VariantID != nil:
+		item = clients.SellableItem{ItemType: "variant", ItemID: *input.VariantID}
+	}
+	if !checkSellable(ctx, c.products, input.Outlet, []clients.SellableItem{item}) {
+		return
+	}
+
+	if input.BundleID != nil {
+		c.addBundleToCart(ctx, customerID, input)
+		return
+	}
+
+	// Verify product exists
 	var product models.Product
 	if err := c.db.First(&product, input.ProductID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
 
-	// Get variant price if specified
-	var price float64 = product.Price
+	if !wholeQuantity(input.Quantity) && (!product.Weighed || len(input.AddonsData) > 0) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be a whole number unless the product is sold by weight without addons"})
+		return
+	}
+
+	priceItem := clients.PriceItem{ItemType: "product", ItemID: product.ID}
 	if input.VariantID != nil {
 		var variant models.ProductVariant
 		if err := c.db.First(&variant, input.VariantID).Error; err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
 			return
 		}
-		price = variant.Price
+		priceItem = clients.PriceItem{ItemType: "variant", ItemID: variant.ID}
+	}
+
+	// Price the item or variant with any scheduled price in force
+	prices, err := c.products.ResolvePrices(ctx.Request.Context(), []clients.PriceItem{priceItem})
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to price item"})
+		return
+	}
+	price := prices[0].Price
+
+	// Validate addons against the product's modifier groups
+	addonsPrice, ok := c.validateAddons(ctx, input.ProductID, input.AddonsData)
+	if !ok {
+		return
 	}
 
-	// Validate and calculate addons
 	var addonsData []byte
 	if len(input.AddonsData) > 0 {
 		addonsData, _ = json.Marshal(input.AddonsData)
 	}
 
 	checkoutItem := models.CheckoutItem{
-		CustomerID: customerID,
-		ProductID:  input.ProductID,
-		VariantID:  input.VariantID,
-		Quantity:   input.Quantity,
-		Price:      price,
-		AddonsData: string(addonsData),
-		Notes:      input.Notes,
+		CustomerID:  customerID,
+		ProductID:   &input.ProductID,
+		VariantID:   input.VariantID,
+		Quantity:    input.Quantity,
+		Price:       price,
+		AddonsPrice: addonsPrice,
+		AddonsData:  string(addonsData),
+		Notes:       input.Notes,
 	}
 
 	if err := c.db.Create(&checkoutItem).Error; err != nil {
@@ -85,13 +125,37 @@ func (c *CheckoutController) UpdateCartItem(ctx *gin.Context) {
 		return
 	}
 
+	quantity := checkoutItem.Quantity
+	if input.Quantity > 0 {
+		quantity = input.Quantity
+	}
+	if !wholeQuantity(quantity) {
+		var product models.Product
+		if checkoutItem.ProductID != nil {
+			c.db.Select("id, weighed").First(&product, *checkoutItem.ProductID)
+		}
+		if !product.Weighed || len(input.AddonsData) > 0 || checkoutItem.AddonsData != "" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be a whole number unless the product is sold by weight without addons"})
+			return
+		}
+	}
+
 	updates := map[string]interface{}{}
 	if input.Quantity > 0 {
 		updates["quantity"] = input.Quantity
 	}
 	if len(input.AddonsData) > 0 {
+		if checkoutItem.ProductID == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Addons cannot be added to a bundle"})
+			return
+		}
+		addonsPrice, ok := c.validateAddons(ctx, *checkoutItem.ProductID, input.AddonsData)
+		if !ok {
+			return
+		}
 		addonsData, _ := json.Marshal(input.AddonsData)
 		updates["addons_data"] = string(addonsData)
+		updates["addons_price"] = addonsPrice
 	}
 	if input.Notes != "" {
 		updates["notes"] = input.Notes
@@ -128,6 +192,7 @@ func (c *CheckoutController) GetCart(ctx *gin.Context) {
 	if err := c.db.Where("customer_id = ?", customerID).
 		Preload("Product").
 		Preload("Variant").
+		Preload("Bundle").
 		Find(&items).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
 		return
@@ -135,3 +200,96 @@ func (c *CheckoutController) GetCart(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, items)
 }
+
+// Helper functions
+
+// addBundleToCart prices the bundle with the customer's swaps and stores the
+// items it resolved to, which are what checkout reserves and sells.
+func (c *CheckoutController) addBundleToCart(ctx *gin.Context, customerID string, input dto.CreateCheckoutItemDTO) {
+	if len(input.AddonsData) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Addons cannot be added to a bundle"})
+		return
+	}
+	if !wholeQuantity(input.Quantity) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Bundle quantity must be a whole number"})
+		return
+	}
+
+	swaps := make([]clients.BundleSwap, len(input.Swaps))
+	for i, swap := range input.Swaps {
+		swaps[i] = clients.BundleSwap{ComponentID: swap.ComponentID, ChoiceID: swap.ChoiceID}
+	}
+
+	quote, err := c.products.QuoteBundle(ctx.Request.Context(), *input.BundleID, swaps)
+	if err != nil {
+		if errors.Is(err, clients.ErrBundleUnavailable) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to price bundle"})
+		return
+	}
+	if float64(quote.Stock) < input.Quantity {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Not enough stock to make this bundle"})
+		return
+	}
+
+	bundleData, _ := json.Marshal(quote.Lines)
+	checkoutItem := models.CheckoutItem{
+		CustomerID: customerID,
+		BundleID:   input.BundleID,
+		Quantity:   input.Quantity,
+		Price:      quote.Price,
+		BundleData: string(bundleData),
+		Notes:      input.Notes,
+	}
+
+	if err := c.db.Create(&checkoutItem).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add item to cart"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Item added to cart", "id": checkoutItem.ID})
+}
+
+func wholeQuantity(quantity float64) bool {
+	return quantity == math.Trunc(quantity)
+}
+
+// validateAddons asks product-service whether the addons satisfy the
+// product's modifier rules and returns their price for one unit of the
+// product. It writes the error response when they do not.
+func (c *CheckoutController) validateAddons(ctx *gin.Context, productID uint, addons []dto.CheckoutAddonDTO) (float64, bool) {
+	validation, err := c.products.ValidateModifiers(ctx.Request.Context(), productID, selectedAddons(addons))
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to validate addons"})
+		return 0, false
+	}
+	if !validation.Valid {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid addon selection", "details": validation.Errors})
+		return 0, false
+	}
+	return validation.AddonsPrice, true
+}
+
+func selectedAddons(addons []dto.CheckoutAddonDTO) []clients.SelectedAddon {
+	selected := make([]clients.SelectedAddon, len(addons))
+	for i, addon := range addons {
+		selected[i] = clients.SelectedAddon{AddonID: addon.AddonID, Quantity: addon.Quantity}
+	}
+	return selected
+}
+
+// checkSellable asks product-service whether the items can be sold at the
+// outlet now and writes the error response when they cannot.
+func checkSellable(ctx *gin.Context, products *clients.ProductClient, outlet string, items []clients.SellableItem) bool {
+	if err := products.CheckSellable(ctx.Request.Context(), outlet, items); err != nil {
+		if errors.Is(err, clients.ErrNotSellable) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return false
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to check availability"})
+		return false
+	}
+	return true
+}
@@ -1,23 +1,34 @@
 package controllers
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/order-service/clients"
 	"github.com/ridhotamma/yourkasa/order-service/dto"
 	"github.com/ridhotamma/yourkasa/order-service/models"
 	"gorm.io/gorm"
 )
 
+var errAddonsUnavailable = errors.New("addons picked for a cart item are no longer available")
+
 type OrderController struct {
-	db *gorm.DB
+	db       *gorm.DB
+	products *clients.ProductClient
 }
 
-func NewOrderController(db *gorm.DB) *OrderController {
-	return &OrderController{db: db}
+func NewOrderController(db *gorm.DB, products *clients.ProductClient) *OrderController {
+	return &OrderController{db: db, products: products}
 }
 
 func (c *OrderController) Create(ctx *gin.Context) {
@@ -32,8 +43,9 @@ func (c *OrderController) Create(ctx *gin.Context) {
 	// Get selected cart items
 	var cartItems []models.CheckoutItem
 	if err := c.db.Where("customer_id = ? AND is_selected = ?", customerID, true).
-		Preload("Product").
+		Preload("Product.Category").
 		Preload("Variant").
+		Preload("Bundle").
 		Find(&cartItems).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
 		return
@@ -44,44 +56,105 @@ func (c *OrderController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !checkSellable(ctx, c.products, input.Outlet, sellableItems(cartItems)) {
+		return
+	}
+
+	// Cart prices may be stale, e.g. a scheduled price started or ended since
+	// the item was added, so items are repriced at checkout
+	if err := c.repriceCart(ctx.Request.Context(), cartItems); err != nil {
+		if errors.Is(err, errAddonsUnavailable) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to price cart items"})
+		return
+	}
+
+	taxes, err := c.lineTaxes(ctx.Request.Context(), cartItems)
+	if err != nil {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to resolve taxes"})
+		return
+	}
+
+	orderNumber, err := newOrderNumber()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate order number"})
+		return
+	}
+
+	// Hold stock before the order is written so it cannot be oversold. The
+	// hold lasts until the order is completed or cancelled, and the order
+	// number doubles as its idempotency key.
+	reserved, err := c.products.Reserve(ctx.Request.Context(), orderNumber, orderNumber, input.Outlet, reservationItems(cartItems))
+	if err != nil {
+		if errors.Is(err, clients.ErrInsufficientStock) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, clients.ErrInvalidQuantity) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reserve stock"})
+		return
+	}
+
 	// Start transaction
 	tx := c.db.Begin()
 
 	// Create order
-	orderNumber := fmt.Sprintf("ORD-%d-%s", time.Now().Unix(), customerID[:8])
-	var subtotal float64
+	var subtotal, taxAmount, exclusiveTax float64
 	var orderItems []models.OrderItem
 
-	for _, item := range cartItems {
-		// Calculate item total
-		itemTotal := item.Price * float64(item.Quantity)
+	for i, item := range cartItems {
+		// Addons are charged with the product they modify and taxed with it
+		itemTotal := math.Round((item.Price+item.AddonsPrice)*item.Quantity*100) / 100
 
-		// Add addons cost if any
-		if item.AddonsData != "" {
-			var addons []dto.CheckoutAddonDTO
-			json.Unmarshal([]byte(item.AddonsData), &addons)
-			// Calculate addons cost (you'll need to fetch addon prices)
-		}
+		// Snapshot the cost of goods sold for margin reporting
+		unitCost := itemUnitCost(item, reserved)
 
 		orderItem := models.OrderItem{
 			ProductID:   item.ProductID,
 			VariantID:   item.VariantID,
-			ProductName: item.Product.Name,
+			BundleID:    item.BundleID,
 			Quantity:    item.Quantity,
 			Price:       item.Price,
+			AddonsPrice: item.AddonsPrice,
 			SubTotal:    itemTotal,
+			TaxCode:     taxes[i].Code,
+			TaxRate:     taxes[i].Rate,
+			UnitCost:    unitCost,
+			CostTotal:   math.Round(unitCost*item.Quantity*100) / 100,
 			AddonsData:  item.AddonsData,
+			BundleData:  item.BundleData,
+		}
+		if item.Product != nil {
+			orderItem.ProductName = item.Product.Name
+			orderItem.CategoryID = item.Product.CategoryID
+			orderItem.CategoryName = item.Product.Category.Name
+		}
+		if item.Bundle != nil {
+			orderItem.ProductName = item.Bundle.Name
 		}
 		if item.Variant != nil {
 			orderItem.VariantName = item.Variant.Name
 		}
 
+		// Inclusive tax is already part of the line total; exclusive tax is
+		// charged on top of it
+		orderItem.TaxInclusive = taxes[i].Inclusive
+		orderItem.TaxAmount = lineTax(itemTotal, taxes[i].Rate, taxes[i].Inclusive)
+		if !orderItem.TaxInclusive {
+			exclusiveTax += orderItem.TaxAmount
+		}
+
 		orderItems = append(orderItems, orderItem)
 		subtotal += itemTotal
+		taxAmount += orderItem.TaxAmount
 	}
 
-	// Calculate tax and shipping (implement your business logic)
-	taxAmount := subtotal * 0.1   // 10% tax example
+	// Calculate shipping (implement your business logic)
 	shippingAmount := float64(10) // Fixed shipping example
 
 	order := models.Order{
@@ -91,7 +164,7 @@ func (c *OrderController) Create(ctx *gin.Context) {
 		SubtotalAmount:  subtotal,
 		TaxAmount:       taxAmount,
 		ShippingAmount:  shippingAmount,
-		TotalAmount:     subtotal + taxAmount + shippingAmount,
+		TotalAmount:     math.Round((subtotal+exclusiveTax+shippingAmount)*100) / 100,
 		ShippingAddress: input.ShippingAddress,
 		BillingAddress:  input.BillingAddress,
 		PaymentMethod:   input.PaymentMethod,
@@ -101,6 +174,7 @@ func (c *OrderController) Create(ctx *gin.Context) {
 
 	if err := tx.Create(&order).Error; err != nil {
 		tx.Rollback()
+		c.releaseReservation(ctx, orderNumber)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
 		return
 	}
@@ -108,6 +182,7 @@ func (c *OrderController) Create(ctx *gin.Context) {
 	// Clear cart items
 	if err := tx.Where("id IN ?", getCartItemIDs(cartItems)).Delete(&models.CheckoutItem{}).Error; err != nil {
 		tx.Rollback()
+		c.releaseReservation(ctx, orderNumber)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear cart"})
 		return
 	}
@@ -166,6 +241,11 @@ func (c *OrderController) Cancel(ctx *gin.Context) {
 		"canceled_at": now,
 	}
 
+	if err := c.products.Release(ctx.Request.Context(), order.OrderNumber); err != nil && !errors.Is(err, clients.ErrReservationNotFound) {
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to release reserved stock"})
+		return
+	}
+
 	if err := c.db.Model(&order).Updates(updates).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order"})
 		return
@@ -174,7 +254,90 @@ func (c *OrderController) Cancel(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Order cancelled successfully"})
 }
 
+// Complete marks a pending order as fulfilled and turns its reserved stock
+// into sales, which also uses up the ingredients in the items' recipes. It is
+// for staff handing the order over, so it is not limited to their own orders.
+func (c *OrderController) Complete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var order models.Order
+	if err := c.db.First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	if order.Status != "pending" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only pending orders can be completed"})
+		return
+	}
+
+	committed, err := c.products.Commit(ctx.Request.Context(), order.OrderNumber)
+	if err != nil {
+		if errors.Is(err, clients.ErrReservationNotFound) || errors.Is(err, clients.ErrReservationConflict) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Reserved stock is no longer held for this order"})
+			return
+		}
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "Failed to commit reserved stock"})
+		return
+	}
+
+	var orderItems []models.OrderItem
+	if err := c.db.Where("order_id = ?", order.ID).Find(&orderItems).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch order items"})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	// Replace the cost snapshot taken at checkout with what the sales cost,
+	// which differs under FIFO costing or when cost prices changed since
+	for _, item := range orderItems {
+		unitCost := itemUnitCost(models.CheckoutItem{
+			ProductID:  item.ProductID,
+			VariantID:  item.VariantID,
+			BundleID:   item.BundleID,
+			AddonsData: item.AddonsData,
+			BundleData: item.BundleData,
+		}, committed)
+		if err := tx.Model(&item).Updates(map[string]interface{}{
+			"unit_cost":  unitCost,
+			"cost_total": math.Round(unitCost*item.Quantity*100) / 100,
+		}).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order costs"})
+			return
+		}
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":       "completed",
+		"completed_at": now,
+	}
+
+	if err := tx.Model(&order).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete order"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Order completed successfully"})
+}
+
 // Helper functions
+
+// newOrderNumber returns a number that cannot repeat, even for two checkouts
+// by the same customer in the same second. It is also the reservation key, so
+// a repeat would replay another order's reservation.
+func newOrderNumber() (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ORD-%s-%s", time.Now().Format("20060102"), strings.ToUpper(hex.EncodeToString(suffix))), nil
+}
+
 func getCartItemIDs(items []models.CheckoutItem) []uint {
 	ids := make([]uint, len(items))
 	for i, item := range items {
@@ -182,3 +345,170 @@ func getCartItemIDs(items []models.CheckoutItem) []uint {
 	}
 	return ids
 }
+
+// reservationItems converts cart lines into the stock to hold: the variant
+// when one is selected, otherwise the product, plus every addon per unit. A
+// bundle holds the items it is made of.
+func reservationItems(items []models.CheckoutItem) []clients.ReservationItem {
+	var result []clients.ReservationItem
+	for _, item := range items {
+		switch {
+		case item.BundleID != nil:
+			for _, line := range bundleLines(item) {
+				result = append(result, clients.ReservationItem{ItemType: line.ItemType, ItemID: line.ItemID, Quantity: float64(line.Quantity) * item.Quantity})
+			}
+		case item.VariantID != nil:
+			result = append(result, clients.ReservationItem{ItemType: "variant", ItemID: *item.VariantID, Quantity: item.Quantity})
+		default:
+			result = append(result, clients.ReservationItem{ItemType: "product", ItemID: *item.ProductID, Quantity: item.Quantity})
+		}
+
+		if item.AddonsData != "" {
+			var addons []dto.CheckoutAddonDTO
+			json.Unmarshal([]byte(item.AddonsData), &addons)
+			for _, addon := range addons {
+				result = append(result, clients.ReservationItem{ItemType: "addon", ItemID: addon.AddonID, Quantity: float64(addon.Quantity) * item.Quantity})
+			}
+		}
+	}
+	return result
+}
+
+// itemUnitCost adds up the reserved cost of a cart line's product or variant
+// and its addons, or of a bundle's items, for one sell unit.
+func itemUnitCost(item models.CheckoutItem, reserved []clients.ReservedItem) float64 {
+	costs := make(map[string]float64, len(reserved))
+	for _, r := range reserved {
+		// Reserved costs are per stock unit, e.g. per gram of an item sold
+		// by the kg
+		cost := r.UnitCost
+		if r.SellQuantity > 0 {
+			cost = r.UnitCost * float64(r.Quantity) / r.SellQuantity
+		}
+		costs[fmt.Sprintf("%s:%d", r.ItemType, r.ItemID)] = cost
+	}
+
+	var cost float64
+	switch {
+	case item.BundleID != nil:
+		for _, line := range bundleLines(item) {
+			cost += costs[fmt.Sprintf("%s:%d", line.ItemType, line.ItemID)] * float64(line.Quantity)
+		}
+	case item.VariantID != nil:
+		cost = costs[fmt.Sprintf("variant:%d", *item.VariantID)]
+	default:
+		cost = costs[fmt.Sprintf("product:%d", *item.ProductID)]
+	}
+
+	if item.AddonsData != "" {
+		var addons []dto.CheckoutAddonDTO
+		json.Unmarshal([]byte(item.AddonsData), &addons)
+		for _, addon := range addons {
+			cost += costs[fmt.Sprintf("addon:%d", addon.AddonID)] * float64(addon.Quantity)
+		}
+	}
+	return cost
+}
+
+func bundleLines(item models.CheckoutItem) []clients.BundleLine {
+	var lines []clients.BundleLine
+	json.Unmarshal([]byte(item.BundleData), &lines)
+	return lines
+}
+
+// sellableItems lists what each cart line sells: its bundle, variant or
+// product.
+func sellableItems(items []models.CheckoutItem) []clients.SellableItem {
+	sellable := make([]clients.SellableItem, 0, len(items))
+	for _, item := range items {
+		switch {
+		case item.BundleID != nil:
+			sellable = append(sellable, clients.SellableItem{ItemType: "group", ItemID: *item.BundleID})
+		case item.VariantID != nil:
+			sellable = append(sellable, clients.SellableItem{ItemType: "variant", ItemID: *item.VariantID})
+		case item.ProductID != nil:
+			sellable = append(sellable, clients.SellableItem{ItemType: "product", ItemID: *item.ProductID})
+		}
+	}
+	return sellable
+}
+
+// lineTaxes looks up the tax class of each cart line, in cart order.
+func (c *OrderController) lineTaxes(ctx context.Context, items []models.CheckoutItem) ([]clients.ItemTax, error) {
+	sellable := sellableItems(items)
+	if len(sellable) != len(items) {
+		return nil, errors.New("cart line without a product or bundle")
+	}
+	taxItems := make([]clients.TaxItem, len(sellable))
+	for i, item := range sellable {
+		taxItems[i] = clients.TaxItem{ItemType: item.ItemType, ItemID: item.ItemID}
+	}
+	return c.products.ResolveTaxes(ctx, taxItems)
+}
+
+// lineTax is the tax on a line total at rate percent. For inclusive rates it
+// is the part of the total that is tax.
+func lineTax(total, rate float64, inclusive bool) float64 {
+	if inclusive {
+		return math.Round((total-total/(1+rate/100))*100) / 100
+	}
+	return math.Round(total*rate/100*100) / 100
+}
+
+// repriceCart sets each product and variant line to its current price and
+// its addons to their current price, checking the addons are still on offer.
+// Bundles keep the price quoted when they were added.
+func (c *OrderController) repriceCart(ctx context.Context, items []models.CheckoutItem) error {
+	for i, item := range items {
+		if item.AddonsData == "" || item.ProductID == nil {
+			continue
+		}
+		var addons []dto.CheckoutAddonDTO
+		if err := json.Unmarshal([]byte(item.AddonsData), &addons); err != nil {
+			return err
+		}
+		validation, err := c.products.ValidateModifiers(ctx, *item.ProductID, selectedAddons(addons))
+		if err != nil {
+			return err
+		}
+		if !validation.Valid {
+			return errAddonsUnavailable
+		}
+		items[i].AddonsPrice = validation.AddonsPrice
+	}
+
+	var priceItems []clients.PriceItem
+	var lines []int
+	for i, item := range items {
+		switch {
+		case item.VariantID != nil:
+			priceItems = append(priceItems, clients.PriceItem{ItemType: "variant", ItemID: *item.VariantID})
+		case item.ProductID != nil:
+			priceItems = append(priceItems, clients.PriceItem{ItemType: "product", ItemID: *item.ProductID})
+		default:
+			continue
+		}
+		lines = append(lines, i)
+	}
+	if len(priceItems) == 0 {
+		return nil
+	}
+
+	prices, err := c.products.ResolvePrices(ctx, priceItems)
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		items[line].Price = prices[i].Price
+	}
+	return nil
+}
+
+// releaseReservation gives back stock held for an order that failed to save.
+// Order holds do not expire, so a failure is logged for someone to release
+// the hold by hand.
+func (c *OrderController) releaseReservation(ctx *gin.Context, orderNumber string) {
+	if err := c.products.Release(ctx.Request.Context(), orderNumber); err != nil {
+		log.Printf("Failed to release stock reservation %s: %v", orderNumber, err)
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/order-service/dto"
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+type ReportController struct {
+	db *gorm.DB
+}
+
+func NewReportController(db *gorm.DB) *ReportController {
+	return &ReportController{db: db}
+}
+
+// Grouping expressions for the margin report, keyed by groupBy.
+var marginGroups = map[string]struct{ key, name string }{
+	"product":  {"coalesce(oi.product_id::text, 'bundle:' || oi.bundle_id)", "max(oi.product_name)"},
+	"category": {"oi.category_id::text", "coalesce(nullif(max(oi.category_name), ''), 'Uncategorized')"},
+	"day":      {"to_char(date_trunc('day', o.created_at), 'YYYY-MM-DD')", "''"},
+	"week":     {"to_char(date_trunc('week', o.created_at), 'YYYY-MM-DD')", "''"},
+	"month":    {"to_char(date_trunc('month', o.created_at), 'YYYY-MM')", "''"},
+}
+
+// Margin reports revenue, cost of goods and gross margin for orders placed in
+// a period, grouped by product, category or period. Only completed orders
+// count unless another status is asked for; pending orders carry the cost
+// held at checkout rather than the cost of the sale. Cancelled orders are
+// always excluded.
+func (c *ReportController) Margin(ctx *gin.Context) {
+	var params dto.MarginReportQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := time.Parse(dateLayout, params.From)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse(dateLayout, params.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+	if to.Before(from) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The to date must not be before the from date"})
+		return
+	}
+
+	statuses := []string{params.Status}
+	if params.Status == "open" {
+		statuses = []string{"pending", "completed"}
+	}
+
+	group := marginGroups[params.GroupBy]
+	var rows []dto.MarginRowDTO
+	if err := c.db.Table("order_items oi").
+		Joins("JOIN orders o ON o.id = oi.order_id AND o.deleted_at IS NULL").
+		Select(group.key+" AS key, "+group.name+` AS name,
+			sum(oi.quantity) AS quantity,
+			sum(oi.sub_total) AS revenue,
+			sum(oi.cost_total) AS cost,
+			count(*) FILTER (WHERE oi.unit_cost = 0) AS missing_costs`).
+		Where("oi.deleted_at IS NULL AND o.status IN ?", statuses).
+		Where("o.created_at >= ? AND o.created_at < ?", from, to.AddDate(0, 0, 1)).
+		Group(group.key).
+		Order("key").
+		Scan(&rows).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build margin report"})
+		return
+	}
+
+	report := dto.MarginReportDTO{
+		From:    params.From,
+		To:      params.To,
+		GroupBy: params.GroupBy,
+		Status:  params.Status,
+		Rows:    make([]dto.MarginRowDTO, 0, len(rows)),
+		Total:   dto.MarginRowDTO{Key: "total", Name: "Total"},
+	}
+	for _, row := range rows {
+		if row.Name == "" {
+			row.Name = row.Key
+		}
+		report.Rows = append(report.Rows, withMargin(row))
+
+		report.Total.Quantity += row.Quantity
+		report.Total.Revenue += row.Revenue
+		report.Total.Cost += row.Cost
+		report.Total.MissingCosts += row.MissingCosts
+	}
+	report.Total = withMargin(report.Total)
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// Helper functions
+func withMargin(row dto.MarginRowDTO) dto.MarginRowDTO {
+	row.Revenue = math.Round(row.Revenue*100) / 100
+	row.Cost = math.Round(row.Cost*100) / 100
+	row.GrossProfit = math.Round((row.Revenue-row.Cost)*100) / 100
+	if row.Revenue != 0 {
+		row.MarginPct = math.Round(row.GrossProfit/row.Revenue*10000) / 100
+	}
+	return row
+}
@@ -1,11 +1,21 @@
 package dto
 
+// CreateCheckoutItemDTO adds either a product, optionally a variant of it, or
+// a bundle with its swaps.
 type CreateCheckoutItemDTO struct {
-	ProductID  uint               `json:"productId" binding:"required"`
+	ProductID  uint               `json:"productId" binding:"required_without=BundleID"`
 	VariantID  *uint              `json:"variantId"`
-	Quantity   int                `json:"quantity" binding:"required,gt=0"`
+	BundleID   *uint              `json:"bundleId"`
+	Swaps      []BundleSwapDTO    `json:"swaps" binding:"dive"`
+	Quantity   float64            `json:"quantity" binding:"required,gt=0"` // Decimal only for weighed products
 	AddonsData []CheckoutAddonDTO `json:"addonsData"`
 	Notes      string             `json:"notes"`
+	Outlet     string             `json:"outlet"` // Outlet whose availability rules apply
+}
+
+type BundleSwapDTO struct {
+	ComponentID uint `json:"componentId" binding:"required"`
+	ChoiceID    uint `json:"choiceId" binding:"required"`
 }
 
 type CheckoutAddonDTO struct {
@@ -14,7 +24,7 @@ type CheckoutAddonDTO struct {
 }
 
 type UpdateCheckoutItemDTO struct {
-	Quantity   int                `json:"quantity" binding:"omitempty,gt=0"`
+	Quantity   float64            `json:"quantity" binding:"omitempty,gt=0"`
 	AddonsData []CheckoutAddonDTO `json:"addonsData"`
 	Notes      string             `json:"notes"`
 	IsSelected *bool              `json:"isSelected"`
@@ -5,4 +5,32 @@ type CreateOrderDTO struct {
 	BillingAddress  string `json:"billingAddress" binding:"required"`
 	PaymentMethod   string `json:"paymentMethod" binding:"required"`
 	Notes           string `json:"notes"`
+	Outlet          string `json:"outlet"` // Outlet whose availability rules apply and whose stock the sale leaves
+}
+
+type MarginReportQuery struct {
+	From    string `form:"from" binding:"required"` // YYYY-MM-DD, inclusive
+	To      string `form:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+	GroupBy string `form:"groupBy,default=product" binding:"oneof=product category day week month"`
+	Status  string `form:"status,default=completed" binding:"oneof=completed pending open"` // open is pending and completed
+}
+
+type MarginRowDTO struct {
+	Key          string  `json:"key"` // Product or category ID, or the start of the period
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	Revenue      float64 `json:"revenue"`
+	Cost         float64 `json:"cost"`
+	GrossProfit  float64 `json:"grossProfit"`
+	MarginPct    float64 `json:"marginPct"`
+	MissingCosts int     `json:"missingCosts"` // Lines sold without a recorded cost
+}
+
+type MarginReportDTO struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	GroupBy string         `json:"groupBy"`
+	Status  string         `json:"status"`
+	Rows    []MarginRowDTO `json:"rows"`
+	Total   MarginRowDTO   `json:"total"`
 }
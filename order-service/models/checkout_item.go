@@ -6,14 +6,18 @@ import (
 
 type CheckoutItem struct {
 	gorm.Model
-	CustomerID string          `json:"customerId" gorm:"not null"`
-	ProductID  uint            `json:"productId" gorm:"not null"`
-	VariantID  *uint           `json:"variantId"`
-	Quantity   int             `json:"quantity" gorm:"not null"`
-	Price      float64         `json:"price" gorm:"not null"`
-	Product    Product         `json:"product"`
-	Variant    *ProductVariant `json:"variant"`
-	AddonsData string          `json:"addonsData" gorm:"type:jsonb"` // Stores selected addons and their quantities
-	Notes      string          `json:"notes"`
-	IsSelected bool            `json:"isSelected" gorm:"default:true"`
+	CustomerID  string          `json:"customerId" gorm:"not null"`
+	ProductID   *uint           `json:"productId"` // Nil for bundles
+	VariantID   *uint           `json:"variantId"`
+	BundleID    *uint           `json:"bundleId"`
+	Quantity    float64         `json:"quantity" gorm:"type:decimal(12,3);not null"` // In the sell unit; decimal only for weighed products
+	Price       float64         `json:"price" gorm:"not null"`
+	AddonsPrice float64         `json:"addonsPrice" gorm:"type:decimal(12,2);default:0"` // Addons picked for one unit, repriced at checkout
+	Product     *Product        `json:"product"`
+	Variant     *ProductVariant `json:"variant"`
+	Bundle      *ProductGroup   `json:"bundle,omitempty" gorm:"foreignKey:BundleID"`
+	AddonsData  string          `json:"addonsData" gorm:"type:jsonb"` // Stores selected addons and their quantities
+	BundleData  string          `json:"bundleData" gorm:"type:jsonb"` // Items a bundle is made of, as quoted when it was added
+	Notes       string          `json:"notes"`
+	IsSelected  bool            `json:"isSelected" gorm:"default:true"`
 }
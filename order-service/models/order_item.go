@@ -6,14 +6,25 @@ import (
 
 type OrderItem struct {
 	gorm.Model
-	OrderID     uint    `json:"orderId" gorm:"not null"`
-	ProductID   uint    `json:"productId" gorm:"not null"`
-	VariantID   *uint   `json:"variantId"`
-	ProductName string  `json:"productName" gorm:"not null"`
-	VariantName string  `json:"variantName"`
-	Quantity    int     `json:"quantity" gorm:"not null"`
-	Price       float64 `json:"price" gorm:"not null"`
-	SubTotal    float64 `json:"subTotal" gorm:"not null"`
-	AddonsData  string  `json:"addonsData" gorm:"type:jsonb"`
-	Order       Order   `json:"-"`
+	OrderID      uint    `json:"orderId" gorm:"not null"`
+	ProductID    *uint   `json:"productId"` // Nil for bundles
+	VariantID    *uint   `json:"variantId"`
+	BundleID     *uint   `json:"bundleId"`
+	ProductName  string  `json:"productName" gorm:"not null"`
+	VariantName  string  `json:"variantName"`
+	Quantity     float64 `json:"quantity" gorm:"type:decimal(12,3);not null"` // In the sell unit, e.g. 0.25 kg
+	Price        float64 `json:"price" gorm:"not null"`
+	AddonsPrice  float64 `json:"addonsPrice" gorm:"type:decimal(12,2);default:0"` // Addons per unit, charged in SubTotal at the line's tax rate
+	SubTotal     float64 `json:"subTotal" gorm:"not null"`
+	TaxCode      string  `json:"taxCode" gorm:"type:varchar(20)"`
+	TaxRate      float64 `json:"taxRate" gorm:"type:decimal(5,2);default:0"`
+	TaxInclusive bool    `json:"taxInclusive" gorm:"default:false"` // SubTotal already contains TaxAmount
+	TaxAmount    float64 `json:"taxAmount" gorm:"type:decimal(12,2);default:0"`
+	UnitCost     float64 `json:"unitCost" gorm:"type:decimal(12,4);default:0"` // Cost per unit, addons included, when placed; the cost of the sale once completed
+	CostTotal    float64 `json:"costTotal" gorm:"type:decimal(12,2);default:0"`
+	CategoryID   uint    `json:"categoryId" gorm:"index"`
+	CategoryName string  `json:"categoryName"`
+	AddonsData   string  `json:"addonsData" gorm:"type:jsonb"`
+	BundleData   string  `json:"bundleData" gorm:"type:jsonb"` // Items the bundle was made of
+	Order        Order   `json:"-"`
 }
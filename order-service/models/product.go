@@ -14,6 +14,7 @@ type Product struct {
 	Price            float64          `json:"price" gorm:"not null"`
 	Stock            int              `json:"stock" gorm:"not null"`
 	SKU              string           `json:"sku" gorm:"uniqueIndex;not null"`
+	BarCode          string           `json:"barCode" gorm:"index"`
 	ImageURL         string           `json:"imageUrl"`
 	LastSoldAt       *time.Time       `json:"lastSoldAt"`
 	CategoryID       uint             `json:"categoryId" gorm:"not null"`
@@ -26,4 +27,5 @@ type Product struct {
 	Weight           float64          `json:"weight" gorm:"type:decimal(10,2)"`                // in kg
 	Dimensions       string           `json:"dimensions"`                                      // JSON string storing length, width, height
 	Tags             string           `json:"tags"`                                            // Comma-separated tags
+	Weighed          bool             `json:"weighed"`                                         // Sold in decimal quantities of its sell unit
 }
@@ -10,6 +10,7 @@ type ProductAddon struct {
 	Description string    `json:"description"`
 	Price       float64   `json:"price" gorm:"not null"`
 	SKU         string    `json:"sku" gorm:"uniqueIndex;not null"`
+	BarCode     string    `json:"barCode" gorm:"index"`
 	ImageURL    string    `json:"imageUrl"`
 	Stock       int       `json:"stock" gorm:"not null"`
 	IsRequired  bool      `json:"isRequired" gorm:"default:false"`
@@ -17,6 +17,6 @@ type ProductVariant struct {
 	Status     string  `json:"status" gorm:"type:varchar(20);default:'active'"`
 	Weight     float64 `json:"weight" gorm:"type:decimal(10,2)"`
 	Dimensions string  `json:"dimensions"`
-	BarCode    string  `json:"barCode"`
+	BarCode    string  `json:"barCode" gorm:"index"`
 	Product    Product `json:"-"`
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/order-service/clients"
 	"github.com/ridhotamma/yourkasa/order-service/controllers"
 	"github.com/ridhotamma/yourkasa/order-service/middleware"
 	"gorm.io/gorm"
@@ -9,8 +10,10 @@ import (
 
 func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 	// Initialize controllers
-	checkoutController := controllers.NewCheckoutController(db)
-	orderController := controllers.NewOrderController(db)
+	products := clients.NewProductClientFromEnv()
+	checkoutController := controllers.NewCheckoutController(db, products)
+	orderController := controllers.NewOrderController(db, products)
+	reportController := controllers.NewReportController(db)
 
 	api := r.Group("/api/v1")
 	{
@@ -32,6 +35,21 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 			orders.GET("/", orderController.List)
 			orders.GET("/:id", orderController.GetByID)
 			orders.POST("/:id/cancel", orderController.Cancel)
+
+			// Staff only routes
+			staffOrders := orders.Group("/")
+			staffOrders.Use(middleware.RequireRole("admin", "owner", "cashier"))
+			{
+				staffOrders.POST("/:id/complete", orderController.Complete)
+			}
+		}
+
+		// Report routes
+		reports := api.Group("/reports")
+		reports.Use(middleware.AuthMiddleware())
+		reports.Use(middleware.RequireRole("admin", "owner"))
+		{
+			reports.GET("/margin", reportController.Margin)
 		}
 	}
 }
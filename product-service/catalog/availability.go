@@ -0,0 +1,262 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRule  = errors.New("invalid availability rule")
+	ErrSellableItem = errors.New("item type must be product, variant or group")
+)
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// SellableItem identifies a product, variant or group to check.
+type SellableItem struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+}
+
+// Sellability tells whether an item can be sold and, when not, why.
+type Sellability struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+	Sellable bool   `json:"sellable"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// StoreLocation is the time zone availability times are written in, read from
+// STORE_TIMEZONE and defaulting to the server's local zone.
+func StoreLocation() *time.Location {
+	if name := os.Getenv("STORE_TIMEZONE"); name != "" {
+		if location, err := time.LoadLocation(name); err == nil {
+			return location
+		}
+	}
+	return time.Local
+}
+
+// ItemRules returns the availability rules of a product or group.
+func ItemRules(db *gorm.DB, itemType string, itemID uint) ([]models.AvailabilityRule, error) {
+	var rules []models.AvailabilityRule
+	err := db.Where("item_type = ? AND item_id = ?", itemType, itemID).
+		Order("outlet asc, id asc").
+		Find(&rules).Error
+	return rules, err
+}
+
+// ReplaceRules validates and replaces all availability rules of a product or
+// group. Days are normalized to lower-case weekday abbreviations in week
+// order.
+func ReplaceRules(tx *gorm.DB, itemType string, itemID uint, rules []models.AvailabilityRule) error {
+	for i := range rules {
+		if err := normalizeRule(&rules[i]); err != nil {
+			return fmt.Errorf("%w: rule %d: %v", ErrInvalidRule, i+1, err)
+		}
+		rules[i].ID = 0
+		rules[i].ItemType = itemType
+		rules[i].ItemID = itemID
+	}
+
+	if err := tx.Where("item_type = ? AND item_id = ?", itemType, itemID).Delete(&models.AvailabilityRule{}).Error; err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return tx.Create(&rules).Error
+}
+
+// AvailableProducts is a scope keeping products that can be sold at an outlet
+// at a time: their own rules match and, when they belong to a group, the group
+// is active, within its dates and its rules match.
+func AvailableProducts(outlet string, at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			rulesMatchSQL(models.ItemTypeProduct, "products.id")+
+				" AND (products.group_id IS NULL OR "+groupAvailableSQL("products.group_id")+")",
+			availabilityArgs(outlet, at))
+	}
+}
+
+// AvailableGroups is a scope keeping groups that can be sold at an outlet at a
+// time.
+func AvailableGroups(outlet string, at time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(groupAvailableSQL("product_groups.id"), availabilityArgs(outlet, at))
+	}
+}
+
+// CheckSellable tells for each item whether it can be sold at an outlet at a
+// time. Variants are sellable when they and their product are; groups are
+// checked as bundles. Results are returned in the order of items.
+func CheckSellable(db *gorm.DB, items []SellableItem, outlet string, at time.Time) ([]Sellability, error) {
+	results := make([]Sellability, len(items))
+	for i, item := range items {
+		result := Sellability{ItemType: item.ItemType, ItemID: item.ItemID}
+		reason, err := unsellableReason(db, item, outlet, at)
+		if err != nil {
+			return nil, err
+		}
+		result.Sellable = reason == ""
+		result.Reason = reason
+		results[i] = result
+	}
+	return results, nil
+}
+
+func unsellableReason(db *gorm.DB, item SellableItem, outlet string, at time.Time) (string, error) {
+	switch item.ItemType {
+	case models.ItemTypeVariant:
+		var variant models.ProductVariant
+		result := db.Select("id, product_id, status").Where("id = ?", item.ItemID).Limit(1).Find(&variant)
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected == 0 {
+			return "variant not found", nil
+		}
+		if variant.Status != "" && variant.Status != "active" {
+			return "variant is " + variant.Status, nil
+		}
+		return unsellableReason(db, SellableItem{ItemType: models.ItemTypeProduct, ItemID: variant.ProductID}, outlet, at)
+
+	case models.ItemTypeProduct:
+		var product models.Product
+		result := db.Select("id, status").Where("id = ?", item.ItemID).Limit(1).Find(&product)
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected == 0 {
+			return "product not found", nil
+		}
+		if product.Status != "active" {
+			return "product is " + product.Status, nil
+		}
+
+		var available int64
+		if err := db.Model(&models.Product{}).Scopes(AvailableProducts(outlet, at)).
+			Where("products.id = ?", item.ItemID).Count(&available).Error; err != nil {
+			return "", err
+		}
+		if available == 0 {
+			return "product is not available at this time", nil
+		}
+		return "", nil
+
+	case models.ItemTypeGroup:
+		var exists int64
+		if err := db.Model(&models.ProductGroup{}).Where("id = ?", item.ItemID).Count(&exists).Error; err != nil {
+			return "", err
+		}
+		if exists == 0 {
+			return "group not found", nil
+		}
+
+		var available int64
+		if err := db.Model(&models.ProductGroup{}).Scopes(AvailableGroups(outlet, at)).
+			Where("product_groups.id = ?", item.ItemID).Count(&available).Error; err != nil {
+			return "", err
+		}
+		if available == 0 {
+			return "group is not available at this time", nil
+		}
+		return "", nil
+
+	default:
+		return "", ErrSellableItem
+	}
+}
+
+// rulesMatchSQL is true when the item in idColumn has no rules for the outlet
+// or one of them matches the day, time and date. Times compare as HH:MM
+// strings; a range ending before it starts passes midnight.
+func rulesMatchSQL(itemType, idColumn string) string {
+	rules := fmt.Sprintf(`SELECT 1 FROM availability_rules r
+		WHERE r.item_type = '%s' AND r.item_id = %s AND (r.outlet = '' OR r.outlet = @outlet)`, itemType, idColumn)
+	return `(NOT EXISTS (` + rules + `) OR EXISTS (` + rules + `
+		AND (r.days = '' OR r.days LIKE @day)
+		AND (r.start_date IS NULL OR r.start_date <= @date)
+		AND (r.end_date IS NULL OR r.end_date >= @date)
+		AND (r.start_time = ''
+			OR (r.start_time < r.end_time AND @time >= r.start_time AND @time < r.end_time)
+			OR (r.start_time > r.end_time AND (@time >= r.start_time OR @time < r.end_time)))))`
+}
+
+func groupAvailableSQL(idColumn string) string {
+	return `EXISTS (SELECT 1 FROM product_groups g
+		WHERE g.id = ` + idColumn + ` AND g.deleted_at IS NULL AND g.is_active
+		AND (g.start_date IS NULL OR g.start_date <= @now)
+		AND (g.end_date IS NULL OR g.end_date >= @now)
+		AND ` + rulesMatchSQL(models.ItemTypeGroup, "g.id") + `)`
+}
+
+func availabilityArgs(outlet string, at time.Time) map[string]interface{} {
+	local := at.In(StoreLocation())
+	return map[string]interface{}{
+		"outlet": outlet,
+		"day":    "%" + weekdays[local.Weekday()] + "%",
+		"time":   local.Format("15:04"),
+		"date":   local.Format("2006-01-02"),
+		"now":    at,
+	}
+}
+
+func normalizeRule(rule *models.AvailabilityRule) error {
+	rule.Outlet = strings.TrimSpace(rule.Outlet)
+
+	if days := strings.TrimSpace(rule.Days); days != "" {
+		picked := map[string]bool{}
+		for _, day := range strings.Split(days, ",") {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if len(day) > 3 {
+				day = day[:3]
+			}
+			if !contains(weekdays, day) {
+				return fmt.Errorf("unknown day %q", day)
+			}
+			picked[day] = true
+		}
+		ordered := make([]string, 0, len(picked))
+		for _, day := range weekdays {
+			if picked[day] {
+				ordered = append(ordered, day)
+			}
+		}
+		rule.Days = strings.Join(ordered, ",")
+	} else {
+		rule.Days = ""
+	}
+
+	rule.StartTime = strings.TrimSpace(rule.StartTime)
+	rule.EndTime = strings.TrimSpace(rule.EndTime)
+	if (rule.StartTime == "") != (rule.EndTime == "") {
+		return errors.New("startTime and endTime must be set together")
+	}
+	if rule.StartTime != "" {
+		start, err := time.Parse("15:04", rule.StartTime)
+		if err != nil {
+			return errors.New("startTime must be HH:MM")
+		}
+		end, err := time.Parse("15:04", rule.EndTime)
+		if err != nil {
+			return errors.New("endTime must be HH:MM")
+		}
+		if start.Equal(end) {
+			return errors.New("startTime and endTime must differ")
+		}
+		rule.StartTime, rule.EndTime = start.Format("15:04"), end.Format("15:04")
+	}
+
+	if rule.StartDate != nil && rule.EndDate != nil && rule.EndDate.Before(*rule.StartDate) {
+		return errors.New("endDate must not be before startDate")
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBundleNotFound    = errors.New("bundle not found")
+	ErrNotBundle         = errors.New("group is not a bundle")
+	ErrBundleUnavailable = errors.New("bundle is not available")
+	ErrBundleEmpty       = errors.New("bundle has no components")
+	ErrBundleItem        = errors.New("bundle item not found")
+	ErrInvalidSwap       = errors.New("invalid bundle swap")
+)
+
+// BundleLine is the item a bundle component resolves to once swaps are
+// applied. Available is the item's stock less active reservations.
+type BundleLine struct {
+	ComponentID uint    `json:"componentId"`
+	ChoiceID    *uint   `json:"choiceId,omitempty"`
+	ItemType    string  `json:"itemType"`
+	ItemID      uint    `json:"itemId"`
+	Name        string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unitPrice"`
+	Available   int     `json:"available"`
+}
+
+// BundleQuote prices one unit of a bundle. ComponentsPrice is what the chosen
+// items cost when bought separately; Stock is how many bundles the available
+// component stock can make.
+type BundleQuote struct {
+	GroupID         uint         `json:"groupId"`
+	Name            string       `json:"name"`
+	ComponentsPrice float64      `json:"componentsPrice"`
+	Price           float64      `json:"price"`
+	Stock           int          `json:"stock"`
+	Lines           []BundleLine `json:"lines"`
+}
+
+// QuoteBundle resolves a bundle's components, with swaps mapping component IDs
+// to the IDs of the chosen alternatives, and prices the result. The bundle
+// price, fixed or discounted from the default components, is charged plus the
+// price adjustment of each swap.
+func QuoteBundle(db *gorm.DB, groupID uint, swaps map[uint]uint) (*BundleQuote, error) {
+	group, err := loadBundle(db, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !group.IsActive || (group.StartDate != nil && now.Before(*group.StartDate)) ||
+		(group.EndDate != nil && now.After(*group.EndDate)) {
+		return nil, ErrBundleUnavailable
+	}
+
+	for componentID := range swaps {
+		if !hasComponent(group.Components, componentID) {
+			return nil, fmt.Errorf("%w: component %d is not part of %s", ErrInvalidSwap, componentID, group.Name)
+		}
+	}
+
+	quote := &BundleQuote{GroupID: group.ID, Name: group.Name, Lines: []BundleLine{}}
+	var defaultsPrice, adjustments float64
+	needed := map[string]int{}
+	available := map[string]int{}
+	factors := map[string]int{}
+
+	for _, component := range group.Components {
+		defaultItem, err := bundleItem(db, component.ItemType, component.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		defaultsPrice += defaultItem.Price * float64(component.Quantity)
+
+		line := BundleLine{
+			ComponentID: component.ID,
+			ItemType:    component.ItemType,
+			ItemID:      component.ItemID,
+			Name:        defaultItem.Name,
+			Quantity:    component.Quantity,
+			UnitPrice:   defaultItem.Price,
+		}
+
+		item := defaultItem
+		if choiceID, ok := swaps[component.ID]; ok {
+			choice, found := findChoice(component.Choices, choiceID)
+			if !found {
+				return nil, fmt.Errorf("%w: choice %d is not offered for component %d", ErrInvalidSwap, choiceID, component.ID)
+			}
+			item, err = bundleItem(db, choice.ItemType, choice.ItemID)
+			if err != nil {
+				return nil, err
+			}
+			line.ChoiceID = &choice.ID
+			line.ItemType, line.ItemID = choice.ItemType, choice.ItemID
+			line.Name, line.UnitPrice = item.Name, item.Price
+			adjustments += choice.PriceAdjustment
+		}
+
+		key := fmt.Sprintf("%s:%d", line.ItemType, line.ItemID)
+		if _, ok := available[key]; !ok {
+			reserved, err := inventory.Reserved(db, line.ItemType, line.ItemID)
+			if err != nil {
+				return nil, err
+			}
+			available[key] = item.Stock - reserved
+
+			units, err := UnitsOf(db, line.ItemType, line.ItemID)
+			if err != nil {
+				return nil, err
+			}
+			factors[key] = units.SellFactor
+		}
+		line.Available = available[key]
+		// Components are counted in the sell unit, stock in the stock unit
+		needed[key] += line.Quantity * factors[key]
+
+		quote.ComponentsPrice += line.UnitPrice * float64(line.Quantity)
+		quote.Lines = append(quote.Lines, line)
+	}
+
+	// The same item may fill several components, so stock is checked against
+	// the total each bundle needs of it
+	quote.Stock = math.MaxInt32
+	for key, quantity := range needed {
+		if bundles := available[key] / quantity; bundles < quote.Stock {
+			quote.Stock = bundles
+		}
+	}
+	if quote.Stock < 0 {
+		quote.Stock = 0
+	}
+
+	price := defaultsPrice * (1 - group.DiscountPercent/100)
+	if group.BundlePrice != nil {
+		price = *group.BundlePrice
+	}
+	quote.Price = math.Round((price+adjustments)*100) / 100
+	quote.ComponentsPrice = math.Round(quote.ComponentsPrice*100) / 100
+	return quote, nil
+}
+
+// ReplaceComponents replaces all components of a bundle and their choices.
+func ReplaceComponents(tx *gorm.DB, groupID uint, components []models.BundleComponent) error {
+	for i := range components {
+		if _, err := bundleItem(tx, components[i].ItemType, components[i].ItemID); err != nil {
+			return err
+		}
+		for _, choice := range components[i].Choices {
+			if _, err := bundleItem(tx, choice.ItemType, choice.ItemID); err != nil {
+				return err
+			}
+		}
+		components[i].ID = 0
+		components[i].GroupID = groupID
+		components[i].SortOrder = i
+	}
+
+	if err := tx.Where("component_id IN (?)", tx.Model(&models.BundleComponent{}).Select("id").Where("group_id = ?", groupID)).
+		Delete(&models.BundleChoice{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("group_id = ?", groupID).Delete(&models.BundleComponent{}).Error; err != nil {
+		return err
+	}
+	if len(components) == 0 {
+		return nil
+	}
+	return tx.Create(&components).Error
+}
+
+type bundleItemInfo struct {
+	Name  string
+	Price float64
+	Stock int
+}
+
+// bundleItem looks up a product or variant that can go into a bundle. Price is
+// the item's current price, scheduled price included.
+func bundleItem(db *gorm.DB, itemType string, itemID uint) (*bundleItemInfo, error) {
+	var info bundleItemInfo
+	var result *gorm.DB
+	switch itemType {
+	case models.ItemTypeProduct:
+		result = db.Model(&models.Product{}).Select("name, price, stock").Where("id = ?", itemID).Scan(&info)
+	case models.ItemTypeVariant:
+		result = db.Model(&models.ProductVariant{}).Select("name, price, stock").Where("id = ?", itemID).Scan(&info)
+	default:
+		return nil, fmt.Errorf("%w: item type must be product or variant", ErrBundleItem)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s %d", ErrBundleItem, itemType, itemID)
+	}
+
+	price, err := pricing.Effective(db, itemType, itemID, info.Price, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	info.Price = price.Price
+	return &info, nil
+}
+
+func loadBundle(db *gorm.DB, groupID uint) (*models.ProductGroup, error) {
+	var group models.ProductGroup
+	if err := db.Preload("Components", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order asc, id asc")
+	}).Preload("Components.Choices").First(&group, groupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBundleNotFound
+		}
+		return nil, err
+	}
+	if group.GroupType != models.GroupTypeBundle {
+		return nil, ErrNotBundle
+	}
+	if len(group.Components) == 0 {
+		return nil, ErrBundleEmpty
+	}
+	return &group, nil
+}
+
+func hasComponent(components []models.BundleComponent, id uint) bool {
+	for _, component := range components {
+		if component.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func findChoice(choices []models.BundleChoice, id uint) (models.BundleChoice, bool) {
+	for _, choice := range choices {
+		if choice.ID == id {
+			return choice, true
+		}
+	}
+	return models.BundleChoice{}, false
+}
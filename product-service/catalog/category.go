@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("parent category not found")
+	ErrCategoryCycle    = errors.New("a category cannot be moved under itself or one of its subcategories")
+)
+
+// CategoryPath returns the materialized path of a category, the IDs from the
+// root down to the category itself, e.g. /1/4/9/.
+func CategoryPath(parentPath string, id uint) string {
+	if parentPath == "" {
+		parentPath = "/"
+	}
+	return fmt.Sprintf("%s%d/", parentPath, id)
+}
+
+// PathLevel returns the depth of a path; root categories are level 0.
+func PathLevel(path string) int {
+	return strings.Count(path, "/") - 2
+}
+
+// PlaceCategory sets the path and level of a newly created category from its
+// parent.
+func PlaceCategory(tx *gorm.DB, category *models.ProductCategory) error {
+	parentPath, err := parentPath(tx, category.ParentID)
+	if err != nil {
+		return err
+	}
+
+	category.Path = CategoryPath(parentPath, category.ID)
+	category.Level = PathLevel(category.Path)
+	return tx.Model(category).Updates(map[string]interface{}{
+		"path":  category.Path,
+		"level": category.Level,
+	}).Error
+}
+
+// MoveCategory re-parents a category, nil meaning the root, and rewrites the
+// path and level of the whole subtree. Moving a category under one of its own
+// descendants is rejected.
+func MoveCategory(tx *gorm.DB, category *models.ProductCategory, parentID *uint) error {
+	newParentPath, err := parentPath(tx, parentID)
+	if err != nil {
+		return err
+	}
+	if parentID != nil && strings.HasPrefix(newParentPath, category.Path) {
+		return ErrCategoryCycle
+	}
+
+	oldPath := category.Path
+	newPath := CategoryPath(newParentPath, category.ID)
+
+	if err := tx.Model(category).Update("parent_id", parentID).Error; err != nil {
+		return err
+	}
+	if newPath != oldPath {
+		if err := tx.Exec(`
+			UPDATE product_categories
+			SET path = @new_path || substr(path, length(@old_path) + 1),
+				level = level + @delta
+			WHERE path LIKE @subtree`,
+			map[string]interface{}{
+				"new_path": newPath,
+				"old_path": oldPath,
+				"delta":    PathLevel(newPath) - PathLevel(oldPath),
+				"subtree":  oldPath + "%",
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	category.ParentID = parentID
+	category.Path = newPath
+	category.Level = PathLevel(newPath)
+	return nil
+}
+
+// Subtree returns a subquery selecting the IDs of a category and all of its
+// descendants, for filters such as category_id IN (?).
+func Subtree(db *gorm.DB, categoryID uint) *gorm.DB {
+	return db.Model(&models.ProductCategory{}).
+		Select("id").
+		Where("path LIKE (SELECT path FROM product_categories WHERE id = ?) || '%'", categoryID)
+}
+
+// RebuildCategoryPaths recomputes every path and level from the parent links.
+// It runs at startup so categories created before paths existed are filled in.
+func RebuildCategoryPaths(db *gorm.DB) error {
+	return db.Exec(`
+		WITH RECURSIVE tree AS (
+			SELECT id, '/' || id || '/' AS path, 0 AS level
+			FROM product_categories
+			WHERE parent_id IS NULL AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, tree.path || c.id || '/', tree.level + 1
+			FROM product_categories c
+			JOIN tree ON c.parent_id = tree.id
+			WHERE c.deleted_at IS NULL
+		)
+		UPDATE product_categories c
+		SET path = tree.path, level = tree.level
+		FROM tree
+		WHERE c.id = tree.id AND (c.path IS DISTINCT FROM tree.path OR c.level <> tree.level)`).Error
+}
+
+func parentPath(tx *gorm.DB, parentID *uint) (string, error) {
+	if parentID == nil {
+		return "", nil
+	}
+
+	var parent models.ProductCategory
+	if err := tx.Select("id, path").First(&parent, *parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrCategoryNotFound
+		}
+		return "", err
+	}
+	return parent.Path, nil
+}
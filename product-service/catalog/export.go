@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+// Export returns the whole catalogue in the Columns layout: addons first, then
+// each product followed by its variants, so the file can be imported as is.
+func Export(db *gorm.DB) ([][]string, error) {
+	var addons []models.ProductAddon
+	if err := db.Order("id asc").Find(&addons).Error; err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	if err := db.Preload("Category").
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		Preload("Addons", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		Order("id asc").
+		Find(&products).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(addons)+len(products))
+	for _, addon := range addons {
+		rows = append(rows, exportRow(map[string]string{
+			"type":         models.ItemTypeAddon,
+			"sku":          addon.SKU,
+			"name":         addon.Name,
+			"description":  addon.Description,
+			"price":        formatFloat(addon.Price),
+			"cost_price":   formatFloat(addon.CostPrice),
+			"stock":        strconv.Itoa(addon.Stock),
+			"bar_code":     addon.BarCode,
+			"status":       addon.Status,
+			"is_required":  strconv.FormatBool(addon.IsRequired),
+			"max_quantity": strconv.Itoa(addon.MaxQuantity),
+		}))
+	}
+
+	for _, product := range products {
+		addonSKUs := make([]string, len(product.Addons))
+		for i, addon := range product.Addons {
+			addonSKUs[i] = addon.SKU
+		}
+
+		rows = append(rows, exportRow(map[string]string{
+			"type":             models.ItemTypeProduct,
+			"sku":              product.SKU,
+			"name":             product.Name,
+			"category":         product.Category.Slug,
+			"description":      product.Description,
+			"price":            formatFloat(product.Price),
+			"cost_price":       formatFloat(product.CostPrice),
+			"stock":            strconv.Itoa(product.Stock),
+			"min_stock":        formatOptionalInt(product.MinStock),
+			"reorder_quantity": strconv.Itoa(product.ReorderQuantity),
+			"bar_code":         product.BarCode,
+			"status":           product.Status,
+			"tags":             product.Tags,
+			"addon_skus":       strings.Join(addonSKUs, ","),
+		}))
+
+		for _, variant := range product.Variants {
+			rows = append(rows, exportRow(map[string]string{
+				"type":             models.ItemTypeVariant,
+				"sku":              variant.SKU,
+				"product_sku":      product.SKU,
+				"name":             variant.Name,
+				"price":            formatFloat(variant.Price),
+				"cost_price":       formatFloat(variant.CostPrice),
+				"stock":            strconv.Itoa(variant.Stock),
+				"min_stock":        formatOptionalInt(variant.MinStock),
+				"reorder_quantity": strconv.Itoa(variant.ReorderQuantity),
+				"bar_code":         variant.BarCode,
+				"status":           variant.Status,
+				"attributes":       variant.Attributes,
+			}))
+		}
+	}
+
+	return rows, nil
+}
+
+func exportRow(values map[string]string) []string {
+	row := make([]string, len(Columns))
+	for i, column := range Columns {
+		row[i] = values[column]
+	}
+	return row
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
@@ -0,0 +1,180 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+const sheetName = "Catalog"
+
+// Columns is the catalogue file layout. One row describes an addon, a product
+// or a variant; variants name their product in product_sku.
+var Columns = []string{
+	"type",
+	"sku",
+	"product_sku",
+	"name",
+	"category",
+	"description",
+	"price",
+	"cost_price",
+	"stock",
+	"min_stock",
+	"reorder_quantity",
+	"bar_code",
+	"status",
+	"tags",
+	"attributes",
+	"addon_skus",
+	"is_required",
+	"max_quantity",
+}
+
+var (
+	ErrUnknownFormat = errors.New("unsupported file format, expected csv or xlsx")
+	ErrMissingHeader = errors.New("file must start with a header row containing type and sku")
+)
+
+// Record is one data row keyed by column name. Line is the row number in the
+// file, counting the header as line 1.
+type Record struct {
+	Line   int
+	Values map[string]string
+}
+
+// Read parses a CSV or XLSX catalogue. For XLSX the first sheet is read.
+// Columns are matched by header name, so their order does not matter and
+// unknown columns are ignored.
+func Read(r io.Reader, format string) ([]Record, error) {
+	var rows [][]string
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		var err error
+		if rows, err = reader.ReadAll(); err != nil {
+			return nil, err
+		}
+	case FormatXLSX:
+		file, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		if rows, err = file.GetRows(file.GetSheetName(0)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrMissingHeader
+	}
+
+	header := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	}
+	if !contains(header, "type") || !contains(header, "sku") {
+		return nil, ErrMissingHeader
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		values := map[string]string{}
+		blank := true
+		for j, value := range row {
+			if j >= len(header) || header[j] == "" {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			values[header[j]] = value
+			if value != "" {
+				blank = false
+			}
+		}
+		if blank {
+			continue
+		}
+		records = append(records, Record{Line: i + 2, Values: values})
+	}
+	return records, nil
+}
+
+// Write renders rows in the Columns layout.
+func Write(w io.Writer, format string, rows [][]string) error {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(Columns); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case FormatXLSX:
+		file := excelize.NewFile()
+		defer file.Close()
+		if err := file.SetSheetName(file.GetSheetName(0), sheetName); err != nil {
+			return err
+		}
+
+		writer, err := file.NewStreamWriter(sheetName)
+		if err != nil {
+			return err
+		}
+		if err := writer.SetRow("A1", cells(Columns)); err != nil {
+			return err
+		}
+		for i, row := range rows {
+			if err := writer.SetRow(fmt.Sprintf("A%d", i+2), cells(row)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+
+		return file.Write(w)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// ContentType returns the MIME type of a catalogue file format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+func cells(row []string) []interface{} {
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return values
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
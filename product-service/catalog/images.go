@@ -0,0 +1,189 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+
+	"github.com/ridhotamma/yourkasa/product-service/storage"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// MaxImageBytes caps the size of an uploaded image file.
+	MaxImageBytes = 5 << 20
+	// MaxImagePixels caps the decoded size so small files cannot expand into
+	// huge bitmaps.
+	MaxImagePixels = 40_000_000
+
+	maxImageSide  = 1600 // Larger images are scaled down before storing
+	thumbnailSide = 320
+)
+
+var (
+	ErrImageTooLarge   = fmt.Errorf("image must be at most %d MB", MaxImageBytes>>20)
+	ErrImageType       = errors.New("image must be a JPEG, PNG, GIF or WebP file")
+	ErrImageDimensions = errors.New("image dimensions are too large")
+)
+
+// StoredImage is an uploaded image kept in storage with its thumbnail.
+type StoredImage struct {
+	URL          string
+	ThumbnailURL string
+	ContentType  string
+	Width        int
+	Height       int
+	Size         int
+}
+
+// StoreImage checks an uploaded image by its content rather than the name or
+// type the client sent, scales it down when it is larger than 1600px on its
+// longest side and stores it with a 320px thumbnail under prefix, e.g.
+// products/12.
+func StoreImage(ctx context.Context, store storage.Storage, prefix string, data []byte) (*StoredImage, error) {
+	if len(data) > MaxImageBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, ErrImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, ErrImageDimensions
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrImageType
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return nil, err
+	}
+
+	// Images within the size limit are stored untouched, which keeps GIF
+	// animation; others are re-encoded at the smaller size. WebP has no
+	// encoder, so scaled WebP images are stored as JPEG.
+	stored := &StoredImage{ContentType: contentType, Width: config.Width, Height: config.Height, Size: len(data)}
+	original := data
+	if config.Width > maxImageSide || config.Height > maxImageSide {
+		scaled := resize(img, maxImageSide)
+		if original, stored.ContentType, err = encode(scaled, contentType); err != nil {
+			return nil, err
+		}
+		bounds := scaled.Bounds()
+		stored.Width, stored.Height, stored.Size = bounds.Dx(), bounds.Dy(), len(original)
+	}
+
+	thumbnail, thumbnailType, err := encode(resize(img, thumbnailSide), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	key := prefix + "/" + name + extension(stored.ContentType)
+	if stored.URL, err = store.Put(ctx, key, original, stored.ContentType); err != nil {
+		return nil, err
+	}
+	thumbnailKey := prefix + "/" + name + "-thumb" + extension(thumbnailType)
+	if stored.ThumbnailURL, err = store.Put(ctx, thumbnailKey, thumbnail, thumbnailType); err != nil {
+		DeleteImage(ctx, store, stored.URL)
+		return nil, err
+	}
+	return stored, nil
+}
+
+// DeleteImage removes stored images by their URLs. URLs that do not point to
+// storage are skipped, and failures are logged rather than returned since the
+// image is no longer referenced.
+func DeleteImage(ctx context.Context, store storage.Storage, urls ...string) {
+	for _, url := range urls {
+		key, ok := store.Key(url)
+		if !ok {
+			continue
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete image %s: %v", key, err)
+		}
+	}
+}
+
+// resize scales img down to fit within side pixels, keeping its aspect
+// ratio. Images already small enough are returned as they are.
+func resize(img image.Image, side int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= side && height <= side {
+		return img
+	}
+
+	if width >= height {
+		width, height = side, max(1, height*side/width)
+	} else {
+		width, height = max(1, width*side/height), side
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return scaled
+}
+
+// encode writes img as PNG when the source may be transparent and as JPEG
+// otherwise.
+func encode(img image.Image, sourceType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch sourceType {
+	case "image/png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	case "image/gif":
+		if err := gif.Encode(&buf, img, nil); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/gif", nil
+	default:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+}
+
+func extension(contentType string) string {
+	switch contentType {
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	default:
+		return ".jpg"
+	}
+}
+
+func randomName() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
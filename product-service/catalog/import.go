@@ -0,0 +1,699 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"github.com/ridhotamma/yourkasa/product-service/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+)
+
+// RowResult reports what an import does, or would do, with one row.
+type RowResult struct {
+	Line   int      `json:"line"`
+	Type   string   `json:"type"`
+	SKU    string   `json:"sku"`
+	Action string   `json:"action,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Result summarises an import. Nothing is written unless every row is valid.
+type Result struct {
+	DryRun        bool        `json:"dryRun"`
+	Applied       bool        `json:"applied"`
+	Created       int         `json:"created"`
+	Updated       int         `json:"updated"`
+	ErrorCount    int         `json:"errorCount"`
+	NewCategories []string    `json:"newCategories"`
+	Rows          []RowResult `json:"rows"`
+}
+
+// row is a parsed record. Empty cells are nil or empty and leave existing
+// values unchanged.
+type row struct {
+	line            int
+	itemType        string
+	sku             string
+	productSKU      string
+	name            string
+	category        string
+	description     string
+	price           *float64
+	costPrice       *float64
+	stock           *int
+	minStock        *int
+	reorderQuantity *int
+	barCode         string
+	status          string
+	tags            string
+	attributes      string
+	attributeKey    string
+	addonSKUs       []string
+	isRequired      *bool
+	maxQuantity     *int
+
+	existingID uint
+	categoryID uint
+}
+
+type existingItem struct {
+	itemType  string
+	id        uint
+	productID uint
+	deleted   bool
+}
+
+var validStatuses = map[string]bool{"active": true, "inactive": true, "discontinued": true}
+
+// Import validates every record and, unless dryRun is set or a row is
+// invalid, upserts addons, products and variants by SKU. Existing items only
+// change in the columns that are filled in. Stock is brought to the imported
+// count through the ledger; cost_price only applies to new items, since the
+// cost of existing stock is kept by the costing method. Call it inside a
+// transaction and roll back unless the result was applied.
+func Import(tx *gorm.DB, records []Record, dryRun bool, userID *uint) (*Result, error) {
+	result := &Result{DryRun: dryRun, NewCategories: []string{}, Rows: make([]RowResult, len(records))}
+
+	rows := make([]*row, len(records))
+	for i, record := range records {
+		rows[i] = parseRow(record, &result.Rows[i])
+	}
+
+	existing, err := findExisting(tx, rows)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := loadCategories(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	validate(rows, result, existing, categories)
+	if err := checkAttributes(tx, rows, result, existing); err != nil {
+		return nil, err
+	}
+
+	for _, r := range result.Rows {
+		if len(r.Errors) > 0 {
+			result.ErrorCount++
+		} else if r.Action == ActionCreate {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+	if dryRun || result.ErrorCount > 0 {
+		return result, nil
+	}
+
+	if err := apply(tx, rows, existing, categories, userID); err != nil {
+		return nil, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+func parseRow(record Record, result *RowResult) *row {
+	v := record.Values
+	r := &row{
+		line:        record.Line,
+		itemType:    strings.ToLower(v["type"]),
+		sku:         v["sku"],
+		productSKU:  v["product_sku"],
+		name:        v["name"],
+		category:    v["category"],
+		description: v["description"],
+		barCode:     v["bar_code"],
+		status:      strings.ToLower(v["status"]),
+		tags:        v["tags"],
+		attributes:  v["attributes"],
+	}
+	result.Line, result.Type, result.SKU = r.line, r.itemType, r.sku
+
+	fail := func(format string, args ...interface{}) {
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+
+	switch r.itemType {
+	case models.ItemTypeProduct, models.ItemTypeVariant, models.ItemTypeAddon:
+	default:
+		fail("type must be product, variant or addon")
+	}
+	if r.sku == "" {
+		fail("sku is required")
+	}
+
+	r.price = parseFloat(v["price"], "price", fail)
+	r.costPrice = parseFloat(v["cost_price"], "cost_price", fail)
+	r.stock = parseInt(v["stock"], "stock", 0, fail)
+	r.minStock = parseInt(v["min_stock"], "min_stock", 0, fail)
+	r.reorderQuantity = parseInt(v["reorder_quantity"], "reorder_quantity", 0, fail)
+	r.maxQuantity = parseInt(v["max_quantity"], "max_quantity", 1, fail)
+
+	if value := v["is_required"]; value != "" {
+		switch strings.ToLower(value) {
+		case "true", "yes", "y", "1":
+			r.isRequired = boolPtr(true)
+		case "false", "no", "n", "0":
+			r.isRequired = boolPtr(false)
+		default:
+			fail("is_required must be true or false")
+		}
+	}
+
+	if value := v["addon_skus"]; value != "" {
+		for _, sku := range strings.Split(value, ",") {
+			if sku = strings.TrimSpace(sku); sku != "" {
+				r.addonSKUs = append(r.addonSKUs, sku)
+			}
+		}
+	}
+
+	if r.status != "" && !validStatuses[r.status] {
+		fail("status must be active, inactive or discontinued")
+	}
+	if _, err := utils.ParseScannedCode(r.barCode); err != nil {
+		fail("bar_code has an invalid check digit")
+	}
+	if key, err := AttributeKey(r.attributes); err != nil {
+		fail("attributes must be a JSON object")
+	} else {
+		r.attributeKey = key
+	}
+
+	return r
+}
+
+// validate checks rows against each other and the database, and decides
+// whether each row creates or updates an item.
+func validate(rows []*row, result *Result, existing map[string]existingItem, categories *categoryIndex) {
+	inFile := map[string]*row{}
+	for i, r := range rows {
+		res := &result.Rows[i]
+		fail := func(format string, args ...interface{}) {
+			res.Errors = append(res.Errors, fmt.Sprintf(format, args...))
+		}
+		if r.sku == "" {
+			continue
+		}
+
+		if first, ok := inFile[r.sku]; ok {
+			fail("sku %s already appears on line %d", r.sku, first.line)
+			continue
+		}
+		inFile[r.sku] = r
+
+		res.Action = ActionCreate
+		if item, ok := existing[r.sku]; ok {
+			switch {
+			case item.deleted:
+				fail("sku %s belongs to a deleted %s", r.sku, item.itemType)
+			case item.itemType != r.itemType:
+				fail("sku %s belongs to an existing %s", r.sku, item.itemType)
+			default:
+				res.Action = ActionUpdate
+				r.existingID = item.id
+			}
+		}
+
+		if res.Action == ActionCreate {
+			if r.name == "" {
+				fail("name is required for a new %s", r.itemType)
+			}
+			if r.price == nil {
+				fail("price is required for a new %s", r.itemType)
+			}
+		}
+
+		switch r.itemType {
+		case models.ItemTypeProduct:
+			if r.category == "" && res.Action == ActionCreate {
+				fail("category is required for a new product")
+			}
+			if r.category != "" {
+				categories.plan(r.category, &result.NewCategories)
+			}
+		case models.ItemTypeVariant:
+			if r.productSKU == "" {
+				if res.Action == ActionCreate {
+					fail("product_sku is required for a new variant")
+				}
+				break
+			}
+			parent, inThisFile := findRow(rows, r.productSKU)
+			item, inDB := existing[r.productSKU]
+			switch {
+			case inThisFile && parent.itemType != models.ItemTypeProduct:
+				fail("product_sku %s is not a product", r.productSKU)
+			case !inThisFile && (!inDB || item.deleted || item.itemType != models.ItemTypeProduct):
+				fail("product %s not found", r.productSKU)
+			}
+			if current, ok := existing[r.sku]; ok && res.Action == ActionUpdate {
+				if product, ok := existing[r.productSKU]; !ok || product.id != current.productID {
+					fail("variant %s belongs to another product", r.sku)
+				}
+			}
+		}
+
+		if len(r.addonSKUs) > 0 && r.itemType != models.ItemTypeProduct {
+			fail("addon_skus only applies to products")
+		}
+		for _, sku := range r.addonSKUs {
+			addon, inThisFile := findRow(rows, sku)
+			item, inDB := existing[sku]
+			switch {
+			case inThisFile && addon.itemType != models.ItemTypeAddon:
+				fail("addon %s is not an addon", sku)
+			case !inThisFile && (!inDB || item.deleted || item.itemType != models.ItemTypeAddon):
+				fail("addon %s not found", sku)
+			}
+		}
+	}
+}
+
+// checkAttributes rejects variant rows whose attribute combination is already
+// used by another variant of the same product, in the file or the database.
+func checkAttributes(tx *gorm.DB, rows []*row, result *Result, existing map[string]existingItem) error {
+	inFile := map[string]*row{}
+	for i, r := range rows {
+		if r.itemType != models.ItemTypeVariant || r.attributeKey == "" || len(result.Rows[i].Errors) > 0 {
+			continue
+		}
+		res := &result.Rows[i]
+
+		if r.productSKU != "" {
+			key := r.productSKU + "|" + r.attributeKey
+			if first, ok := inFile[key]; ok {
+				res.Errors = append(res.Errors, fmt.Sprintf("attributes repeat those of line %d", first.line))
+				continue
+			}
+			inFile[key] = r
+		}
+
+		productID := existing[r.sku].productID
+		if product, ok := existing[r.productSKU]; ok && r.productSKU != "" {
+			productID = product.id
+		}
+		if productID == 0 {
+			continue
+		}
+		if _, err := CheckVariantAttributes(tx, productID, r.existingID, r.attributes); err != nil {
+			if !errors.Is(err, ErrDuplicateVariant) {
+				return err
+			}
+			res.Errors = append(res.Errors, "another variant of this product has the same attributes")
+		}
+	}
+	return nil
+}
+
+// apply writes valid rows: new categories first, then addons, products and
+// variants, so every row can refer to items created by earlier ones or
+// already in the catalog.
+func apply(tx *gorm.DB, rows []*row, existing map[string]existingItem, categories *categoryIndex, userID *uint) error {
+	if err := categories.create(tx); err != nil {
+		return err
+	}
+
+	// Variant rows may belong to products that are not in the file
+	ids := map[string]uint{}
+	for sku, item := range existing {
+		if item.itemType == models.ItemTypeProduct && !item.deleted {
+			ids[sku] = item.id
+		}
+	}
+	for _, itemType := range []string{models.ItemTypeAddon, models.ItemTypeProduct, models.ItemTypeVariant} {
+		for _, r := range rows {
+			if r.itemType != itemType {
+				continue
+			}
+			if r.category != "" {
+				r.categoryID = categories.id(r.category)
+			}
+
+			var err error
+			if r.existingID != 0 {
+				ids[r.sku] = r.existingID
+				err = updateItem(tx, r, ids, userID)
+			} else {
+				ids[r.sku], err = createItem(tx, r, ids, userID)
+			}
+			if err != nil {
+				return fmt.Errorf("line %d: %w", r.line, err)
+			}
+		}
+	}
+
+	for _, r := range rows {
+		if r.itemType != models.ItemTypeProduct || len(r.addonSKUs) == 0 {
+			continue
+		}
+		if err := linkAddons(tx, ids[r.sku], r.addonSKUs); err != nil {
+			return fmt.Errorf("line %d: %w", r.line, err)
+		}
+	}
+	return nil
+}
+
+func createItem(tx *gorm.DB, r *row, ids map[string]uint, userID *uint) (uint, error) {
+	var id uint
+	switch r.itemType {
+	case models.ItemTypeAddon:
+		addon := models.ProductAddon{
+			Name:        r.name,
+			Description: r.description,
+			Price:       *r.price,
+			SKU:         r.sku,
+			BarCode:     r.barCode,
+			Status:      r.status,
+			MaxQuantity: 1,
+		}
+		if r.isRequired != nil {
+			addon.IsRequired = *r.isRequired
+		}
+		if r.maxQuantity != nil {
+			addon.MaxQuantity = *r.maxQuantity
+		}
+		if err := tx.Create(&addon).Error; err != nil {
+			return 0, err
+		}
+		id = addon.ID
+	case models.ItemTypeProduct:
+		product := models.Product{
+			Name:        r.name,
+			Description: r.description,
+			Price:       *r.price,
+			MinStock:    r.minStock,
+			CategoryID:  r.categoryID,
+			SKU:         r.sku,
+			BarCode:     r.barCode,
+			Tags:        r.tags,
+			Status:      r.status,
+		}
+		if r.reorderQuantity != nil {
+			product.ReorderQuantity = *r.reorderQuantity
+		}
+		if err := tx.Create(&product).Error; err != nil {
+			return 0, err
+		}
+		id = product.ID
+		if err := pricing.RecordChange(tx, models.ItemTypeProduct, id, nil, product.Price, models.PriceSourceImport, userID); err != nil {
+			return 0, err
+		}
+	case models.ItemTypeVariant:
+		variant := models.ProductVariant{
+			ProductID:    ids[r.productSKU],
+			Name:         r.name,
+			SKU:          r.sku,
+			Price:        *r.price,
+			MinStock:     r.minStock,
+			Attributes:   r.attributes,
+			AttributeKey: r.attributeKey,
+			Status:       r.status,
+			BarCode:      r.barCode,
+		}
+		if variant.Attributes == "" {
+			variant.Attributes = "{}"
+		}
+		if r.reorderQuantity != nil {
+			variant.ReorderQuantity = *r.reorderQuantity
+		}
+		if err := tx.Create(&variant).Error; err != nil {
+			return 0, err
+		}
+		id = variant.ID
+		if err := pricing.RecordChange(tx, models.ItemTypeVariant, id, nil, variant.Price, models.PriceSourceImport, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.stock != nil && *r.stock > 0 {
+		costPrice := 0.0
+		if r.costPrice != nil {
+			costPrice = *r.costPrice
+		}
+		if _, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     r.itemType,
+			ItemID:       id,
+			MovementType: models.MovementAdjustment,
+			Quantity:     *r.stock,
+			Reason:       "Opening stock",
+			Reference:    "Catalogue import",
+			UnitCost:     &costPrice,
+			UserID:       userID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+func updateItem(tx *gorm.DB, r *row, ids map[string]uint, userID *uint) error {
+	updates := map[string]interface{}{}
+	if r.name != "" {
+		updates["name"] = r.name
+	}
+	if r.price != nil {
+		updates["price"] = *r.price
+	}
+	if r.barCode != "" {
+		updates["bar_code"] = r.barCode
+	}
+	if r.status != "" {
+		updates["status"] = r.status
+	}
+
+	var model interface{}
+	switch r.itemType {
+	case models.ItemTypeAddon:
+		model = &models.ProductAddon{}
+		if r.description != "" {
+			updates["description"] = r.description
+		}
+		if r.isRequired != nil {
+			updates["is_required"] = *r.isRequired
+		}
+		if r.maxQuantity != nil {
+			updates["max_quantity"] = *r.maxQuantity
+		}
+	case models.ItemTypeProduct:
+		model = &models.Product{}
+		if r.description != "" {
+			updates["description"] = r.description
+		}
+		if r.categoryID != 0 {
+			updates["category_id"] = r.categoryID
+		}
+		if r.minStock != nil {
+			updates["min_stock"] = *r.minStock
+		}
+		if r.reorderQuantity != nil {
+			updates["reorder_quantity"] = *r.reorderQuantity
+		}
+		if r.tags != "" {
+			updates["tags"] = r.tags
+		}
+	case models.ItemTypeVariant:
+		model = &models.ProductVariant{}
+		if r.minStock != nil {
+			updates["min_stock"] = *r.minStock
+		}
+		if r.reorderQuantity != nil {
+			updates["reorder_quantity"] = *r.reorderQuantity
+		}
+		if r.attributes != "" {
+			updates["attributes"] = r.attributes
+			updates["attribute_key"] = r.attributeKey
+		}
+	}
+
+	// Addon prices are not scheduled, so only products and variants keep a
+	// price history
+	var oldPrice *float64
+	if r.price != nil && r.itemType != models.ItemTypeAddon {
+		var current float64
+		if err := tx.Model(model).Where("id = ?", r.existingID).Pluck("price", &current).Error; err != nil {
+			return err
+		}
+		oldPrice = &current
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(model).Where("id = ?", r.existingID).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+
+	if oldPrice != nil {
+		if err := pricing.RecordChange(tx, r.itemType, r.existingID, oldPrice, *r.price, models.PriceSourceImport, userID); err != nil {
+			return err
+		}
+	}
+
+	if r.stock != nil {
+		if _, err := inventory.SetCount(tx, r.itemType, r.existingID, *r.stock, "Catalogue import", "", userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkAddons replaces the addons offered with a product.
+func linkAddons(tx *gorm.DB, productID uint, skus []string) error {
+	var addons []models.ProductAddon
+	if err := tx.Where("sku IN ?", skus).Find(&addons).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Product{Model: gorm.Model{ID: productID}}).Association("Addons").Replace(&addons)
+}
+
+// findExisting looks up every SKU in the file, including soft-deleted items
+// whose SKU is still taken.
+func findExisting(tx *gorm.DB, rows []*row) (map[string]existingItem, error) {
+	skus := []string{}
+	for _, r := range rows {
+		if r.sku != "" {
+			skus = append(skus, r.sku)
+		}
+		if r.productSKU != "" {
+			skus = append(skus, r.productSKU)
+		}
+		skus = append(skus, r.addonSKUs...)
+	}
+
+	existing := map[string]existingItem{}
+	if len(skus) == 0 {
+		return existing, nil
+	}
+
+	var found []struct {
+		ItemType  string
+		ID        uint
+		ProductID uint
+		SKU       string
+		Deleted   bool
+	}
+	if err := tx.Raw(`
+		SELECT 'product' AS item_type, id, 0 AS product_id, sku, deleted_at IS NOT NULL AS deleted FROM products WHERE sku IN @skus
+		UNION ALL
+		SELECT 'variant', id, product_id, sku, deleted_at IS NOT NULL FROM product_variants WHERE sku IN @skus
+		UNION ALL
+		SELECT 'addon', id, 0, sku, deleted_at IS NOT NULL FROM product_addons WHERE sku IN @skus`,
+		map[string]interface{}{"skus": skus}).Scan(&found).Error; err != nil {
+		return nil, err
+	}
+
+	for _, item := range found {
+		existing[item.SKU] = existingItem{itemType: item.ItemType, id: item.ID, productID: item.ProductID, deleted: item.Deleted}
+	}
+	return existing, nil
+}
+
+// findRow finds the row for a SKU anywhere in the file.
+func findRow(rows []*row, sku string) (*row, bool) {
+	for _, r := range rows {
+		if r.sku == sku {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// categoryIndex resolves category cells by slug or name and collects the ones
+// that have to be created.
+type categoryIndex struct {
+	ids     map[string]uint
+	pending map[string]string // slug to name
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+func loadCategories(tx *gorm.DB) (*categoryIndex, error) {
+	var categories []models.ProductCategory
+	if err := tx.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	index := &categoryIndex{ids: map[string]uint{}, pending: map[string]string{}}
+	for _, category := range categories {
+		index.ids[strings.ToLower(category.Name)] = category.ID
+		index.ids[category.Slug] = category.ID
+	}
+	return index, nil
+}
+
+func (c *categoryIndex) plan(value string, created *[]string) {
+	if c.id(value) != 0 {
+		return
+	}
+	slug := slugify(value)
+	if _, ok := c.pending[slug]; !ok {
+		c.pending[slug] = value
+		*created = append(*created, value)
+	}
+}
+
+func (c *categoryIndex) id(value string) uint {
+	if id, ok := c.ids[strings.ToLower(value)]; ok {
+		return id
+	}
+	return c.ids[slugify(value)]
+}
+
+func (c *categoryIndex) create(tx *gorm.DB) error {
+	for slug, name := range c.pending {
+		category := models.ProductCategory{Name: name, Slug: slug, IsActive: true}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		if err := PlaceCategory(tx, &category); err != nil {
+			return err
+		}
+		c.ids[slug] = category.ID
+		c.ids[strings.ToLower(name)] = category.ID
+	}
+	return nil
+}
+
+func parseFloat(value, column string, fail func(string, ...interface{})) *float64 {
+	if value == "" {
+		return nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		fail("%s must be a number of at least 0", column)
+		return nil
+	}
+	return &number
+}
+
+func parseInt(value, column string, min int, fail func(string, ...interface{})) *int {
+	if value == "" {
+		return nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < min {
+		fail("%s must be a whole number of at least %d", column, min)
+		return nil
+	}
+	return &number
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
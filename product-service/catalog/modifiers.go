@@ -0,0 +1,189 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+// ModifierGroupRules is a modifier group as offered on one product, with the
+// product's overrides applied.
+type ModifierGroupRules struct {
+	ID            uint                  `json:"id"`
+	Name          string                `json:"name"`
+	Description   string                `json:"description"`
+	MinSelections int                   `json:"minSelections"`
+	MaxSelections int                   `json:"maxSelections"` // 0 means no limit
+	SortOrder     int                   `json:"sortOrder"`
+	Addons        []models.ProductAddon `json:"addons"`
+}
+
+// Selection is an addon picked for one unit of a product.
+type Selection struct {
+	AddonID  uint `json:"addonId"`
+	Quantity int  `json:"quantity"`
+}
+
+type ModifierError struct {
+	ModifierGroupID *uint  `json:"modifierGroupId,omitempty"`
+	AddonID         *uint  `json:"addonId,omitempty"`
+	Message         string `json:"message"`
+}
+
+// ModifierValidation is the outcome of checking selections against a
+// product's rules. AddonsPrice is the price of the selected addons for one
+// unit of the product.
+type ModifierValidation struct {
+	Valid       bool            `json:"valid"`
+	Errors      []ModifierError `json:"errors"`
+	AddonsPrice float64         `json:"addonsPrice"`
+}
+
+// ProductModifierGroups returns the active modifier groups offered on a
+// product in display order, each with its active addons.
+func ProductModifierGroups(db *gorm.DB, productID uint) ([]ModifierGroupRules, error) {
+	var links []models.ProductModifierGroup
+	if err := db.Preload("ModifierGroup", "is_active = ?", true).
+		Preload("ModifierGroup.Addons", "status = ?", "active").
+		Where("product_id = ?", productID).
+		Order("sort_order asc, modifier_group_id asc").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	groups := make([]ModifierGroupRules, 0, len(links))
+	for _, link := range links {
+		// Inactive or deleted groups are not loaded
+		if link.ModifierGroup.ID == 0 {
+			continue
+		}
+
+		group := ModifierGroupRules{
+			ID:            link.ModifierGroup.ID,
+			Name:          link.ModifierGroup.Name,
+			Description:   link.ModifierGroup.Description,
+			MinSelections: link.ModifierGroup.MinSelections,
+			MaxSelections: link.ModifierGroup.MaxSelections,
+			SortOrder:     link.SortOrder,
+			Addons:        link.ModifierGroup.Addons,
+		}
+		if link.MinSelections != nil {
+			group.MinSelections = *link.MinSelections
+		}
+		if link.MaxSelections != nil {
+			group.MaxSelections = *link.MaxSelections
+		}
+		if group.Addons == nil {
+			group.Addons = []models.ProductAddon{}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ValidateModifiers checks the addons picked for one unit of a product. Each
+// addon counts towards the first of the product's groups that contains it.
+// Addons linked to the product directly, outside any group, follow their own
+// IsRequired and MaxQuantity settings.
+func ValidateModifiers(db *gorm.DB, productID uint, selections []Selection) (*ModifierValidation, error) {
+	var product models.Product
+	if err := db.Preload("Addons", "status = ?", "active").First(&product, productID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+
+	groups, err := ProductModifierGroups(db, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := &ModifierValidation{Errors: []ModifierError{}}
+	fail := func(groupID, addonID *uint, format string, args ...interface{}) {
+		validation.Errors = append(validation.Errors, ModifierError{
+			ModifierGroupID: groupID,
+			AddonID:         addonID,
+			Message:         fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Merge repeated addons and keep a stable order for error messages
+	quantities := map[uint]int{}
+	var addonIDs []uint
+	for _, selection := range selections {
+		if _, ok := quantities[selection.AddonID]; !ok {
+			addonIDs = append(addonIDs, selection.AddonID)
+		}
+		quantities[selection.AddonID] += selection.Quantity
+	}
+	sort.Slice(addonIDs, func(i, j int) bool { return addonIDs[i] < addonIDs[j] })
+
+	groupOf := map[uint]int{}
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, addon := range groups[i].Addons {
+			groupOf[addon.ID] = i
+		}
+	}
+	direct := map[uint]models.ProductAddon{}
+	for _, addon := range product.Addons {
+		direct[addon.ID] = addon
+	}
+
+	selected := make([]int, len(groups))
+	for _, addonID := range addonIDs {
+		addonID := addonID
+		quantity := quantities[addonID]
+
+		var addon models.ProductAddon
+		if i, ok := groupOf[addonID]; ok {
+			for _, a := range groups[i].Addons {
+				if a.ID == addonID {
+					addon = a
+				}
+			}
+			selected[i] += quantity
+		} else if a, ok := direct[addonID]; ok {
+			addon = a
+		} else {
+			fail(nil, &addonID, "Addon %d is not offered with %s", addonID, product.Name)
+			continue
+		}
+
+		if quantity <= 0 {
+			fail(nil, &addonID, "Quantity of %s must be at least 1", addon.Name)
+			continue
+		}
+		if addon.MaxQuantity > 0 && quantity > addon.MaxQuantity {
+			fail(nil, &addonID, "%s can be added at most %d times", addon.Name, addon.MaxQuantity)
+		}
+		validation.AddonsPrice += addon.Price * float64(quantity)
+	}
+
+	for i, group := range groups {
+		groupID := group.ID
+		switch {
+		case selected[i] < group.MinSelections:
+			fail(&groupID, nil, "Choose at least %d from %s", group.MinSelections, group.Name)
+		case group.MaxSelections > 0 && selected[i] > group.MaxSelections:
+			fail(&groupID, nil, "Choose at most %d from %s", group.MaxSelections, group.Name)
+		}
+	}
+
+	for _, addon := range product.Addons {
+		addonID := addon.ID
+		if _, inGroup := groupOf[addonID]; addon.IsRequired && !inGroup && quantities[addonID] == 0 {
+			fail(nil, &addonID, "%s is required", addon.Name)
+		}
+	}
+
+	validation.Valid = len(validation.Errors) == 0
+	validation.AddonsPrice = math.Round(validation.AddonsPrice*100) / 100
+	return validation, nil
+}
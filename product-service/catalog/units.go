@@ -0,0 +1,207 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnitNotFound       = errors.New("unit of measure not found")
+	ErrUnitMismatch       = errors.New("stock, sell and purchase units must measure the same dimension")
+	ErrUnitFactor         = errors.New("sell and purchase units must be whole multiples of the stock unit")
+	ErrFractionalQuantity = errors.New("quantity must be a whole number")
+	ErrWeighedUnit        = errors.New("weighed items need a sell unit larger than the stock unit, e.g. sold per kg and stocked in g")
+	ErrUnitItemNotFound   = errors.New("item not found")
+)
+
+// ItemUnits are the units a product or variant is stocked, sold and bought
+// in. SellFactor and PurchaseFactor are the number of stock units in one sell
+// or purchase unit. Units are nil for items counted in pieces.
+type ItemUnits struct {
+	StockUnit      *models.UnitOfMeasure `json:"stockUnit"`
+	SellUnit       *models.UnitOfMeasure `json:"sellUnit"`
+	PurchaseUnit   *models.UnitOfMeasure `json:"purchaseUnit"`
+	SellFactor     int                   `json:"sellFactor"`
+	PurchaseFactor int                   `json:"purchaseFactor"`
+	Weighed        bool                  `json:"weighed"`
+}
+
+// SellCode is the code of the unit prices are per, "pcs" for items counted in
+// pieces.
+func (u *ItemUnits) SellCode() string {
+	if u.SellUnit != nil {
+		return u.SellUnit.Code
+	}
+	return "pcs"
+}
+
+// PurchaseCode is the code of the unit purchase orders are placed in.
+func (u *ItemUnits) PurchaseCode() string {
+	if u.PurchaseUnit != nil {
+		return u.PurchaseUnit.Code
+	}
+	return "pcs"
+}
+
+// ToStock converts a quantity in the sell unit to whole stock units. Only
+// weighed items may be sold in fractions of their sell unit.
+func (u *ItemUnits) ToStock(quantity float64) (int, error) {
+	if !u.Weighed && quantity != math.Trunc(quantity) {
+		return 0, fmt.Errorf("%w: item is not sold by weight", ErrFractionalQuantity)
+	}
+
+	stock := quantity * float64(u.SellFactor)
+	rounded := math.Round(stock)
+	if math.Abs(stock-rounded) > 1e-6 {
+		return 0, fmt.Errorf("%w: %g %s is not a whole number of stock units", ErrFractionalQuantity, quantity, u.SellCode())
+	}
+	return int(rounded), nil
+}
+
+// CheckUnits validates the units chosen for a product: they must exist,
+// measure the same dimension and, since stock is counted in whole stock
+// units, the sell and purchase units must be whole multiples of the stock
+// unit, e.g. stock in g and sell in kg. A nil stock unit means pieces.
+// Weighed items must be sold in a unit of several stock units, or fractions
+// of it could not be counted in whole stock units.
+func CheckUnits(db *gorm.DB, stockUnitID, sellUnitID, purchaseUnitID *uint, weighed bool) error {
+	units, err := loadUnits(db, stockUnitID, sellUnitID, purchaseUnitID)
+	if err != nil {
+		return err
+	}
+	factors, err := unitFactors(units[0], units[1], units[2])
+	if err != nil {
+		return err
+	}
+	if weighed && factors.SellFactor <= 1 {
+		return ErrWeighedUnit
+	}
+	return nil
+}
+
+// ProductUnits returns the units of a product, which its variants share.
+func ProductUnits(db *gorm.DB, product *models.Product) (*ItemUnits, error) {
+	units, err := loadUnits(db, product.StockUnitID, product.SellUnitID, product.PurchaseUnitID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := unitFactors(units[0], units[1], units[2])
+	if err != nil {
+		return nil, err
+	}
+	result.Weighed = product.Weighed
+	return result, nil
+}
+
+// UnitsOf returns the units of a product, variant or addon. Addons are always
+// counted in pieces.
+func UnitsOf(db *gorm.DB, itemType string, itemID uint) (*ItemUnits, error) {
+	var product models.Product
+	var result *gorm.DB
+	switch itemType {
+	case models.ItemTypeProduct:
+		result = db.Select("id, stock_unit_id, sell_unit_id, purchase_unit_id, weighed").
+			Where("id = ?", itemID).Limit(1).Find(&product)
+	case models.ItemTypeVariant:
+		result = db.Select("id, stock_unit_id, sell_unit_id, purchase_unit_id, weighed").
+			Where("id = (SELECT product_id FROM product_variants WHERE id = ?)", itemID).Limit(1).Find(&product)
+	case models.ItemTypeAddon:
+		return &ItemUnits{SellFactor: 1, PurchaseFactor: 1}, nil
+	default:
+		return nil, fmt.Errorf("unknown item type %q", itemType)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: %s %d", ErrUnitItemNotFound, itemType, itemID)
+	}
+	return ProductUnits(db, &product)
+}
+
+// SeedUnits creates the common units the first time the service starts.
+func SeedUnits(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.UnitOfMeasure{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Create(&[]models.UnitOfMeasure{
+		{Code: "pcs", Name: "Piece", Dimension: models.DimensionCount, Factor: 1},
+		{Code: "g", Name: "Gram", Dimension: models.DimensionWeight, Factor: 1},
+		{Code: "kg", Name: "Kilogram", Dimension: models.DimensionWeight, Factor: 1000},
+		{Code: "ml", Name: "Millilitre", Dimension: models.DimensionVolume, Factor: 1},
+		{Code: "l", Name: "Litre", Dimension: models.DimensionVolume, Factor: 1000},
+	}).Error
+}
+
+func loadUnits(db *gorm.DB, ids ...*uint) ([]*models.UnitOfMeasure, error) {
+	units := make([]*models.UnitOfMeasure, len(ids))
+	for i, id := range ids {
+		if id == nil {
+			continue
+		}
+		var unit models.UnitOfMeasure
+		if err := db.First(&unit, *id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: %d", ErrUnitNotFound, *id)
+			}
+			return nil, err
+		}
+		units[i] = &unit
+	}
+	return units, nil
+}
+
+// unitFactors works out how many stock units make up a sell and a purchase
+// unit. Missing sell and purchase units default to the stock unit.
+func unitFactors(stock, sell, purchase *models.UnitOfMeasure) (*ItemUnits, error) {
+	if sell == nil {
+		sell = stock
+	}
+	if purchase == nil {
+		purchase = stock
+	}
+	units := &ItemUnits{StockUnit: stock, SellUnit: sell, PurchaseUnit: purchase, SellFactor: 1, PurchaseFactor: 1}
+	if stock == nil {
+		if sell != nil || purchase != nil {
+			// Without a stock unit the item is counted in pieces
+			stock = &models.UnitOfMeasure{Code: "pcs", Dimension: models.DimensionCount, Factor: 1}
+		} else {
+			return units, nil
+		}
+	}
+
+	var err error
+	if sell != nil {
+		if units.SellFactor, err = unitFactor(stock, sell); err != nil {
+			return nil, err
+		}
+	}
+	if purchase != nil {
+		if units.PurchaseFactor, err = unitFactor(stock, purchase); err != nil {
+			return nil, err
+		}
+	}
+	return units, nil
+}
+
+func unitFactor(stock, unit *models.UnitOfMeasure) (int, error) {
+	if unit.Dimension != stock.Dimension {
+		return 0, fmt.Errorf("%w: %s is %s, %s is %s", ErrUnitMismatch, unit.Code, unit.Dimension, stock.Code, stock.Dimension)
+	}
+	factor := unit.Factor / stock.Factor
+	rounded := math.Round(factor)
+	if rounded < 1 || math.Abs(factor-rounded) > 1e-6 {
+		return 0, fmt.Errorf("%w: %s is not a whole number of %s", ErrUnitFactor, unit.Code, stock.Code)
+	}
+	return int(rounded), nil
+}
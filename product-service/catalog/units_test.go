@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
+
+func TestItemUnitsToStock(t *testing.T) {
+	gram := &models.UnitOfMeasure{Code: "g", Dimension: models.DimensionWeight, Factor: 1}
+	kilogram := &models.UnitOfMeasure{Code: "kg", Dimension: models.DimensionWeight, Factor: 1000}
+
+	tests := []struct {
+		name     string
+		units    ItemUnits
+		quantity float64
+		want     int
+		err      error
+	}{
+		{"pieces", ItemUnits{SellFactor: 1}, 3, 3, nil},
+		{"fraction of a piece", ItemUnits{SellFactor: 1}, 1.5, 0, ErrFractionalQuantity},
+		{"whole kg stocked in g", ItemUnits{StockUnit: gram, SellUnit: kilogram, SellFactor: 1000}, 2, 2000, nil},
+		{"fraction of a kg needs a weighed item", ItemUnits{StockUnit: gram, SellUnit: kilogram, SellFactor: 1000}, 0.25, 0, ErrFractionalQuantity},
+		{"weighed fraction of a kg", ItemUnits{StockUnit: gram, SellUnit: kilogram, SellFactor: 1000, Weighed: true}, 0.25, 250, nil},
+		{"weighed float rounding", ItemUnits{StockUnit: gram, SellUnit: kilogram, SellFactor: 1000, Weighed: true}, 0.3, 300, nil},
+		{"weighed below one stock unit", ItemUnits{StockUnit: gram, SellUnit: kilogram, SellFactor: 1000, Weighed: true}, 0.0005, 0, ErrFractionalQuantity},
+		{"weighed sold in its stock unit", ItemUnits{StockUnit: gram, SellUnit: gram, SellFactor: 1, Weighed: true}, 1.5, 0, ErrFractionalQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.units.ToStock(tt.quantity)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ToStock(%g) error = %v, want %v", tt.quantity, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("ToStock(%g) = %d, want %d", tt.quantity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitFactors(t *testing.T) {
+	gram := &models.UnitOfMeasure{Code: "g", Dimension: models.DimensionWeight, Factor: 1}
+	kilogram := &models.UnitOfMeasure{Code: "kg", Dimension: models.DimensionWeight, Factor: 1000}
+	litre := &models.UnitOfMeasure{Code: "l", Dimension: models.DimensionVolume, Factor: 1000}
+
+	units, err := unitFactors(gram, kilogram, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if units.SellFactor != 1000 || units.PurchaseFactor != 1 {
+		t.Errorf("g/kg factors = %d/%d, want 1000/1", units.SellFactor, units.PurchaseFactor)
+	}
+
+	if _, err := unitFactors(gram, litre, nil); !errors.Is(err, ErrUnitMismatch) {
+		t.Errorf("g/l error = %v, want %v", err, ErrUnitMismatch)
+	}
+	if _, err := unitFactors(kilogram, gram, nil); !errors.Is(err, ErrUnitFactor) {
+		t.Errorf("kg/g error = %v, want %v", err, ErrUnitFactor)
+	}
+}
@@ -0,0 +1,293 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"gorm.io/gorm"
+)
+
+// MaxGeneratedVariants caps the number of combinations a single generate call
+// may produce.
+const MaxGeneratedVariants = 500
+
+var (
+	ErrInvalidAttributes = errors.New("attributes must be a JSON object")
+	ErrDuplicateVariant  = errors.New("another variant of this product has the same attributes")
+	ErrNoOptions         = errors.New("product has no options to generate variants from")
+	ErrTooManyVariants   = fmt.Errorf("options produce more than %d variants", MaxGeneratedVariants)
+)
+
+// GenerateResult lists the variants created by GenerateVariants and the
+// combinations skipped because a variant already had them.
+type GenerateResult struct {
+	Created []models.ProductVariant `json:"created"`
+	Skipped []string                `json:"skipped"`
+}
+
+// AttributeKey normalizes a variant's attributes JSON so combinations can be
+// compared: names and values are trimmed and lower-cased and sorted by name,
+// e.g. color=red|size=m. Empty attributes give an empty key.
+func AttributeKey(attributes string) (string, error) {
+	if strings.TrimSpace(attributes) == "" {
+		return "", nil
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(attributes), &values); err != nil || values == nil {
+		return "", ErrInvalidAttributes
+	}
+
+	pairs := make([]string, 0, len(values))
+	for name, value := range values {
+		pairs = append(pairs, fmt.Sprintf("%s=%s",
+			strings.ToLower(strings.TrimSpace(name)),
+			strings.ToLower(strings.TrimSpace(fmt.Sprint(value)))))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "|"), nil
+}
+
+// CheckVariantAttributes returns the attribute key for a variant of a product,
+// or ErrDuplicateVariant when another variant, other than excludeID, already
+// has the same combination.
+func CheckVariantAttributes(db *gorm.DB, productID, excludeID uint, attributes string) (string, error) {
+	key, err := AttributeKey(attributes)
+	if err != nil || key == "" {
+		return key, err
+	}
+
+	var count int64
+	if err := db.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND attribute_key = ? AND id <> ?", productID, key, excludeID).
+		Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", ErrDuplicateVariant
+	}
+	return key, nil
+}
+
+// ProductOptions returns a product's options and their values in display
+// order.
+func ProductOptions(db *gorm.DB, productID uint) ([]models.ProductOption, error) {
+	var options []models.ProductOption
+	err := db.Preload("Values", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order asc, id asc")
+	}).
+		Where("product_id = ?", productID).
+		Order("sort_order asc, id asc").
+		Find(&options).Error
+	return options, err
+}
+
+// ReplaceOptions replaces all options of a product. Existing variants keep
+// their attributes; only variants generated afterwards use the new options.
+func ReplaceOptions(tx *gorm.DB, productID uint, options []models.ProductOption) error {
+	names := map[string]bool{}
+	for i := range options {
+		name := strings.ToLower(strings.TrimSpace(options[i].Name))
+		if names[name] {
+			return fmt.Errorf("option %s is listed more than once", options[i].Name)
+		}
+		names[name] = true
+
+		values := map[string]bool{}
+		for j := range options[i].Values {
+			value := strings.ToLower(strings.TrimSpace(options[i].Values[j].Value))
+			if values[value] {
+				return fmt.Errorf("value %s is listed more than once in %s", options[i].Values[j].Value, options[i].Name)
+			}
+			values[value] = true
+			options[i].Values[j].SortOrder = j
+		}
+
+		options[i].ID = 0
+		options[i].ProductID = productID
+		options[i].SortOrder = i
+	}
+
+	if err := tx.Where("option_id IN (?)", tx.Model(&models.ProductOption{}).Select("id").Where("product_id = ?", productID)).
+		Delete(&models.ProductOptionValue{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("product_id = ?", productID).Delete(&models.ProductOption{}).Error; err != nil {
+		return err
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return tx.Create(&options).Error
+}
+
+// GenerateVariants creates a variant for every combination of the product's
+// option values that no variant has yet. Generated variants get a SKU built
+// from the product SKU and the value codes, the product price plus the values'
+// price adjustments, and stock as opening stock at the product's cost price.
+func GenerateVariants(tx *gorm.DB, product *models.Product, stock int, userID *uint) (*GenerateResult, error) {
+	options, err := ProductOptions(tx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	combinations := 1
+	for _, option := range options {
+		combinations *= len(option.Values)
+		if combinations > MaxGeneratedVariants {
+			return nil, ErrTooManyVariants
+		}
+	}
+	if len(options) == 0 || combinations == 0 {
+		return nil, ErrNoOptions
+	}
+
+	var existing []string
+	if err := tx.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND attribute_key <> ''", product.ID).
+		Pluck("attribute_key", &existing).Error; err != nil {
+		return nil, err
+	}
+	taken := map[string]bool{}
+	for _, key := range existing {
+		taken[key] = true
+	}
+
+	result := &GenerateResult{Created: []models.ProductVariant{}, Skipped: []string{}}
+	picks := make([]int, len(options))
+	for {
+		attributes := map[string]string{}
+		names := make([]string, len(options))
+		codes := make([]string, len(options))
+		price := product.Price
+		for i, option := range options {
+			value := option.Values[picks[i]]
+			attributes[option.Name] = value.Value
+			names[i] = value.Value
+			codes[i] = value.SKUCode
+			if codes[i] == "" {
+				codes[i] = strings.ToUpper(slugify(value.Value))
+			}
+			price += value.PriceAdjustment
+		}
+
+		data, _ := json.Marshal(attributes)
+		key, _ := AttributeKey(string(data))
+		name := product.Name + " - " + strings.Join(names, " / ")
+
+		if taken[key] {
+			result.Skipped = append(result.Skipped, name)
+		} else {
+			sku, err := freeSKU(tx, product.SKU+"-"+strings.Join(codes, "-"))
+			if err != nil {
+				return nil, err
+			}
+
+			variant := models.ProductVariant{
+				ProductID:    product.ID,
+				Name:         name,
+				SKU:          sku,
+				Price:        math.Round(price*100) / 100,
+				Attributes:   string(data),
+				AttributeKey: key,
+				Weight:       product.Weight,
+				Dimensions:   product.Dimensions,
+			}
+			if err := tx.Create(&variant).Error; err != nil {
+				return nil, err
+			}
+			if err := pricing.RecordChange(tx, models.ItemTypeVariant, variant.ID, nil, variant.Price,
+				models.PriceSourceGenerated, userID); err != nil {
+				return nil, err
+			}
+
+			if stock > 0 {
+				costPrice := product.CostPrice
+				if _, err := inventory.Post(tx, inventory.Movement{
+					ItemType:     models.ItemTypeVariant,
+					ItemID:       variant.ID,
+					MovementType: models.MovementAdjustment,
+					Quantity:     stock,
+					Reason:       "Opening stock",
+					UnitCost:     &costPrice,
+					UserID:       userID,
+				}); err != nil {
+					return nil, err
+				}
+				variant.Stock = stock
+			}
+
+			taken[key] = true
+			result.Created = append(result.Created, variant)
+		}
+
+		// Advance to the next combination, the last option changing fastest
+		i := len(picks) - 1
+		for ; i >= 0; i-- {
+			picks[i]++
+			if picks[i] < len(options[i].Values) {
+				break
+			}
+			picks[i] = 0
+		}
+		if i < 0 {
+			return result, nil
+		}
+	}
+}
+
+// BackfillAttributeKeys fills in the attribute key of variants created before
+// keys existed. When older variants of a product share a combination, only the
+// first keeps the key and the others are logged for manual clean-up.
+func BackfillAttributeKeys(db *gorm.DB) error {
+	var variants []models.ProductVariant
+	if err := db.Select("id, product_id, attributes").
+		Where("attribute_key IS NULL").
+		Order("id asc").
+		Find(&variants).Error; err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		key, err := AttributeKey(variant.Attributes)
+		if err == nil && key != "" {
+			_, err = CheckVariantAttributes(db, variant.ProductID, variant.ID, variant.Attributes)
+		}
+		if err != nil {
+			log.Printf("Variant %d: %v; leaving its attributes unchecked", variant.ID, err)
+			key = ""
+		}
+		if err := db.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).
+			Update("attribute_key", key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freeSKU returns sku, or sku with a numeric suffix when it is already used by
+// a product or variant, deleted ones included.
+func freeSKU(tx *gorm.DB, sku string) (string, error) {
+	candidate := sku
+	for n := 2; ; n++ {
+		var count int64
+		if err := tx.Raw(`
+			SELECT (SELECT COUNT(*) FROM product_variants WHERE sku = @sku) +
+				(SELECT COUNT(*) FROM products WHERE sku = @sku)`,
+			map[string]interface{}{"sku": candidate}).Scan(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", sku, n)
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"os"
 
 	"github.com/joho/godotenv"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
 	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -36,11 +39,61 @@ func InitDB() *gorm.DB {
 		&models.ProductAddon{},
 		&models.ProductGroup{},
 		&models.ProductAddonMapping{},
+		&models.StockMovement{},
+		&models.CostLayer{},
+		&models.StockReservation{},
+		&models.StockReservationItem{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderItem{},
+		&models.StockTake{},
+		&models.StockTakeLine{},
+		&models.OutletStock{},
+		&models.StockTransfer{},
+		&models.StockTransferItem{},
+		&models.ModifierGroup{},
+		&models.ProductModifierGroup{},
+		&models.ProductOption{},
+		&models.ProductOptionValue{},
+		&models.BundleComponent{},
+		&models.BundleChoice{},
+		&models.PriceSchedule{},
+		&models.PriceHistory{},
+		&models.AvailabilityRule{},
+		&models.TaxClass{},
+		&models.UnitOfMeasure{},
+		&models.Ingredient{},
+		&models.RecipeLine{},
+		&models.ProductImage{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	if err := SetupSearch(db); err != nil {
+		log.Fatal("Failed to set up product search:", err)
+	}
+
+	if err := catalog.RebuildCategoryPaths(db); err != nil {
+		log.Fatal("Failed to build category paths:", err)
+	}
+
+	if err := catalog.BackfillAttributeKeys(db); err != nil {
+		log.Fatal("Failed to backfill variant attribute keys:", err)
+	}
+
+	if err := inventory.Backfill(db); err != nil {
+		log.Fatal("Failed to backfill inventory ledger:", err)
+	}
+
+	if err := pricing.SeedTaxClasses(db); err != nil {
+		log.Fatal("Failed to seed tax classes:", err)
+	}
+
+	if err := catalog.SeedUnits(db); err != nil {
+		log.Fatal("Failed to seed units of measure:", err)
+	}
+
 	return db
 }
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"gorm.io/gorm"
+)
+
+// searchMigrations add the full-text and trigram indexes used by product
+// search. The tsvector column is generated by Postgres, so it is not part of
+// the Product model and never written by the application.
+var searchMigrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(tags, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(short_description, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_sku_trgm ON products USING GIN (sku gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_product_variants_name_trgm ON product_variants USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_product_variants_sku_trgm ON product_variants USING GIN (sku gin_trgm_ops)`,
+}
+
+func SetupSearch(db *gorm.DB) error {
+	for _, statement := range searchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
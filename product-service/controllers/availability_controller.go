@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type AvailabilityController struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityController(db *gorm.DB) *AvailabilityController {
+	return &AvailabilityController{db: db}
+}
+
+func (c *AvailabilityController) GetProductRules(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	c.getRules(ctx, models.ItemTypeProduct, product.ID)
+}
+
+func (c *AvailabilityController) SetProductRules(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	c.setRules(ctx, models.ItemTypeProduct, product.ID)
+}
+
+func (c *AvailabilityController) GetGroupRules(ctx *gin.Context) {
+	var group models.ProductGroup
+	if err := c.db.Select("id").First(&group, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	c.getRules(ctx, models.ItemTypeGroup, group.ID)
+}
+
+func (c *AvailabilityController) SetGroupRules(ctx *gin.Context) {
+	var group models.ProductGroup
+	if err := c.db.Select("id").First(&group, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	c.setRules(ctx, models.ItemTypeGroup, group.ID)
+}
+
+// Check tells order-service whether items can be sold at an outlet right now.
+func (c *AvailabilityController) Check(ctx *gin.Context) {
+	var input dto.CheckSellableDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at := time.Now()
+	if input.At != nil {
+		at = *input.At
+	}
+
+	items := make([]catalog.SellableItem, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = catalog.SellableItem{ItemType: item.ItemType, ItemID: item.ItemID}
+	}
+
+	results, err := catalog.CheckSellable(c.db, items, input.Outlet, at)
+	if err != nil {
+		if errors.Is(err, catalog.ErrSellableItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check availability"})
+		return
+	}
+
+	sellable := true
+	for _, result := range results {
+		sellable = sellable && result.Sellable
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"sellable": sellable, "items": results})
+}
+
+// Helper functions
+func (c *AvailabilityController) getRules(ctx *gin.Context, itemType string, itemID uint) {
+	rules, err := catalog.ItemRules(c.db, itemType, itemID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch availability rules"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"timezone": catalog.StoreLocation().String(), "rules": rules})
+}
+
+func (c *AvailabilityController) setRules(ctx *gin.Context, itemType string, itemID uint) {
+	var input dto.SetAvailabilityDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rules := make([]models.AvailabilityRule, len(input.Rules))
+	for i, rule := range input.Rules {
+		rules[i] = models.AvailabilityRule{
+			Outlet:    rule.Outlet,
+			Days:      rule.Days,
+			StartTime: rule.StartTime,
+			EndTime:   rule.EndTime,
+		}
+		var err error
+		if rules[i].StartDate, err = parseOptionalDate(rule.StartDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Rule %d: invalid startDate, expected YYYY-MM-DD", i+1)})
+			return
+		}
+		if rules[i].EndDate, err = parseOptionalDate(rule.EndDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Rule %d: invalid endDate, expected YYYY-MM-DD", i+1)})
+			return
+		}
+	}
+
+	tx := c.db.Begin()
+	if err := catalog.ReplaceRules(tx, itemType, itemID, rules); err != nil {
+		tx.Rollback()
+		if errors.Is(err, catalog.ErrInvalidRule) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability rules"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability rules"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Availability rules updated successfully", "rules": rules})
+}
+
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
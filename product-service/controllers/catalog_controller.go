@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"gorm.io/gorm"
+)
+
+const maxImportSize = 10 << 20
+
+type CatalogController struct {
+	db *gorm.DB
+}
+
+func NewCatalogController(db *gorm.DB) *CatalogController {
+	return &CatalogController{db: db}
+}
+
+// Import upserts products, variants and addons from an uploaded CSV or XLSX
+// file, matched by SKU. With dryRun=true it only reports what would change and
+// which rows are invalid. Otherwise the whole file is applied in one
+// transaction, or not at all when any row is invalid.
+func (c *CatalogController) Import(ctx *gin.Context) {
+	dryRun, _ := strconv.ParseBool(ctx.Query("dryRun"))
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Upload the catalogue as a file field named file"})
+		return
+	}
+	if header.Size > maxImportSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is larger than 10 MB"})
+		return
+	}
+
+	format := ctx.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	records, err := catalog.Read(file, format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(records) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File has no rows to import"})
+		return
+	}
+
+	tx := c.db.Begin()
+	result, err := catalog.Import(tx, records, dryRun, currentUserID(ctx))
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to import catalogue: %v", err)})
+		return
+	}
+	if !result.Applied {
+		tx.Rollback()
+		status := http.StatusOK
+		if result.ErrorCount > 0 {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, result)
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Export downloads the whole catalogue as CSV (the default) or XLSX, in the
+// same layout Import reads.
+func (c *CatalogController) Export(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", catalog.FormatCSV)
+
+	rows, err := catalog.Export(c.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export catalogue"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := catalog.Write(&buf, format, rows); err != nil {
+		if errors.Is(err, catalog.ErrUnknownFormat) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write catalogue file"})
+		return
+	}
+
+	filename := fmt.Sprintf("catalog-%s.%s", time.Now().Format("20060102"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, catalog.ContentType(format), buf.Bytes())
+}
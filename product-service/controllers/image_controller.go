@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/storage"
+	"gorm.io/gorm"
+)
+
+// maxProductImages caps how many images one product can have.
+const maxProductImages = 10
+
+type ImageController struct {
+	db    *gorm.DB
+	store storage.Storage
+}
+
+func NewImageController(db *gorm.DB, store storage.Storage) *ImageController {
+	return &ImageController{db: db, store: store}
+}
+
+func (c *ImageController) ListProductImages(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	images, err := productImages(c.db, product.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product images"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, images)
+}
+
+// UploadProductImage adds an image, sent as the multipart field image with an
+// optional altText, after the product's existing images.
+func (c *ImageController) UploadProductImage(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var count int64
+	if err := c.db.Model(&models.ProductImage{}).Where("product_id = ?", product.ID).Count(&count).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count product images"})
+		return
+	}
+	if count >= maxProductImages {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A product can have at most %d images", maxProductImages)})
+		return
+	}
+
+	stored, ok := c.storeUpload(ctx, fmt.Sprintf("products/%d", product.ID))
+	if !ok {
+		return
+	}
+
+	image := models.ProductImage{
+		ProductID:    product.ID,
+		URL:          stored.URL,
+		ThumbnailURL: stored.ThumbnailURL,
+		ContentType:  stored.ContentType,
+		Width:        stored.Width,
+		Height:       stored.Height,
+		Size:         stored.Size,
+		AltText:      ctx.PostForm("altText"),
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Model(&models.ProductImage{}).
+		Select("coalesce(max(sort_order), -1) + 1").
+		Where("product_id = ?", product.ID).
+		Scan(&image.SortOrder).Error; err != nil {
+		tx.Rollback()
+		catalog.DeleteImage(ctx, c.store, stored.URL, stored.ThumbnailURL)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save product image"})
+		return
+	}
+	if err := tx.Create(&image).Error; err != nil {
+		tx.Rollback()
+		catalog.DeleteImage(ctx, c.store, stored.URL, stored.ThumbnailURL)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save product image"})
+		return
+	}
+	if err := syncMainImage(tx, product.ID); err != nil {
+		tx.Rollback()
+		catalog.DeleteImage(ctx, c.store, stored.URL, stored.ThumbnailURL)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusCreated, image)
+}
+
+func (c *ImageController) UpdateProductImage(ctx *gin.Context) {
+	var input dto.UpdateProductImageDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var image models.ProductImage
+	if err := c.db.Where("product_id = ?", ctx.Param("id")).First(&image, ctx.Param("imageId")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product image not found"})
+		return
+	}
+
+	if err := c.db.Model(&image).Update("alt_text", input.AltText).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, image)
+}
+
+// ReorderProductImages sets the order of all of a product's images. The first
+// becomes the product's main image.
+func (c *ImageController) ReorderProductImages(ctx *gin.Context) {
+	var input dto.ReorderProductImagesDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	images, err := productImages(c.db, product.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product images"})
+		return
+	}
+	if len(input.ImageIDs) != len(images) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Image IDs must list every image of the product once"})
+		return
+	}
+	owned := make(map[uint]bool, len(images))
+	for _, image := range images {
+		owned[image.ID] = true
+	}
+	for _, id := range input.ImageIDs {
+		if !owned[id] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Image IDs must list every image of the product once"})
+			return
+		}
+		delete(owned, id)
+	}
+
+	tx := c.db.Begin()
+	for position, id := range input.ImageIDs {
+		if err := tx.Model(&models.ProductImage{}).Where("id = ?", id).Update("sort_order", position).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder product images"})
+			return
+		}
+	}
+	if err := syncMainImage(tx, product.ID); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
+		return
+	}
+	tx.Commit()
+
+	if images, err = productImages(c.db, product.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product images"})
+		return
+	}
+	ctx.JSON(http.StatusOK, images)
+}
+
+func (c *ImageController) DeleteProductImage(ctx *gin.Context) {
+	var image models.ProductImage
+	if err := c.db.Where("product_id = ?", ctx.Param("id")).First(&image, ctx.Param("imageId")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product image not found"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Unscoped().Delete(&image).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product image"})
+		return
+	}
+
+	// Without images left, clear the main image only if it was this one so a
+	// link set by hand stays
+	var remaining int64
+	if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", image.ProductID).Count(&remaining).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
+		return
+	}
+	var err error
+	if remaining > 0 {
+		err = syncMainImage(tx, image.ProductID)
+	} else {
+		err = tx.Model(&models.Product{}).
+			Where("id = ? AND image_url = ?", image.ProductID, image.URL).
+			Updates(map[string]interface{}{"image_url": "", "thumbnail_url": ""}).Error
+	}
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product image"})
+		return
+	}
+	tx.Commit()
+
+	catalog.DeleteImage(ctx, c.store, image.URL, image.ThumbnailURL)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Product image deleted successfully"})
+}
+
+func (c *ImageController) UploadVariantImage(ctx *gin.Context) {
+	c.replaceImage(ctx, &models.ProductVariant{}, "variants", "Variant")
+}
+
+func (c *ImageController) UploadAddonImage(ctx *gin.Context) {
+	c.replaceImage(ctx, &models.ProductAddon{}, "addons", "Addon")
+}
+
+func (c *ImageController) UploadCategoryImage(ctx *gin.Context) {
+	c.replaceImage(ctx, &models.ProductCategory{}, "categories", "Category")
+}
+
+func (c *ImageController) UploadGroupImage(ctx *gin.Context) {
+	c.replaceImage(ctx, &models.ProductGroup{}, "groups", "Group")
+}
+
+// Helper functions
+
+// replaceImage stores an uploaded image as the single image of a variant,
+// addon, category or group and removes the one it replaces.
+func (c *ImageController) replaceImage(ctx *gin.Context, model interface{}, prefix, name string) {
+	var current struct {
+		ID           uint
+		ImageURL     string
+		ThumbnailURL string
+	}
+	result := c.db.Model(model).
+		Select("id, image_url, thumbnail_url").
+		Where("id = ?", ctx.Param("id")).
+		Limit(1).
+		Scan(&current)
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch " + prefix})
+		return
+	}
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": name + " not found"})
+		return
+	}
+
+	stored, ok := c.storeUpload(ctx, fmt.Sprintf("%s/%d", prefix, current.ID))
+	if !ok {
+		return
+	}
+
+	if err := c.db.Model(model).Where("id = ?", current.ID).Updates(map[string]interface{}{
+		"image_url":     stored.URL,
+		"thumbnail_url": stored.ThumbnailURL,
+	}).Error; err != nil {
+		catalog.DeleteImage(ctx, c.store, stored.URL, stored.ThumbnailURL)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + prefix})
+		return
+	}
+
+	catalog.DeleteImage(ctx, c.store, current.ImageURL, current.ThumbnailURL)
+	ctx.JSON(http.StatusOK, gin.H{
+		"imageUrl":     stored.URL,
+		"thumbnailUrl": stored.ThumbnailURL,
+	})
+}
+
+// storeUpload reads the multipart field image and stores it under prefix. It
+// writes the error response itself and returns false on failure.
+func (c *ImageController) storeUpload(ctx *gin.Context, prefix string) (*catalog.StoredImage, bool) {
+	// Leave room for the multipart framing and other fields
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, catalog.MaxImageBytes+1<<20)
+
+	header, err := ctx.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": catalog.ErrImageTooLarge.Error()})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Image file is required"})
+		return nil, false
+	}
+	if header.Size > catalog.MaxImageBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": catalog.ErrImageTooLarge.Error()})
+		return nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image"})
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, catalog.MaxImageBytes+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read image"})
+		return nil, false
+	}
+
+	stored, err := catalog.StoreImage(ctx, c.store, prefix, data)
+	if err != nil {
+		status := imageErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			ctx.JSON(status, gin.H{"error": "Failed to store image"})
+		} else {
+			ctx.JSON(status, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return stored, true
+}
+
+func productImages(db *gorm.DB, productID uint) ([]models.ProductImage, error) {
+	images := []models.ProductImage{}
+	err := db.Where("product_id = ?", productID).Order("sort_order asc, id asc").Find(&images).Error
+	return images, err
+}
+
+// syncMainImage copies the product's first image into its ImageURL and
+// ThumbnailURL, which product lists and search show.
+func syncMainImage(tx *gorm.DB, productID uint) error {
+	var first models.ProductImage
+	result := tx.Where("product_id = ?", productID).Order("sort_order asc, id asc").Limit(1).Find(&first)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return tx.Model(&models.Product{}).Where("id = ?", productID).Updates(map[string]interface{}{
+		"image_url":     first.URL,
+		"thumbnail_url": first.ThumbnailURL,
+	}).Error
+}
+
+func imageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, catalog.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, catalog.ErrImageType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, catalog.ErrImageDimensions):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
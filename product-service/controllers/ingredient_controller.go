@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type IngredientController struct {
+	db *gorm.DB
+}
+
+func NewIngredientController(db *gorm.DB) *IngredientController {
+	return &IngredientController{db: db}
+}
+
+func (c *IngredientController) Create(ctx *gin.Context) {
+	var input dto.CreateIngredientDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if ingredientSKUTaken(c.db, input.SKU, 0) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "SKU already exists"})
+		return
+	}
+	if input.UnitID != nil && !unitExists(c.db, *input.UnitID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	ingredient := models.Ingredient{
+		Name:      input.Name,
+		SKU:       input.SKU,
+		UnitID:    input.UnitID,
+		CostPrice: input.CostPrice,
+		MinStock:  input.MinStock,
+		IsActive:  true,
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Create(&ingredient).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create ingredient"})
+		return
+	}
+
+	// Opening stock is received rather than adjusted so it does not show up
+	// as usage
+	if input.Stock > 0 {
+		if _, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     models.ItemTypeIngredient,
+			ItemID:       ingredient.ID,
+			MovementType: models.MovementReceiving,
+			Quantity:     input.Stock,
+			Reason:       "Opening stock",
+			UnitCost:     &input.CostPrice,
+			UserID:       currentUserID(ctx),
+		}); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record opening stock"})
+			return
+		}
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Ingredient created successfully", "id": ingredient.ID})
+}
+
+func (c *IngredientController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateIngredientDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var ingredient models.Ingredient
+	if err := c.db.First(&ingredient, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.SKU != "" && input.SKU != ingredient.SKU {
+		if ingredientSKUTaken(c.db, input.SKU, ingredient.ID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "SKU already exists"})
+			return
+		}
+		updates["sku"] = input.SKU
+	}
+	if input.UnitID != nil {
+		unitID := unitUpdate(ingredient.UnitID, input.UnitID)
+		if !sameUnit(unitID, ingredient.UnitID) {
+			// Stock and recipes are counted in the current unit
+			if ingredient.Stock != 0 {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unit can only change while the ingredient has no stock"})
+				return
+			}
+			if unitID != nil && !unitExists(c.db, *unitID) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unit not found"})
+				return
+			}
+			updates["unit_id"] = unitID
+		}
+	}
+	if input.MinStock != nil {
+		if *input.MinStock < 0 {
+			updates["min_stock"] = nil
+		} else {
+			updates["min_stock"] = *input.MinStock
+		}
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+	}
+
+	if err := c.db.Model(&ingredient).Updates(updates).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ingredient"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Ingredient updated successfully"})
+}
+
+func (c *IngredientController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var ingredient models.Ingredient
+	if err := c.db.First(&ingredient, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found"})
+		return
+	}
+
+	// Check for recipes of items still on sale using this ingredient
+	var recipeCount int64
+	if err := c.db.Model(&models.RecipeLine{}).
+		Where("ingredient_id = ?", ingredient.ID).
+		Where(`(item_type = 'product' AND item_id IN (SELECT id FROM products WHERE deleted_at IS NULL))
+			OR (item_type = 'variant' AND item_id IN (SELECT id FROM product_variants WHERE deleted_at IS NULL))
+			OR (item_type = 'addon' AND item_id IN (SELECT id FROM product_addons WHERE deleted_at IS NULL))`).
+		Count(&recipeCount).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check ingredient usage"})
+		return
+	}
+
+	if recipeCount > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete ingredient used in recipes"})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	// Recipes of deleted items may still list it
+	if err := tx.Unscoped().Where("ingredient_id = ?", ingredient.ID).Delete(&models.RecipeLine{}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe lines"})
+		return
+	}
+
+	if err := tx.Delete(&ingredient).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete ingredient"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Ingredient deleted successfully"})
+}
+
+func (c *IngredientController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var ingredient models.Ingredient
+	if err := c.db.Preload("Unit").First(&ingredient, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ingredient)
+}
+
+func (c *IngredientController) List(ctx *gin.Context) {
+	query := c.db.Preload("Unit").Order("name asc")
+	if q := ctx.Query("q"); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("name ILIKE ? OR sku ILIKE ?", pattern, pattern)
+	}
+	if ctx.Query("active") == "true" {
+		query = query.Where("is_active = ?", true)
+	}
+	if ctx.Query("lowStock") == "true" {
+		query = query.Where("min_stock IS NOT NULL AND stock <= min_stock")
+	}
+
+	var ingredients []models.Ingredient
+	if err := query.Find(&ingredients).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ingredients"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ingredients)
+}
+
+// Usage compares how much of each ingredient recipes say was used with how
+// much actually left stock over a period.
+func (c *IngredientController) Usage(ctx *gin.Context) {
+	var params dto.IngredientUsageQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := time.Parse(dateLayout, params.From)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+		return
+	}
+	to, err := time.Parse(dateLayout, params.To)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+		return
+	}
+	if to.Before(from) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "To date must not be before from date"})
+		return
+	}
+
+	rows, err := inventory.IngredientUsageReport(c.db, from, to.AddDate(0, 0, 1), params.IngredientID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build usage report"})
+		return
+	}
+
+	report := dto.IngredientUsageReportDTO{
+		From:        params.From,
+		To:          params.To,
+		Ingredients: rows,
+	}
+	for _, row := range rows {
+		report.TheoreticalCost += row.TheoreticalCost
+		report.ActualCost += row.ActualCost
+	}
+	report.TheoreticalCost = roundPrice(report.TheoreticalCost)
+	report.ActualCost = roundPrice(report.ActualCost)
+	report.VarianceCost = roundPrice(report.ActualCost - report.TheoreticalCost)
+	if report.Ingredients == nil {
+		report.Ingredients = []inventory.IngredientUsage{}
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// Helper functions
+func ingredientSKUTaken(db *gorm.DB, sku string, excludeID uint) bool {
+	var count int64
+	db.Unscoped().Model(&models.Ingredient{}).Where("sku = ? AND id <> ?", sku, excludeID).Count(&count)
+	return count > 0
+}
+
+func unitExists(db *gorm.DB, id uint) bool {
+	var exists bool
+	db.Model(&models.UnitOfMeasure{}).Select("count(*) > 0").Where("id = ?", id).Find(&exists)
+	return exists
+}
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+const dateLayout = "2006-01-02"
+
+type InventoryController struct {
+	db *gorm.DB
+}
+
+func NewInventoryController(db *gorm.DB) *InventoryController {
+	return &InventoryController{db: db}
+}
+
+// RecordMovement posts a manual stock movement. Quantities for receiving,
+// returns and waste are given as positive amounts; adjustments are signed.
+func (c *InventoryController) RecordMovement(ctx *gin.Context) {
+	var input dto.RecordMovementDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quantity := input.Quantity
+	if input.MovementType != models.MovementAdjustment && quantity < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive for " + input.MovementType})
+		return
+	}
+	if input.MovementType == models.MovementWaste {
+		quantity = -quantity
+	}
+
+	tx := c.db.Begin()
+	movement, err := inventory.Post(tx, inventory.Movement{
+		ItemType:     input.ItemType,
+		ItemID:       input.ItemID,
+		MovementType: input.MovementType,
+		Quantity:     quantity,
+		Reason:       input.Reason,
+		Reference:    input.Reference,
+		UserID:       currentUserID(ctx),
+		UnitCost:     input.UnitCost,
+		Outlet:       input.Outlet,
+	})
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(inventoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusCreated, movement)
+}
+
+func (c *InventoryController) ListMovements(ctx *gin.Context) {
+	var params dto.MovementHistoryQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Model(&models.StockMovement{})
+	if params.ItemType != "" {
+		query = query.Where("item_type = ?", params.ItemType)
+	}
+	if params.ItemID != nil {
+		query = query.Where("item_id = ?", *params.ItemID)
+	}
+	if params.MovementType != "" {
+		query = query.Where("movement_type = ?", params.MovementType)
+	}
+	if params.Reference != "" {
+		query = query.Where("reference = ?", params.Reference)
+	}
+	if params.Outlet != "" {
+		query = query.Where("outlet = ?", params.Outlet)
+	}
+	if params.From != "" {
+		from, err := time.Parse(dateLayout, params.From)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if params.To != "" {
+		to, err := time.Parse(dateLayout, params.To)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count movements"})
+		return
+	}
+
+	var movements []models.StockMovement
+	if err := query.Order("created_at desc, id desc").
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize).
+		Find(&movements).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch movements"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.MovementListResponse{
+		Movements:   movements,
+		TotalCount:  total,
+		CurrentPage: params.Page,
+		PageSize:    params.PageSize,
+	})
+}
+
+// StockLevel compares the quantity on hand derived from the ledger with the
+// cached balance on the item, and breaks the balance down by outlet.
+func (c *InventoryController) StockLevel(ctx *gin.Context) {
+	itemType := ctx.Param("itemType")
+	itemID, err := strconv.ParseUint(ctx.Param("itemId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var item struct {
+		Name  string
+		SKU   string
+		Stock int
+	}
+	var result *gorm.DB
+	switch itemType {
+	case models.ItemTypeProduct:
+		result = c.db.Model(&models.Product{}).Select("name, sku, stock").Where("id = ?", itemID).Scan(&item)
+	case models.ItemTypeVariant:
+		result = c.db.Model(&models.ProductVariant{}).Select("name, sku, stock").Where("id = ?", itemID).Scan(&item)
+	case models.ItemTypeAddon:
+		result = c.db.Model(&models.ProductAddon{}).Select("name, sku, stock").Where("id = ?", itemID).Scan(&item)
+	case models.ItemTypeIngredient:
+		result = c.db.Model(&models.Ingredient{}).Select("name, sku, stock").Where("id = ?", itemID).Scan(&item)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item type"})
+		return
+	}
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	onHand, err := inventory.OnHand(c.db, itemType, uint(itemID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stock on hand"})
+		return
+	}
+
+	id := uint(itemID)
+	outlets, err := inventory.OutletLevels(c.db, inventory.OutletFilter{ItemType: itemType, ItemID: &id})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch outlet stock"})
+		return
+	}
+	unassigned := item.Stock
+	for _, outlet := range outlets {
+		unassigned -= outlet.Quantity
+	}
+	if outlets == nil {
+		outlets = []inventory.OutletLevel{}
+	}
+
+	ctx.JSON(http.StatusOK, dto.StockLevelDTO{
+		ItemType:   itemType,
+		ItemID:     id,
+		Name:       item.Name,
+		SKU:        item.SKU,
+		OnHand:     onHand,
+		Balance:    item.Stock,
+		InSync:     onHand == item.Stock,
+		Outlets:    outlets,
+		Unassigned: unassigned,
+	})
+}
+
+// OutletStock lists stock held at each outlet, optionally for one outlet or
+// item.
+func (c *InventoryController) OutletStock(ctx *gin.Context) {
+	var params dto.OutletStockQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	levels, err := inventory.OutletLevels(c.db, inventory.OutletFilter{
+		Outlet:   params.Outlet,
+		ItemType: params.ItemType,
+		ItemID:   params.ItemID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch outlet stock"})
+		return
+	}
+	if levels == nil {
+		levels = []inventory.OutletLevel{}
+	}
+
+	ctx.JSON(http.StatusOK, levels)
+}
+
+// AssignOutletStock records where existing stock is held, such as opening
+// balances, without posting a movement that would change the total.
+func (c *InventoryController) AssignOutletStock(ctx *gin.Context) {
+	var input dto.AssignOutletStockDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := inventory.AssignOutletStock(tx, input.Outlet, input.ItemType, input.ItemID, input.Quantity); err != nil {
+		tx.Rollback()
+		ctx.JSON(inventoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	levels, err := inventory.OutletLevels(c.db, inventory.OutletFilter{
+		Outlet:   input.Outlet,
+		ItemType: input.ItemType,
+		ItemID:   &input.ItemID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch outlet stock"})
+		return
+	}
+	if levels == nil {
+		levels = []inventory.OutletLevel{}
+	}
+
+	ctx.JSON(http.StatusOK, levels)
+}
+
+// LowStock reports every product and variant at or below its reorder point,
+// with a suggested order quantity.
+func (c *InventoryController) LowStock(ctx *gin.Context) {
+	items, err := inventory.LowStock(c.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch low stock items"})
+		return
+	}
+	if items == nil {
+		items = []inventory.LowStockItem{}
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// Helper functions
+func inventoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, inventory.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, inventory.ErrInsufficientStock):
+		return http.StatusConflict
+	case errors.Is(err, inventory.ErrInvalidItemType),
+		errors.Is(err, inventory.ErrInvalidMovementType),
+		errors.Is(err, inventory.ErrInvalidQuantity):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
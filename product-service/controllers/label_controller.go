@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/utils"
+	"gorm.io/gorm"
+)
+
+type LabelController struct {
+	db *gorm.DB
+}
+
+func NewLabelController(db *gorm.DB) *LabelController {
+	return &LabelController{db: db}
+}
+
+// GenerateBarcodes assigns in-store EAN-13 codes to variants that have no
+// barcode yet. Codes are the LABEL_BARCODE_PREFIX (default 04, a GS1
+// restricted circulation prefix for use within a company) followed by the
+// zero padded variant ID.
+func (c *LabelController) GenerateBarcodes(ctx *gin.Context) {
+	var input dto.GenerateBarcodesDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prefix := barcodePrefix()
+
+	query := c.db.Where("bar_code IS NULL OR bar_code = ''")
+	if len(input.ProductIDs) > 0 {
+		query = query.Where("product_id IN ?", input.ProductIDs)
+	}
+
+	var variants []models.ProductVariant
+	if err := query.Order("id asc").Find(&variants).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch variants"})
+		return
+	}
+
+	generated := make([]dto.GeneratedBarcodeDTO, 0, len(variants))
+	tx := c.db.Begin()
+	for _, variant := range variants {
+		code, ok := generatedBarCode(prefix, variant.ID)
+		if !ok {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Variant %d does not fit in the barcode prefix range", variant.ID)})
+			return
+		}
+
+		if barCodeInUse(tx, code) {
+			tx.Rollback()
+			ctx.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Barcode %s is already in use", code)})
+			return
+		}
+
+		if err := tx.Model(&variant).Update("bar_code", code).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign barcode"})
+			return
+		}
+
+		generated = append(generated, dto.GeneratedBarcodeDTO{
+			VariantID: variant.ID,
+			ProductID: variant.ProductID,
+			Name:      variant.Name,
+			BarCode:   code,
+		})
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, generated)
+}
+
+func (c *LabelController) BarcodeImage(ctx *gin.Context) {
+	var params dto.BarcodeImageQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bc, err := utils.EncodeBarcode(params.Code, params.Symbology)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to encode barcode: " + err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+	if params.Format == "svg" {
+		contentType = "image/svg+xml"
+		err = utils.WriteBarcodeSVG(&buf, bc, params.Width, params.Height)
+	} else {
+		err = utils.WriteBarcodePNG(&buf, bc, params.Width, params.Height)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to render barcode: " + err.Error()})
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// Sheet renders a printable PDF of shelf labels. Selected products with
+// variants get one label per variant.
+func (c *LabelController) Sheet(ctx *gin.Context) {
+	var input dto.LabelSheetDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(input.ProductIDs) == 0 && len(input.VariantIDs) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Select at least one product or variant"})
+		return
+	}
+
+	copies := input.Copies
+	if copies == 0 {
+		copies = 1
+	}
+
+	var labels []utils.Label
+	addLabel := func(name, detail, sku, barCode string, price float64) error {
+		code := barCode
+		if code == "" {
+			code = sku
+		}
+		bc, err := utils.EncodeBarcode(code, input.Symbology)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for i := 0; i < copies; i++ {
+			labels = append(labels, utils.Label{Name: name, Detail: detail, Price: price, Code: code, Barcode: bc})
+		}
+		return nil
+	}
+
+	if len(input.ProductIDs) > 0 {
+		var products []models.Product
+		if err := c.db.Preload("Variants").Where("id IN ?", input.ProductIDs).Order("name asc").Find(&products).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+			return
+		}
+
+		for _, product := range products {
+			if len(product.Variants) == 0 {
+				if err := addLabel(product.Name, product.ShortDescription, product.SKU, product.BarCode, product.Price); err != nil {
+					ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				continue
+			}
+			for _, variant := range product.Variants {
+				if err := addLabel(product.Name, variant.Name, variant.SKU, variant.BarCode, variant.Price); err != nil {
+					ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+			}
+		}
+	}
+
+	if len(input.VariantIDs) > 0 {
+		var variants []models.ProductVariant
+		if err := c.db.Preload("Product").Where("id IN ?", input.VariantIDs).Order("product_id asc, id asc").Find(&variants).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch variants"})
+			return
+		}
+
+		for _, variant := range variants {
+			if err := addLabel(variant.Product.Name, variant.Name, variant.SKU, variant.BarCode, variant.Price); err != nil {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := utils.WriteLabelSheet(&buf, labels, labelCurrency()); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render label sheet"})
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="labels.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// Helper functions
+
+// barcodePrefix falls back to 04 for invalid prefixes and for those starting
+// with 2, which Lookup reads as price or weight embedded labels.
+func barcodePrefix() string {
+	prefix := os.Getenv("LABEL_BARCODE_PREFIX")
+	if len(prefix) < 2 || len(prefix) > 6 || strings.Trim(prefix, "0123456789") != "" {
+		return "04"
+	}
+	if prefix[0] == '2' {
+		log.Printf("LABEL_BARCODE_PREFIX %s overlaps the in-store weighted range 20-29, using 04", prefix)
+		return "04"
+	}
+	return prefix
+}
+
+// generatedBarCode builds an EAN-13 from the prefix and the zero padded id,
+// reporting false when the id is too long for the prefix.
+func generatedBarCode(prefix string, id uint) (string, bool) {
+	body := fmt.Sprintf("%s%0*d", prefix, 12-len(prefix), id)
+	if len(body) != 12 {
+		return "", false
+	}
+	return body + string(utils.CheckDigit(body)), true
+}
+
+func labelCurrency() string {
+	if currency := os.Getenv("LABEL_CURRENCY"); currency != "" {
+		return currency
+	}
+	return "Rp"
+}
+
+func barCodeInUse(db *gorm.DB, code string) bool {
+	var exists bool
+	db.Raw(`SELECT EXISTS (SELECT 1 FROM product_variants WHERE bar_code = @code AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM products WHERE bar_code = @code AND deleted_at IS NULL)
+		OR EXISTS (SELECT 1 FROM product_addons WHERE bar_code = @code AND deleted_at IS NULL)`,
+		map[string]interface{}{"code": code}).Scan(&exists)
+	return exists
+}
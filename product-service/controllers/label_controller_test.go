@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/ridhotamma/yourkasa/product-service/utils"
+)
+
+func TestBarcodePrefix(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "04"},
+		{"0", "04"},
+		{"1234567", "04"},
+		{"4A", "04"},
+		{"20", "04"},
+		{"299", "04"},
+		{"04", "04"},
+		{"899", "899"},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LABEL_BARCODE_PREFIX", tt.env)
+		if got := barcodePrefix(); got != tt.want {
+			t.Errorf("barcodePrefix() with %q = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestGeneratedBarCode(t *testing.T) {
+	tests := []struct {
+		prefix string
+		id     uint
+		want   string
+		ok     bool
+	}{
+		{"04", 1, "0400000000015", true},
+		{"899", 42, "8990000000426", true},
+		{"899123", 999999, "8991239999994", true},
+		{"899123", 1000000, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := generatedBarCode(tt.prefix, tt.id)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("generatedBarCode(%q, %d) = %q, %v, want %q, %v", tt.prefix, tt.id, got, ok, tt.want, tt.ok)
+		}
+		if ok && !utils.ValidEAN13(got) {
+			t.Errorf("generatedBarCode(%q, %d) = %q is not a valid EAN-13", tt.prefix, tt.id, got)
+		}
+
+		// Generated codes must never read back as weighted labels
+		if ok {
+			scanned, err := utils.ParseScannedCode(got)
+			if err != nil || scanned.Weighted {
+				t.Errorf("ParseScannedCode(%q) = weighted %v, err %v", got, scanned.Weighted, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type ModifierGroupController struct {
+	db *gorm.DB
+}
+
+func NewModifierGroupController(db *gorm.DB) *ModifierGroupController {
+	return &ModifierGroupController{db: db}
+}
+
+func (c *ModifierGroupController) Create(ctx *gin.Context) {
+	var input dto.CreateModifierGroupDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := checkSelectionLimits(input.MinSelections, input.MaxSelections); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	addons, err := findAddons(c.db, input.AddonIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	group := models.ModifierGroup{
+		Name:          input.Name,
+		Description:   input.Description,
+		MinSelections: input.MinSelections,
+		MaxSelections: input.MaxSelections,
+		IsActive:      true,
+		Addons:        addons,
+	}
+	if input.IsActive != nil {
+		group.IsActive = *input.IsActive
+	}
+
+	if err := c.db.Create(&group).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create modifier group"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Modifier group created successfully", "id": group.ID})
+}
+
+func (c *ModifierGroupController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateModifierGroupDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var group models.ModifierGroup
+	if err := c.db.First(&group, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Modifier group not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Description != "" {
+		updates["description"] = input.Description
+	}
+	if input.MinSelections != nil {
+		group.MinSelections = *input.MinSelections
+		updates["min_selections"] = group.MinSelections
+	}
+	if input.MaxSelections != nil {
+		group.MaxSelections = *input.MaxSelections
+		updates["max_selections"] = group.MaxSelections
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+	}
+
+	if err := checkSelectionLimits(group.MinSelections, group.MaxSelections); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	if len(input.AddonIDs) > 0 {
+		addons, err := findAddons(tx, input.AddonIDs)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := tx.Model(&group).Association("Addons").Replace(&addons); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update modifier group addons"})
+			return
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&group).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update modifier group"})
+			return
+		}
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Modifier group updated successfully"})
+}
+
+func (c *ModifierGroupController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var group models.ModifierGroup
+	if err := c.db.First(&group, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Modifier group not found"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Where("modifier_group_id = ?", group.ID).Delete(&models.ProductModifierGroup{}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove modifier group from products"})
+		return
+	}
+	if err := tx.Model(&group).Association("Addons").Clear(); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete modifier group"})
+		return
+	}
+	if err := tx.Delete(&group).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete modifier group"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Modifier group deleted successfully"})
+}
+
+func (c *ModifierGroupController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var group models.ModifierGroup
+	if err := c.db.Preload("Addons").First(&group, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Modifier group not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+func (c *ModifierGroupController) List(ctx *gin.Context) {
+	var groups []models.ModifierGroup
+	if err := c.db.Preload("Addons").Order("name asc").Find(&groups).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch modifier groups"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, groups)
+}
+
+// AssignToProduct replaces the modifier groups offered on a product. The list
+// order is the display order.
+func (c *ModifierGroupController) AssignToProduct(ctx *gin.Context) {
+	var input dto.AssignModifierGroupsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := c.db.First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	links := make([]models.ProductModifierGroup, 0, len(input.ModifierGroups))
+	seen := map[uint]bool{}
+	for i, assigned := range input.ModifierGroups {
+		if seen[assigned.ModifierGroupID] {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Modifier group %d is listed more than once", assigned.ModifierGroupID)})
+			return
+		}
+		seen[assigned.ModifierGroupID] = true
+
+		var group models.ModifierGroup
+		if err := c.db.First(&group, assigned.ModifierGroupID).Error; err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Modifier group %d not found", assigned.ModifierGroupID)})
+			return
+		}
+
+		minSelections, maxSelections := group.MinSelections, group.MaxSelections
+		if assigned.MinSelections != nil {
+			minSelections = *assigned.MinSelections
+		}
+		if assigned.MaxSelections != nil {
+			maxSelections = *assigned.MaxSelections
+		}
+		if err := checkSelectionLimits(minSelections, maxSelections); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s: %v", group.Name, err)})
+			return
+		}
+
+		links = append(links, models.ProductModifierGroup{
+			ProductID:       product.ID,
+			ModifierGroupID: group.ID,
+			SortOrder:       i,
+			MinSelections:   assigned.MinSelections,
+			MaxSelections:   assigned.MaxSelections,
+		})
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductModifierGroup{}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product modifier groups"})
+		return
+	}
+	if len(links) > 0 {
+		if err := tx.Omit("ModifierGroup").Create(&links).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product modifier groups"})
+			return
+		}
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Product modifier groups updated successfully"})
+}
+
+// Validate checks the addons picked for a cart item against the product's
+// modifier groups. Rule violations are reported in the response rather than
+// as an error status, so callers can show every problem at once.
+func (c *ModifierGroupController) Validate(ctx *gin.Context) {
+	var input dto.ValidateModifiersDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	selections := make([]catalog.Selection, len(input.Addons))
+	for i, addon := range input.Addons {
+		selections[i] = catalog.Selection{AddonID: addon.AddonID, Quantity: addon.Quantity}
+	}
+
+	validation, err := catalog.ValidateModifiers(c.db, input.ProductID, selections)
+	if err != nil {
+		if errors.Is(err, catalog.ErrProductNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate modifiers"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, validation)
+}
+
+// Helper functions
+func checkSelectionLimits(minSelections, maxSelections int) error {
+	if maxSelections > 0 && maxSelections < minSelections {
+		return errors.New("maxSelections must be 0 (no limit) or at least minSelections")
+	}
+	return nil
+}
+
+func findAddons(db *gorm.DB, ids []uint) ([]models.ProductAddon, error) {
+	var addons []models.ProductAddon
+	if err := db.Where("id IN ?", ids).Find(&addons).Error; err != nil {
+		return nil, err
+	}
+	if len(addons) != len(uniqueIDs(ids)) {
+		return nil, errors.New("one or more addons not found")
+	}
+	return addons, nil
+}
+
+func uniqueIDs(ids []uint) map[uint]bool {
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	return unique
+}
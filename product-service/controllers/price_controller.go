@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"gorm.io/gorm"
+)
+
+type PriceController struct {
+	db *gorm.DB
+}
+
+func NewPriceController(db *gorm.DB) *PriceController {
+	return &PriceController{db: db}
+}
+
+func (c *PriceController) CreateSchedule(ctx *gin.Context) {
+	var input dto.CreatePriceScheduleDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := models.PriceSchedule{
+		ItemType:  input.ItemType,
+		ItemID:    input.ItemID,
+		Name:      input.Name,
+		Price:     input.Price,
+		StartsAt:  input.StartsAt,
+		EndsAt:    input.EndsAt,
+		CreatedBy: currentUserID(ctx),
+	}
+	if err := pricing.CheckSchedule(c.db, &schedule, 0); err != nil {
+		ctx.JSON(priceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.db.Create(&schedule).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create price schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Price schedule created successfully", "id": schedule.ID})
+}
+
+func (c *PriceController) UpdateSchedule(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdatePriceScheduleDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var schedule models.PriceSchedule
+	if err := c.db.First(&schedule, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Price schedule not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Price > 0 {
+		updates["price"] = input.Price
+	}
+	if input.StartsAt != nil {
+		schedule.StartsAt = *input.StartsAt
+		updates["starts_at"] = schedule.StartsAt
+	}
+	if input.EndsAt != nil {
+		schedule.EndsAt = input.EndsAt
+		updates["ends_at"] = schedule.EndsAt
+	}
+
+	if err := pricing.CheckSchedule(c.db, &schedule, schedule.ID); err != nil {
+		ctx.JSON(priceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.db.Model(&schedule).Updates(updates).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update price schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Price schedule updated successfully"})
+}
+
+func (c *PriceController) DeleteSchedule(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if err := c.db.Delete(&models.PriceSchedule{}, id).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete price schedule"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Price schedule deleted successfully"})
+}
+
+func (c *PriceController) GetSchedule(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var schedule models.PriceSchedule
+	if err := c.db.First(&schedule, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Price schedule not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (c *PriceController) ListSchedules(ctx *gin.Context) {
+	var params dto.PriceScheduleQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Model(&models.PriceSchedule{})
+	if params.ItemType != "" {
+		query = query.Where("item_type = ?", params.ItemType)
+	}
+	if params.ItemID != nil {
+		query = query.Where("item_id = ?", *params.ItemID)
+	}
+
+	now := time.Now()
+	switch params.Status {
+	case "active":
+		query = query.Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", now, now)
+	case "upcoming":
+		query = query.Where("starts_at > ?", now)
+	case "expired":
+		query = query.Where("ends_at <= ?", now)
+	}
+
+	var schedules []models.PriceSchedule
+	if err := query.Order("starts_at desc, id desc").Find(&schedules).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price schedules"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+// History lists changes to regular prices, newest first.
+func (c *PriceController) History(ctx *gin.Context) {
+	var params dto.PriceHistoryQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Model(&models.PriceHistory{})
+	if params.ItemType != "" {
+		query = query.Where("item_type = ?", params.ItemType)
+	}
+	if params.ItemID != nil {
+		query = query.Where("item_id = ?", *params.ItemID)
+	}
+	if params.From != "" {
+		from, err := time.Parse(dateLayout, params.From)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if params.To != "" {
+		to, err := time.Parse(dateLayout, params.To)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count price changes"})
+		return
+	}
+
+	var changes []models.PriceHistory
+	if err := query.Order("created_at desc, id desc").
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize).
+		Find(&changes).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch price history"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.PriceHistoryListResponse{
+		Changes:     changes,
+		TotalCount:  total,
+		CurrentPage: params.Page,
+		PageSize:    params.PageSize,
+	})
+}
+
+// Effective returns what a single item sells for now.
+func (c *PriceController) Effective(ctx *gin.Context) {
+	var input dto.PriceItemDTO
+	if err := ctx.ShouldBindQuery(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	prices, err := pricing.Resolve(c.db, []pricing.Item{{ItemType: input.ItemType, ItemID: input.ItemID}}, time.Now())
+	if err != nil {
+		ctx.JSON(priceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, prices[0])
+}
+
+// Resolve returns what items sell for at a time, with any scheduled price
+// applied. order-service uses it to price carts.
+func (c *PriceController) Resolve(ctx *gin.Context) {
+	var input dto.ResolvePricesDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at := time.Now()
+	if input.At != nil {
+		at = *input.At
+	}
+
+	items := make([]pricing.Item, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = pricing.Item{ItemType: item.ItemType, ItemID: item.ItemID}
+	}
+
+	prices, err := pricing.Resolve(c.db, items, at)
+	if err != nil {
+		ctx.JSON(priceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"prices": prices})
+}
+
+// Helper functions
+func priceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, pricing.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, pricing.ErrOverlap):
+		return http.StatusConflict
+	case errors.Is(err, pricing.ErrInvalidItemType),
+		errors.Is(err, pricing.ErrInvalidPeriod):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
 	"github.com/ridhotamma/yourkasa/product-service/models"
 	"gorm.io/gorm"
 )
@@ -31,22 +32,54 @@ func (c *AddonController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !validBarCode(input.BarCode) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+		return
+	}
+
+	if input.TaxClassID != nil && !taxClassExists(c.db, *input.TaxClassID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+		return
+	}
+
 	addon := models.ProductAddon{
 		Name:        input.Name,
 		Description: input.Description,
 		Price:       input.Price,
 		SKU:         input.SKU,
+		BarCode:     input.BarCode,
 		ImageURL:    input.ImageURL,
-		Stock:       input.Stock,
 		IsRequired:  input.IsRequired,
 		MaxQuantity: input.MaxQuantity,
+		TaxClassID:  input.TaxClassID,
 	}
 
-	if err := c.db.Create(&addon).Error; err != nil {
+	tx := c.db.Begin()
+	if err := tx.Create(&addon).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create addon"})
 		return
 	}
 
+	// Record opening stock in the ledger
+	if input.Stock > 0 {
+		if _, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     models.ItemTypeAddon,
+			ItemID:       addon.ID,
+			MovementType: models.MovementAdjustment,
+			Quantity:     input.Stock,
+			Reason:       "Opening stock",
+			UnitCost:     &input.CostPrice,
+			UserID:       currentUserID(ctx),
+		}); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record opening stock"})
+			return
+		}
+	}
+
+	tx.Commit()
+
 	ctx.JSON(http.StatusCreated, gin.H{"message": "Addon created successfully", "id": addon.ID})
 }
 
@@ -71,24 +104,52 @@ func (c *AddonController) Update(ctx *gin.Context) {
 	if input.Description != "" {
 		updates["description"] = input.Description
 	}
+	if input.BarCode != "" {
+		if !validBarCode(input.BarCode) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+			return
+		}
+		updates["bar_code"] = input.BarCode
+	}
 	if input.Price > 0 {
 		updates["price"] = input.Price
 	}
-	if input.Stock >= 0 {
-		updates["stock"] = input.Stock
-	}
 	if input.IsRequired != nil {
 		updates["is_required"] = *input.IsRequired
 	}
 	if input.MaxQuantity != nil {
 		updates["max_quantity"] = *input.MaxQuantity
 	}
+	if input.TaxClassID != nil {
+		if *input.TaxClassID == 0 {
+			updates["tax_class_id"] = nil
+		} else if !taxClassExists(c.db, *input.TaxClassID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+			return
+		} else {
+			updates["tax_class_id"] = *input.TaxClassID
+		}
+	}
 
-	if err := c.db.Model(&addon).Updates(updates).Error; err != nil {
+	tx := c.db.Begin()
+	if err := tx.Model(&addon).Updates(updates).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update addon"})
 		return
 	}
 
+	// Stock is never overwritten; a new count is posted as an adjustment
+	if input.Stock != nil {
+		if _, err := inventory.SetCount(tx, models.ItemTypeAddon, addon.ID, *input.Stock,
+			"Stock updated on addon", "", currentUserID(ctx)); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust stock"})
+			return
+		}
+	}
+
+	tx.Commit()
+
 	ctx.JSON(http.StatusOK, gin.H{"message": "Addon updated successfully"})
 }
 
@@ -105,7 +166,7 @@ func (c *AddonController) Delete(ctx *gin.Context) {
 func (c *AddonController) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 	var addon models.ProductAddon
-	if err := c.db.Preload("Products").First(&addon, id).Error; err != nil {
+	if err := c.db.Preload("Products").Preload("TaxClass").First(&addon, id).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Addon not found"})
 		return
 	}
@@ -117,6 +178,7 @@ func (c *AddonController) GetByProductID(ctx *gin.Context) {
 	productID := ctx.Param("productId")
 	var addons []models.ProductAddon
 	if err := c.db.Joins("JOIN product_addon_mappings ON product_addon_mappings.addon_id = product_addons.id").
+		Preload("TaxClass").
 		Where("product_addon_mappings.product_id = ?", productID).
 		Find(&addons).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch addons"})
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
 	"github.com/ridhotamma/yourkasa/product-service/dto"
 	"github.com/ridhotamma/yourkasa/product-service/models"
 	"gorm.io/gorm"
@@ -37,15 +40,23 @@ func (c *CategoryController) Create(ctx *gin.Context) {
 		Description: input.Description,
 		ImageURL:    input.ImageURL,
 		ParentID:    input.ParentID,
-		Level:       input.Level,
 		IsActive:    input.IsActive,
 		SortOrder:   input.SortOrder,
 	}
 
-	if err := c.db.Create(&category).Error; err != nil {
+	// Level and path follow from the parent
+	tx := c.db.Begin()
+	if err := tx.Create(&category).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
 		return
 	}
+	if err := catalog.PlaceCategory(tx, &category); err != nil {
+		tx.Rollback()
+		ctx.JSON(categoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
 
 	ctx.JSON(http.StatusCreated, gin.H{"message": "Category created successfully", "id": category.ID})
 }
@@ -73,9 +84,8 @@ func (c *CategoryController) Update(ctx *gin.Context) {
 	}
 	if input.ImageURL != "" {
 		updates["image_url"] = input.ImageURL
-	}
-	if input.ParentID != nil {
-		updates["parent_id"] = input.ParentID
+		// Links set by hand have no thumbnail
+		updates["thumbnail_url"] = ""
 	}
 	if input.IsActive != nil {
 		updates["is_active"] = *input.IsActive
@@ -84,11 +94,30 @@ func (c *CategoryController) Update(ctx *gin.Context) {
 		updates["sort_order"] = *input.SortOrder
 	}
 
-	if err := c.db.Model(&category).Updates(updates).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
-		return
+	tx := c.db.Begin()
+
+	// Re-parenting moves the whole subtree
+	if input.ParentID != nil {
+		var parentID *uint
+		if *input.ParentID != 0 {
+			parentID = input.ParentID
+		}
+		if err := catalog.MoveCategory(tx, &category, parentID); err != nil {
+			tx.Rollback()
+			ctx.JSON(categoryErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&category).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+			return
+		}
 	}
 
+	tx.Commit()
 	ctx.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
 }
 
@@ -107,6 +136,17 @@ func (c *CategoryController) Delete(ctx *gin.Context) {
 		return
 	}
 
+	var childrenCount int64
+	if err := c.db.Model(&models.ProductCategory{}).Where("parent_id = ?", id).Count(&childrenCount).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category usage"})
+		return
+	}
+
+	if childrenCount > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete category with subcategories"})
+		return
+	}
+
 	if err := c.db.Delete(&models.ProductCategory{}, id).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
 		return
@@ -128,10 +168,154 @@ func (c *CategoryController) GetByID(ctx *gin.Context) {
 
 func (c *CategoryController) List(ctx *gin.Context) {
 	var categories []models.ProductCategory
-	if err := c.db.Preload("Parent").Find(&categories).Error; err != nil {
+	if err := c.db.Preload("Parent").Order("path asc").Find(&categories).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, categories)
 }
+
+// Tree returns the categories nested under their parents, siblings ordered by
+// sort order, with product counts per category and per subtree.
+func (c *CategoryController) Tree(ctx *gin.Context) {
+	var params dto.CategoryTreeQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Order("sort_order asc, name asc")
+	if !params.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var categories []models.ProductCategory
+	if err := query.Find(&categories).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+
+	var counts []struct {
+		CategoryID uint
+		Count      int
+	}
+	if err := c.db.Model(&models.Product{}).
+		Select("category_id, count(*) AS count").
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
+	productCounts := make(map[uint]int, len(counts))
+	for _, count := range counts {
+		productCounts[count.CategoryID] = count.Count
+	}
+
+	children := map[uint][]models.ProductCategory{}
+	included := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		included[category.ID] = true
+	}
+	var roots []models.ProductCategory
+	for _, category := range categories {
+		// Children of a hidden parent are hidden with it
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else if included[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, buildCategoryTree(roots, children, productCounts))
+}
+
+// Reorder applies a drag and drop: the given categories are placed under the
+// parent in the given order, moving any that came from another parent.
+func (c *CategoryController) Reorder(ctx *gin.Context) {
+	var input dto.ReorderCategoriesDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var categories []models.ProductCategory
+	if err := c.db.Where("id IN ?", input.CategoryIDs).Find(&categories).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+	byID := make(map[uint]*models.ProductCategory, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+
+	tx := c.db.Begin()
+	for position, id := range input.CategoryIDs {
+		category, ok := byID[id]
+		if !ok {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Category %d not found", id)})
+			return
+		}
+
+		if !sameParent(category.ParentID, input.ParentID) {
+			if err := catalog.MoveCategory(tx, category, input.ParentID); err != nil {
+				tx.Rollback()
+				ctx.JSON(categoryErrorStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if err := tx.Model(category).Update("sort_order", position).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder categories"})
+			return
+		}
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Categories reordered successfully"})
+}
+
+// Helper functions
+func buildCategoryTree(categories []models.ProductCategory, children map[uint][]models.ProductCategory, productCounts map[uint]int) []dto.CategoryTreeDTO {
+	nodes := make([]dto.CategoryTreeDTO, 0, len(categories))
+	for _, category := range categories {
+		node := dto.CategoryTreeDTO{
+			ID:           category.ID,
+			Name:         category.Name,
+			Slug:         category.Slug,
+			ImageURL:     category.ImageURL,
+			ParentID:     category.ParentID,
+			Level:        category.Level,
+			Path:         category.Path,
+			SortOrder:    category.SortOrder,
+			IsActive:     category.IsActive,
+			ProductCount: productCounts[category.ID],
+			Children:     buildCategoryTree(children[category.ID], children, productCounts),
+		}
+
+		node.TotalProductCount = node.ProductCount
+		for _, child := range node.Children {
+			node.TotalProductCount += child.TotalProductCount
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func sameParent(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, catalog.ErrCategoryNotFound), errors.Is(err, catalog.ErrCategoryCycle):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,11 +1,20 @@
 package controllers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
 	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
 	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"github.com/ridhotamma/yourkasa/product-service/utils"
 	"gorm.io/gorm"
 )
 
@@ -43,20 +52,42 @@ func (c *ProductController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if input.TaxClassID != nil && !taxClassExists(c.db, *input.TaxClassID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+		return
+	}
+
+	if err := catalog.CheckUnits(c.db, input.StockUnitID, input.SellUnitID, input.PurchaseUnitID, input.Weighed); err != nil {
+		ctx.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validBarCode(input.BarCode) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+		return
+	}
+
 	product := models.Product{
 		Name:             input.Name,
 		ShortDescription: input.ShortDescription,
 		Description:      input.Description,
 		Price:            input.Price,
-		Stock:            input.Stock,
+		MinStock:         input.MinStock,
+		ReorderQuantity:  input.ReorderQuantity,
 		CategoryID:       input.CategoryID,
 		GroupID:          input.GroupID,
 		SKU:              input.SKU,
+		BarCode:          input.BarCode,
 		ImageURL:         input.ImageURL,
 		Weight:           input.Weight,
 		Dimensions:       input.Dimensions,
 		Tags:             input.Tags,
 		Status:           input.Status,
+		TaxClassID:       input.TaxClassID,
+		StockUnitID:      input.StockUnitID,
+		SellUnitID:       input.SellUnitID,
+		PurchaseUnitID:   input.PurchaseUnitID,
+		Weighed:          input.Weighed,
 	}
 
 	// Start transaction
@@ -67,6 +98,30 @@ func (c *ProductController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if err := pricing.RecordChange(tx, models.ItemTypeProduct, product.ID, nil, product.Price,
+		models.PriceSourceManual, currentUserID(ctx)); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record price history"})
+		return
+	}
+
+	// Record opening stock in the ledger
+	if input.Stock > 0 {
+		if _, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     models.ItemTypeProduct,
+			ItemID:       product.ID,
+			MovementType: models.MovementAdjustment,
+			Quantity:     input.Stock,
+			Reason:       "Opening stock",
+			UnitCost:     &input.CostPrice,
+			UserID:       currentUserID(ctx),
+		}); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record opening stock"})
+			return
+		}
+	}
+
 	// Associate addons if provided
 	if len(input.AddonIDs) > 0 {
 		var addons []models.ProductAddon
@@ -114,8 +169,11 @@ func (c *ProductController) Update(ctx *gin.Context) {
 	if input.Price > 0 {
 		updates["price"] = input.Price
 	}
-	if input.Stock >= 0 {
-		updates["stock"] = input.Stock
+	if input.MinStock != nil {
+		updates["min_stock"] = *input.MinStock
+	}
+	if input.ReorderQuantity != nil {
+		updates["reorder_quantity"] = *input.ReorderQuantity
 	}
 	if input.CategoryID != nil {
 		if !categoryExists(c.db, *input.CategoryID) {
@@ -124,8 +182,17 @@ func (c *ProductController) Update(ctx *gin.Context) {
 		}
 		updates["category_id"] = *input.CategoryID
 	}
+	if input.BarCode != "" {
+		if !validBarCode(input.BarCode) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+			return
+		}
+		updates["bar_code"] = input.BarCode
+	}
 	if input.ImageURL != "" {
 		updates["image_url"] = input.ImageURL
+		// Links set by hand have no thumbnail
+		updates["thumbnail_url"] = ""
 	}
 	if input.Weight > 0 {
 		updates["weight"] = input.Weight
@@ -146,7 +213,42 @@ func (c *ProductController) Update(ctx *gin.Context) {
 		}
 		updates["group_id"] = *input.GroupID
 	}
+	if input.TaxClassID != nil {
+		if *input.TaxClassID == 0 {
+			updates["tax_class_id"] = nil
+		} else if !taxClassExists(c.db, *input.TaxClassID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+			return
+		} else {
+			updates["tax_class_id"] = *input.TaxClassID
+		}
+	}
 
+	if input.StockUnitID != nil || input.SellUnitID != nil || input.PurchaseUnitID != nil || input.Weighed != nil {
+		stockUnitID := unitUpdate(product.StockUnitID, input.StockUnitID)
+		sellUnitID := unitUpdate(product.SellUnitID, input.SellUnitID)
+		purchaseUnitID := unitUpdate(product.PurchaseUnitID, input.PurchaseUnitID)
+		weighed := product.Weighed
+		if input.Weighed != nil {
+			weighed = *input.Weighed
+		}
+
+		// Stock on hand is counted in the current stock unit
+		if !sameUnit(stockUnitID, product.StockUnitID) && hasStock(c.db, product.ID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Stock unit can only change while the product and its variants have no stock"})
+			return
+		}
+		if err := catalog.CheckUnits(c.db, stockUnitID, sellUnitID, purchaseUnitID, weighed); err != nil {
+			ctx.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		updates["stock_unit_id"] = stockUnitID
+		updates["sell_unit_id"] = sellUnitID
+		updates["purchase_unit_id"] = purchaseUnitID
+		updates["weighed"] = weighed
+	}
+
+	oldPrice := product.Price
 	tx := c.db.Begin()
 
 	if err := tx.Model(&product).Updates(updates).Error; err != nil {
@@ -155,6 +257,25 @@ func (c *ProductController) Update(ctx *gin.Context) {
 		return
 	}
 
+	if input.Price > 0 {
+		if err := pricing.RecordChange(tx, models.ItemTypeProduct, product.ID, &oldPrice, input.Price,
+			models.PriceSourceManual, currentUserID(ctx)); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record price history"})
+			return
+		}
+	}
+
+	// Stock is never overwritten; a new count is posted as an adjustment
+	if input.Stock != nil {
+		if _, err := inventory.SetCount(tx, models.ItemTypeProduct, product.ID, *input.Stock,
+			"Stock updated on product", "", currentUserID(ctx)); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust stock"})
+			return
+		}
+	}
+
 	// Update addons if provided
 	if len(input.AddonIDs) > 0 {
 		if err := tx.Model(&product).Association("Addons").Clear(); err != nil {
@@ -182,50 +303,381 @@ func (c *ProductController) Update(ctx *gin.Context) {
 }
 
 func (c *ProductController) List(ctx *gin.Context) {
-	var products []models.Product
+	var params dto.ProductSearchParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	query := c.db.Model(&models.Product{})
 
 	// Apply filters
-	if category := ctx.Query("category"); category != "" {
+	if params.CategoryID != nil && params.IncludeSubcategories {
+		query = query.Where("category_id IN (?)", catalog.Subtree(c.db, *params.CategoryID))
+	} else if params.CategoryID != nil {
+		query = query.Where("category_id = ?", *params.CategoryID)
+	} else if category := ctx.Query("category"); category != "" {
 		query = query.Where("category_id = ?", category)
 	}
 
-	if status := ctx.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
+	if params.GroupID != nil {
+		query = query.Where("group_id = ?", *params.GroupID)
+	} else if group := ctx.Query("group"); group != "" {
+		query = query.Where("group_id = ?", group)
+	}
+
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
 	}
 
-	if group := ctx.Query("group"); group != "" {
-		query = query.Where("group_id = ?", group)
+	if params.MinPrice > 0 {
+		query = query.Where("price >= ?", params.MinPrice)
+	}
+	if params.MaxPrice > 0 {
+		query = query.Where("price <= ?", params.MaxPrice)
 	}
 
-	// Preload relationships
-	query = query.Preload("Category").
-		Preload("Group").
-		Preload("Variants").
-		Preload("Addons")
+	if params.InStock != nil {
+		if *params.InStock {
+			query = query.Where("stock > 0")
+		} else {
+			query = query.Where("stock <= 0")
+		}
+	}
+
+	if params.AvailableNow {
+		query = query.Scopes(catalog.AvailableProducts(params.Outlet, time.Now()))
+	}
+
+	if q := strings.TrimSpace(params.Query); q != "" {
+		like := "%" + escapeLike(q) + "%"
+		query = query.Where("name ILIKE ? OR sku ILIKE ? OR short_description ILIKE ? OR tags ILIKE ?",
+			like, like, like, like)
+	}
+
+	var totalCount int64
+	if err := query.Session(&gorm.Session{}).Count(&totalCount).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count products"})
+		return
+	}
 
-	if err := query.Find(&products).Error; err != nil {
+	var products []models.Product
+	if err := query.
+		Preload("Category", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Preload("TaxClass").
+		Order(productSortClause(params.SortBy, params.SortOrder)).
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize).
+		Find(&products).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
 
-	var productList []dto.ProductListDTO
+	variantCounts, err := countVariants(c.db, products)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count product variants"})
+		return
+	}
+
+	productIDs := make([]uint, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+	schedules, err := pricing.Scheduled(c.db, models.ItemTypeProduct, productIDs, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled prices"})
+		return
+	}
+
+	defaultTaxClass, err := pricing.DefaultTaxClass(c.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch default tax class"})
+		return
+	}
+
+	codes, err := unitCodes(c.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch units"})
+		return
+	}
+
+	productList := make([]dto.ProductListDTO, 0, len(products))
 	for _, product := range products {
 		productList = append(productList, dto.ProductListDTO{
 			ID:               product.ID,
 			Name:             product.Name,
 			ShortDescription: product.ShortDescription,
 			Price:            product.Price,
+			EffectivePrice:   scheduledPrice(schedules, product.ID, product.Price),
 			Stock:            product.Stock,
 			CategoryID:       product.CategoryID,
 			CategoryName:     product.Category.Name,
 			SKU:              product.SKU,
 			Status:           product.Status,
-			VariantCount:     len(product.Variants),
+			ImageURL:         product.ImageURL,
+			ThumbnailURL:     product.ThumbnailURL,
+			VariantCount:     variantCounts[product.ID],
+			TaxClassID:       product.TaxClassID,
+			TaxClass:         productTaxClass(product.TaxClass, defaultTaxClass),
+			Unit:             sellUnitCode(codes, product),
+			Weighed:          product.Weighed,
 		})
 	}
 
-	ctx.JSON(http.StatusOK, productList)
+	ctx.JSON(http.StatusOK, dto.ProductListResponse{
+		Products:    productList,
+		TotalCount:  totalCount,
+		PageCount:   int((totalCount + int64(params.PageSize) - 1) / int64(params.PageSize)),
+		CurrentPage: params.Page,
+		PageSize:    params.PageSize,
+	})
+}
+
+// productSearchSQL ranks products by full-text match on the generated
+// search_vector and falls back to trigram similarity so that misspelled names
+// still match. Variants are matched by name or SKU and the best one is
+// returned alongside its product.
+const productSearchSQL = `
+WITH q AS (SELECT to_tsquery('simple', @tsquery) AS tsq)
+SELECT p.id, p.name, p.sku, p.price, p.stock, p.status, p.image_url, p.category_id,
+	ts_rank_cd(p.search_vector, q.tsq) * 2
+		+ GREATEST(word_similarity(@term, p.name), COALESCE(v.similarity, 0)) AS rank,
+	ts_headline('simple',
+		p.name || ' ' || coalesce(p.short_description, '') || ' ' || coalesce(p.description, ''),
+		q.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=8, MaxFragments=2') AS snippet,
+	v.id AS variant_id, v.name AS variant_name, v.sku AS variant_sku, v.price AS variant_price
+FROM products p
+CROSS JOIN q
+LEFT JOIN LATERAL (
+	SELECT pv.id, pv.name, pv.sku, pv.price,
+		GREATEST(word_similarity(@term, pv.name), similarity(@term, pv.sku)) AS similarity
+	FROM product_variants pv
+	WHERE pv.product_id = p.id AND pv.deleted_at IS NULL
+		AND (to_tsvector('simple', pv.name) @@ q.tsq OR @term <% pv.name OR pv.sku ILIKE @prefix)
+	ORDER BY similarity DESC
+	LIMIT 1
+) v ON true
+WHERE p.deleted_at IS NULL
+	AND (p.search_vector @@ q.tsq OR @term <% p.name OR p.sku ILIKE @prefix OR v.id IS NOT NULL)
+	AND (@include_inactive OR p.status = 'active')
+	AND (CAST(@category_id AS bigint) IS NULL OR p.category_id = @category_id
+		OR (@include_subcategories AND p.category_id IN (
+			SELECT c.id FROM product_categories c
+			WHERE c.path LIKE (SELECT path FROM product_categories WHERE id = @category_id) || '%')))
+ORDER BY rank DESC, p.name ASC
+LIMIT @limit`
+
+func (c *ProductController) Search(ctx *gin.Context) {
+	var params dto.ProductSearchQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	terms := searchTerms(params.Query)
+	if len(terms) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Search query must contain letters or digits"})
+		return
+	}
+
+	prefixes := make([]string, len(terms))
+	for i, term := range terms {
+		prefixes[i] = term + ":*"
+	}
+	term := strings.Join(terms, " ")
+
+	var rows []struct {
+		ID           uint
+		Name         string
+		SKU          string
+		Price        float64
+		Stock        int
+		Status       string
+		ImageURL     string
+		CategoryID   uint
+		Rank         float64
+		Snippet      string
+		VariantID    *uint
+		VariantName  *string
+		VariantSKU   *string
+		VariantPrice *float64
+	}
+	if err := c.db.Raw(productSearchSQL, map[string]interface{}{
+		"tsquery":               strings.Join(prefixes, " & "),
+		"term":                  term,
+		"prefix":                escapeLike(term) + "%",
+		"include_inactive":      params.IncludeInactive,
+		"category_id":           params.CategoryID,
+		"include_subcategories": params.IncludeSubcategories,
+		"limit":                 params.Limit,
+	}).Scan(&rows).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search products"})
+		return
+	}
+
+	now := time.Now()
+	var productIDs, variantIDs []uint
+	for _, row := range rows {
+		productIDs = append(productIDs, row.ID)
+		if row.VariantID != nil {
+			variantIDs = append(variantIDs, *row.VariantID)
+		}
+	}
+	productSchedules, err := pricing.Scheduled(c.db, models.ItemTypeProduct, productIDs, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled prices"})
+		return
+	}
+	variantSchedules, err := pricing.Scheduled(c.db, models.ItemTypeVariant, variantIDs, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled prices"})
+		return
+	}
+
+	results := make([]dto.ProductSearchResultDTO, 0, len(rows))
+	for _, row := range rows {
+		result := dto.ProductSearchResultDTO{
+			ID:             row.ID,
+			Name:           row.Name,
+			SKU:            row.SKU,
+			Price:          row.Price,
+			EffectivePrice: scheduledPrice(productSchedules, row.ID, row.Price),
+			Stock:          row.Stock,
+			Status:         row.Status,
+			ImageURL:       row.ImageURL,
+			CategoryID:     row.CategoryID,
+			Rank:           row.Rank,
+			Snippet:        row.Snippet,
+		}
+		if row.VariantID != nil {
+			result.MatchedVariant = &dto.ProductSearchVariantDTO{
+				ID:             *row.VariantID,
+				Name:           *row.VariantName,
+				SKU:            *row.VariantSKU,
+				Price:          *row.VariantPrice,
+				EffectivePrice: scheduledPrice(variantSchedules, *row.VariantID, *row.VariantPrice),
+			}
+		}
+		results = append(results, result)
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
+
+// scanLookupSQL resolves a scanned code against variant, product and addon
+// barcodes and SKUs in one round trip. bestScanMatch picks among the rows.
+const scanLookupSQL = `
+SELECT * FROM (
+	SELECT 'variant' AS type, 1 AS priority, v.id, v.product_id, v.name, p.name AS product_name,
+		v.sku, v.bar_code, v.status, v.stock, v.image_url, v.price
+	FROM product_variants v
+	JOIN products p ON p.id = v.product_id AND p.deleted_at IS NULL
+	WHERE v.deleted_at IS NULL AND (v.bar_code IN @codes OR v.sku IN @codes)
+	UNION ALL
+	SELECT 'product', 2, p.id, p.id, p.name, p.name,
+		p.sku, p.bar_code, p.status, p.stock, p.image_url, p.price
+	FROM products p
+	WHERE p.deleted_at IS NULL AND (p.bar_code IN @codes OR p.sku IN @codes)
+	UNION ALL
+	SELECT 'addon', 3, a.id, NULL, a.name, '',
+		a.sku, a.bar_code, a.status, a.stock, a.image_url, a.price
+	FROM product_addons a
+	WHERE a.deleted_at IS NULL AND (a.bar_code IN @codes OR a.sku IN @codes)
+) matches
+ORDER BY priority`
+
+type scanMatch struct {
+	Type        string
+	Priority    int
+	ID          uint
+	ProductID   *uint
+	Name        string
+	ProductName string
+	SKU         string
+	BarCode     string
+	Status      string
+	Stock       int
+	ImageURL    string
+	Price       float64
+}
+
+func (c *ProductController) Lookup(ctx *gin.Context) {
+	code := strings.TrimSpace(ctx.Query("code"))
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	// A bad check digit still matches a numeric SKU or custom barcode exactly
+	scanned, _ := utils.ParseScannedCode(code)
+
+	var matches []scanMatch
+	if err := c.db.Raw(scanLookupSQL, map[string]interface{}{"codes": scanned.Candidates}).Scan(&matches).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up code"})
+		return
+	}
+	if len(matches) == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No product found for code"})
+		return
+	}
+	match := bestScanMatch(matches, scanned.Candidates)
+
+	// Addons have no price schedules
+	if match.Type != "addon" {
+		price, err := pricing.Effective(c.db, match.Type, match.ID, match.Price, time.Now())
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled price"})
+			return
+		}
+		match.Price = price.Price
+	}
+
+	// A label printed for this exact code is a fixed price item, even when
+	// the code falls in the in-store weighted range
+	if match.BarCode != "" && (match.BarCode == scanned.Candidates[0] || match.BarCode == "0"+scanned.Raw) {
+		scanned.Weighted, scanned.Price, scanned.WeightKg = false, nil, nil
+	}
+
+	lookup := dto.ScanLookupDTO{
+		Code:           code,
+		Type:           match.Type,
+		ID:             match.ID,
+		ProductID:      match.ProductID,
+		Name:           match.Name,
+		ProductName:    match.ProductName,
+		SKU:            match.SKU,
+		BarCode:        match.BarCode,
+		Status:         match.Status,
+		Stock:          match.Stock,
+		ImageURL:       match.ImageURL,
+		UnitPrice:      match.Price,
+		Quantity:       1,
+		EffectivePrice: match.Price,
+		Weighted:       scanned.Weighted,
+		EmbeddedPrice:  scanned.Price,
+		WeightKg:       scanned.WeightKg,
+	}
+
+	// Weighted items are priced per sell unit, kg unless the product sells
+	// in another weight unit; derive whichever of weight or price the label
+	// did not carry
+	switch {
+	case scanned.WeightKg != nil:
+		quantity, err := c.weightInSellUnit(match.Type, match.ID, *scanned.WeightKg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product units"})
+			return
+		}
+		lookup.Quantity = quantity
+		lookup.EffectivePrice = roundPrice(match.Price * quantity)
+	case scanned.Price != nil:
+		lookup.EffectivePrice = *scanned.Price
+		if match.Price > 0 {
+			lookup.Quantity = roundQuantity(*scanned.Price / match.Price)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, lookup)
 }
 
 func (c *ProductController) GetByID(ctx *gin.Context) {
@@ -235,36 +687,101 @@ func (c *ProductController) GetByID(ctx *gin.Context) {
 	if err := c.db.Preload("Category").
 		Preload("Group").
 		Preload("Variants").
-		Preload("Addons").
+		Preload("Addons.TaxClass").
+		Preload("Images", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order asc, id asc") }).
+		Preload("TaxClass").
 		First(&product, id).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
 
+	modifierGroups, err := catalog.ProductModifierGroups(c.db, product.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch modifier groups"})
+		return
+	}
+
+	options, err := catalog.ProductOptions(c.db, product.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product options"})
+		return
+	}
+
+	now := time.Now()
+	productSchedules, err := pricing.Scheduled(c.db, models.ItemTypeProduct, []uint{product.ID}, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled prices"})
+		return
+	}
+	variantIDs := make([]uint, len(product.Variants))
+	for i, variant := range product.Variants {
+		variantIDs[i] = variant.ID
+	}
+	variantSchedules, err := pricing.Scheduled(c.db, models.ItemTypeVariant, variantIDs, now)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch scheduled prices"})
+		return
+	}
+
+	defaultTaxClass, err := pricing.DefaultTaxClass(c.db)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch default tax class"})
+		return
+	}
+
+	units, err := catalog.ProductUnits(c.db, &product)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product units"})
+		return
+	}
+
+	variants := make([]dto.PricedVariantDTO, len(product.Variants))
+	for i, variant := range product.Variants {
+		variants[i] = dto.PricedVariantDTO{
+			ProductVariant: variant,
+			EffectivePrice: scheduledPrice(variantSchedules, variant.ID, variant.Price),
+		}
+	}
+
 	productDetail := dto.ProductDetailDTO{
 		ID:               product.ID,
 		Name:             product.Name,
 		ShortDescription: product.ShortDescription,
 		Description:      product.Description,
 		Price:            product.Price,
+		EffectivePrice:   scheduledPrice(productSchedules, product.ID, product.Price),
 		Stock:            product.Stock,
+		MinStock:         product.MinStock,
+		ReorderQuantity:  product.ReorderQuantity,
 		CategoryID:       product.CategoryID,
 		Category:         product.Category,
 		GroupID:          product.GroupID,
 		Group:            product.Group,
 		SKU:              product.SKU,
+		BarCode:          product.BarCode,
 		ImageURL:         product.ImageURL,
+		ThumbnailURL:     product.ThumbnailURL,
+		Images:           product.Images,
 		Weight:           product.Weight,
 		Dimensions:       product.Dimensions,
 		Tags:             product.Tags,
 		Status:           product.Status,
-		Variants:         product.Variants,
+		Options:          options,
+		Variants:         variants,
 		Addons:           product.Addons,
+		ModifierGroups:   modifierGroups,
+		TaxClassID:       product.TaxClassID,
+		TaxClass:         productTaxClass(product.TaxClass, defaultTaxClass),
+		Units:            units,
 		CreatedAt:        product.CreatedAt,
 		UpdatedAt:        product.UpdatedAt,
 		LastSoldAt:       product.LastSoldAt,
 	}
 
+	if schedule, ok := productSchedules[product.ID]; ok {
+		productDetail.PriceSchedule = &schedule
+	}
+
 	ctx.JSON(http.StatusOK, productDetail)
 }
 
@@ -299,6 +816,103 @@ func (c *ProductController) Delete(ctx *gin.Context) {
 }
 
 // Helper functions
+func scheduledPrice(schedules map[uint]models.PriceSchedule, id uint, regularPrice float64) float64 {
+	if schedule, ok := schedules[id]; ok {
+		return schedule.Price
+	}
+	return regularPrice
+}
+
+// weightInSellUnit converts a label weight in kg to the item's sell unit when
+// that unit measures weight.
+func (c *ProductController) weightInSellUnit(itemType string, itemID uint, weightKg float64) (float64, error) {
+	if itemType == models.ItemTypeAddon {
+		return weightKg, nil
+	}
+	units, err := catalog.UnitsOf(c.db, itemType, itemID)
+	if err != nil {
+		return 0, err
+	}
+	if units.SellUnit == nil || units.SellUnit.Dimension != models.DimensionWeight {
+		return weightKg, nil
+	}
+	// Weight factors are in grams
+	return roundQuantity(weightKg * 1000 / units.SellUnit.Factor), nil
+}
+
+// unitUpdate applies a unit ID from an update request, where 0 clears it.
+func unitUpdate(current, requested *uint) *uint {
+	if requested == nil {
+		return current
+	}
+	if *requested == 0 {
+		return nil
+	}
+	return requested
+}
+
+func unitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, catalog.ErrUnitItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, catalog.ErrUnitNotFound),
+		errors.Is(err, catalog.ErrUnitMismatch),
+		errors.Is(err, catalog.ErrUnitFactor),
+		errors.Is(err, catalog.ErrWeighedUnit):
+		return http.StatusBadRequest
+	case errors.Is(err, catalog.ErrFractionalQuantity):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func sameUnit(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func hasStock(db *gorm.DB, productID uint) bool {
+	var exists bool
+	db.Raw(`SELECT EXISTS (SELECT 1 FROM products WHERE id = @id AND stock <> 0)
+		OR EXISTS (SELECT 1 FROM product_variants WHERE product_id = @id AND stock <> 0 AND deleted_at IS NULL)`,
+		map[string]interface{}{"id": productID}).Scan(&exists)
+	return exists
+}
+
+func unitCodes(db *gorm.DB) (map[uint]string, error) {
+	var units []models.UnitOfMeasure
+	if err := db.Select("id, code").Find(&units).Error; err != nil {
+		return nil, err
+	}
+	codes := make(map[uint]string, len(units))
+	for _, unit := range units {
+		codes[unit.ID] = unit.Code
+	}
+	return codes, nil
+}
+
+// sellUnitCode is the code of the unit a product's price is per.
+func sellUnitCode(codes map[uint]string, product models.Product) string {
+	switch {
+	case product.SellUnitID != nil:
+		return codes[*product.SellUnitID]
+	case product.StockUnitID != nil:
+		return codes[*product.StockUnitID]
+	default:
+		return "pcs"
+	}
+}
+
+func productTaxClass(own, fallback *models.TaxClass) *models.TaxClass {
+	if own != nil {
+		return own
+	}
+	return fallback
+}
+
 func groupExists(db *gorm.DB, id uint) bool {
 	var exists bool
 	db.Model(&models.ProductGroup{}).Select("count(*) > 0").Where("id = ?", id).Find(&exists)
@@ -310,3 +924,122 @@ func categoryExists(db *gorm.DB, id uint) bool {
 	db.Model(&models.ProductCategory{}).Select("count(*) > 0").Where("id = ?", id).Find(&exists)
 	return exists
 }
+
+// productSortClause maps the public sortBy/sortOrder values onto an ORDER BY
+// clause. Unknown values never reach SQL; they fall back to newest first.
+func productSortClause(sortBy, sortOrder string) string {
+	columns := map[string]string{
+		"name":     "name",
+		"price":    "price",
+		"stock":    "stock",
+		"created":  "created_at",
+		"lastSold": "last_sold_at",
+	}
+
+	column, ok := columns[sortBy]
+	if !ok {
+		return "created_at desc, id desc"
+	}
+
+	direction := "asc"
+	if sortOrder == "desc" {
+		direction = "desc"
+	}
+
+	clause := column + " " + direction
+	if column == "last_sold_at" {
+		clause += " nulls last"
+	}
+	return clause + ", id " + direction
+}
+
+func countVariants(db *gorm.DB, products []models.Product) (map[uint]int, error) {
+	counts := map[uint]int{}
+	if len(products) == 0 {
+		return counts, nil
+	}
+
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	var rows []struct {
+		ProductID uint
+		Count     int
+	}
+	if err := db.Model(&models.ProductVariant{}).
+		Select("product_id, count(*) as count").
+		Where("product_id IN ?", ids).
+		Group("product_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ProductID] = row.Count
+	}
+	return counts, nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// searchTerms splits free text into lower-cased words made of letters and
+// digits, which are safe to embed in a tsquery expression.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// bestScanMatch prefers the match on the earliest candidate, so an exact hit
+// on the raw code beats one on a decoded weighted item code, then variants
+// over products over addons.
+func bestScanMatch(matches []scanMatch, candidates []string) scanMatch {
+	rank := func(m scanMatch) int {
+		for i, code := range candidates {
+			if m.BarCode == code || m.SKU == code {
+				return i
+			}
+		}
+		return len(candidates)
+	}
+
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if r, b := rank(m), rank(best); r < b || (r == b && m.Priority < best.Priority) {
+			best = m
+		}
+	}
+	return best
+}
+
+// validBarCode rejects numeric EAN-13/UPC-A codes with a wrong check digit.
+// Empty and non-numeric codes are custom barcodes and always accepted.
+func validBarCode(code string) bool {
+	_, err := utils.ParseScannedCode(code)
+	return err == nil
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// currentUserID returns the authenticated user set by AuthMiddleware.
+func currentUserID(ctx *gin.Context) *uint {
+	userID, ok := ctx.Get("userId")
+	if !ok {
+		return nil
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		return nil
+	}
+	return &id
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import "testing"
+
+func TestBestScanMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		matches    []scanMatch
+		candidates []string
+		want       uint
+	}{
+		{
+			name: "variant over product over addon",
+			matches: []scanMatch{
+				{Type: "variant", Priority: 1, ID: 1, SKU: "A1"},
+				{Type: "product", Priority: 2, ID: 2, BarCode: "A1"},
+				{Type: "addon", Priority: 3, ID: 3, SKU: "A1"},
+			},
+			candidates: []string{"A1"},
+			want:       1,
+		},
+		{
+			name: "exact raw match beats a decoded item code",
+			matches: []scanMatch{
+				{Type: "variant", Priority: 1, ID: 1, SKU: "00123"},
+				{Type: "product", Priority: 2, ID: 2, BarCode: "2500123012501"},
+			},
+			candidates: []string{"2500123012501", "2500123", "00123"},
+			want:       2,
+		},
+		{
+			name: "raw code beats the padded UPC-A",
+			matches: []scanMatch{
+				{Type: "product", Priority: 2, ID: 2, BarCode: "0036000291452"},
+				{Type: "addon", Priority: 3, ID: 3, SKU: "036000291452"},
+			},
+			candidates: []string{"036000291452", "0036000291452"},
+			want:       3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bestScanMatch(tt.matches, tt.candidates); got.ID != tt.want {
+				t.Errorf("bestScanMatch() = %s %d, want id %d", got.Type, got.ID, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
 	"github.com/ridhotamma/yourkasa/product-service/dto"
 	"github.com/ridhotamma/yourkasa/product-service/models"
 	"gorm.io/gorm"
@@ -24,6 +27,11 @@ func (c *GroupController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if input.TaxClassID != nil && !taxClassExists(c.db, *input.TaxClassID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+		return
+	}
+
 	group := models.ProductGroup{
 		Name:        input.Name,
 		Description: input.Description,
@@ -33,6 +41,10 @@ func (c *GroupController) Create(ctx *gin.Context) {
 		EndDate:     input.EndDate,
 		GroupType:   input.GroupType,
 		SortOrder:   input.SortOrder,
+
+		BundlePrice:     input.BundlePrice,
+		DiscountPercent: input.DiscountPercent,
+		TaxClassID:      input.TaxClassID,
 	}
 
 	if err := c.db.Create(&group).Error; err != nil {
@@ -66,6 +78,8 @@ func (c *GroupController) Update(ctx *gin.Context) {
 	}
 	if input.ImageURL != "" {
 		updates["image_url"] = input.ImageURL
+		// Links set by hand have no thumbnail
+		updates["thumbnail_url"] = ""
 	}
 	if input.IsActive != nil {
 		updates["is_active"] = *input.IsActive
@@ -82,6 +96,26 @@ func (c *GroupController) Update(ctx *gin.Context) {
 	if input.SortOrder != nil {
 		updates["sort_order"] = *input.SortOrder
 	}
+	if input.BundlePrice != nil {
+		if *input.BundlePrice > 0 {
+			updates["bundle_price"] = *input.BundlePrice
+		} else {
+			updates["bundle_price"] = nil
+		}
+	}
+	if input.DiscountPercent != nil {
+		updates["discount_percent"] = *input.DiscountPercent
+	}
+	if input.TaxClassID != nil {
+		if *input.TaxClassID == 0 {
+			updates["tax_class_id"] = nil
+		} else if !taxClassExists(c.db, *input.TaxClassID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+			return
+		} else {
+			updates["tax_class_id"] = *input.TaxClassID
+		}
+	}
 
 	if err := c.db.Model(&group).Updates(updates).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
@@ -106,10 +140,29 @@ func (c *GroupController) Delete(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.db.Delete(&models.ProductGroup{}, id).Error; err != nil {
+	var group models.ProductGroup
+	if err := c.db.First(&group, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := catalog.ReplaceComponents(tx, group.ID, nil); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bundle components"})
+		return
+	}
+	if err := catalog.ReplaceRules(tx, models.ItemTypeGroup, group.ID, nil); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete availability rules"})
+		return
+	}
+	if err := tx.Delete(&group).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
 		return
 	}
+	tx.Commit()
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
 }
@@ -141,7 +194,11 @@ func (c *GroupController) List(ctx *gin.Context) {
 		query = query.Where("group_type = ?", groupType)
 	}
 
-	// Filter by date range
+	// Keep groups that are active, within their dates and availability rules
+	if ctx.Query("availableNow") == "true" {
+		query = query.Scopes(catalog.AvailableGroups(ctx.Query("outlet"), time.Now()))
+	}
+
 	if err := query.Preload("Products").Order("sort_order asc").Find(&groups).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
 		return
@@ -206,3 +263,113 @@ func (c *GroupController) RemoveProductFromGroup(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Product removed from group successfully"})
 }
+
+// SetComponents replaces the products and variants that make up a bundle,
+// along with the alternatives customers may swap each one for.
+func (c *GroupController) SetComponents(ctx *gin.Context) {
+	var input dto.SetBundleComponentsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var group models.ProductGroup
+	if err := c.db.First(&group, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+	if group.GroupType != models.GroupTypeBundle {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only bundle groups have components"})
+		return
+	}
+
+	components := make([]models.BundleComponent, len(input.Components))
+	for i, component := range input.Components {
+		components[i] = models.BundleComponent{
+			ItemType: component.ItemType,
+			ItemID:   component.ItemID,
+			Quantity: component.Quantity,
+		}
+		for _, choice := range component.Choices {
+			components[i].Choices = append(components[i].Choices, models.BundleChoice{
+				ItemType:        choice.ItemType,
+				ItemID:          choice.ItemID,
+				PriceAdjustment: choice.PriceAdjustment,
+			})
+		}
+	}
+
+	tx := c.db.Begin()
+	if err := catalog.ReplaceComponents(tx, group.ID, components); err != nil {
+		tx.Rollback()
+		if errors.Is(err, catalog.ErrBundleItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bundle components"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Bundle components updated successfully"})
+}
+
+// GetBundle returns a bundle with its components and choices, priced with the
+// default components and with the stock available to make it.
+func (c *GroupController) GetBundle(ctx *gin.Context) {
+	var group models.ProductGroup
+	if err := c.db.Preload("Components", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order asc, id asc")
+	}).Preload("Components.Choices").First(&group, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		return
+	}
+
+	quote, err := catalog.QuoteBundle(c.db, group.ID, nil)
+	if err != nil {
+		ctx.JSON(bundleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.BundleDetailDTO{Bundle: group, Quote: *quote})
+}
+
+// Quote prices a bundle with the customer's swaps. order-service calls it when
+// a bundle is added to a cart and sells the returned lines as the components.
+func (c *GroupController) Quote(ctx *gin.Context) {
+	var input dto.QuoteBundleDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	swaps := make(map[uint]uint, len(input.Swaps))
+	for _, swap := range input.Swaps {
+		swaps[swap.ComponentID] = swap.ChoiceID
+	}
+
+	quote, err := catalog.QuoteBundle(c.db, input.GroupID, swaps)
+	if err != nil {
+		ctx.JSON(bundleErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+// Helper functions
+func bundleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, catalog.ErrBundleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, catalog.ErrNotBundle),
+		errors.Is(err, catalog.ErrInvalidSwap):
+		return http.StatusBadRequest
+	case errors.Is(err, catalog.ErrBundleUnavailable),
+		errors.Is(err, catalog.ErrBundleEmpty),
+		errors.Is(err, catalog.ErrBundleItem):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
 	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
 	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
 	"gorm.io/gorm"
 )
 
@@ -38,25 +43,66 @@ func (c *VariantController) Create(ctx *gin.Context) {
 		return
 	}
 
+	if !validBarCode(input.BarCode) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+		return
+	}
+
+	attributeKey, err := catalog.CheckVariantAttributes(c.db, input.ProductID, 0, input.Attributes)
+	if err != nil {
+		variantAttributesError(ctx, err)
+		return
+	}
+
 	variant := models.ProductVariant{
-		ProductID:  input.ProductID,
-		Name:       input.Name,
-		SKU:        input.SKU,
-		Price:      input.Price,
-		Stock:      input.Stock,
-		ImageURL:   input.ImageURL,
-		Attributes: input.Attributes,
-		IsDefault:  input.IsDefault,
-		Weight:     input.Weight,
-		Dimensions: input.Dimensions,
-		BarCode:    input.BarCode,
-	}
-
-	if err := c.db.Create(&variant).Error; err != nil {
+		ProductID:       input.ProductID,
+		Name:            input.Name,
+		SKU:             input.SKU,
+		Price:           input.Price,
+		MinStock:        input.MinStock,
+		ReorderQuantity: input.ReorderQuantity,
+		ImageURL:        input.ImageURL,
+		Attributes:      input.Attributes,
+		AttributeKey:    attributeKey,
+		IsDefault:       input.IsDefault,
+		Weight:          input.Weight,
+		Dimensions:      input.Dimensions,
+		BarCode:         input.BarCode,
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Create(&variant).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create variant"})
 		return
 	}
 
+	if err := pricing.RecordChange(tx, models.ItemTypeVariant, variant.ID, nil, variant.Price,
+		models.PriceSourceManual, currentUserID(ctx)); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record price history"})
+		return
+	}
+
+	// Record opening stock in the ledger
+	if input.Stock > 0 {
+		if _, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     models.ItemTypeVariant,
+			ItemID:       variant.ID,
+			MovementType: models.MovementAdjustment,
+			Quantity:     input.Stock,
+			Reason:       "Opening stock",
+			UnitCost:     &input.CostPrice,
+			UserID:       currentUserID(ctx),
+		}); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record opening stock"})
+			return
+		}
+	}
+
+	tx.Commit()
+
 	ctx.JSON(http.StatusCreated, gin.H{"message": "Variant created successfully", "id": variant.ID})
 }
 
@@ -81,24 +127,66 @@ func (c *VariantController) Update(ctx *gin.Context) {
 	if input.Price > 0 {
 		updates["price"] = input.Price
 	}
-	if input.Stock >= 0 {
-		updates["stock"] = input.Stock
+	if input.MinStock != nil {
+		updates["min_stock"] = *input.MinStock
+	}
+	if input.ReorderQuantity != nil {
+		updates["reorder_quantity"] = *input.ReorderQuantity
 	}
 	if input.ImageURL != "" {
 		updates["image_url"] = input.ImageURL
+		// Links set by hand have no thumbnail
+		updates["thumbnail_url"] = ""
 	}
 	if input.Attributes != "" {
+		attributeKey, err := catalog.CheckVariantAttributes(c.db, variant.ProductID, variant.ID, input.Attributes)
+		if err != nil {
+			variantAttributesError(ctx, err)
+			return
+		}
 		updates["attributes"] = input.Attributes
+		updates["attribute_key"] = attributeKey
 	}
 	if input.IsDefault != nil {
 		updates["is_default"] = *input.IsDefault
 	}
+	if input.BarCode != "" {
+		if !validBarCode(input.BarCode) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode check digit"})
+			return
+		}
+		updates["bar_code"] = input.BarCode
+	}
 
-	if err := c.db.Model(&variant).Updates(updates).Error; err != nil {
+	oldPrice := variant.Price
+	tx := c.db.Begin()
+	if err := tx.Model(&variant).Updates(updates).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update variant"})
 		return
 	}
 
+	if input.Price > 0 {
+		if err := pricing.RecordChange(tx, models.ItemTypeVariant, variant.ID, &oldPrice, input.Price,
+			models.PriceSourceManual, currentUserID(ctx)); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record price history"})
+			return
+		}
+	}
+
+	// Stock is never overwritten; a new count is posted as an adjustment
+	if input.Stock != nil {
+		if _, err := inventory.SetCount(tx, models.ItemTypeVariant, variant.ID, *input.Stock,
+			"Stock updated on variant", "", currentUserID(ctx)); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to adjust stock"})
+			return
+		}
+	}
+
+	tx.Commit()
+
 	ctx.JSON(http.StatusOK, gin.H{"message": "Variant updated successfully"})
 }
 
@@ -133,3 +221,101 @@ func (c *VariantController) GetByProductID(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, variants)
 }
+
+func (c *VariantController) GetOptions(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.First(&product, ctx.Param("productId")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	options, err := catalog.ProductOptions(c.db, product.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product options"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, options)
+}
+
+// SetOptions replaces the options, such as Size and Color, that variants of a
+// product are generated from.
+func (c *VariantController) SetOptions(ctx *gin.Context) {
+	var input dto.SetProductOptionsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := c.db.First(&product, ctx.Param("productId")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	options := make([]models.ProductOption, len(input.Options))
+	for i, option := range input.Options {
+		options[i].Name = option.Name
+		for _, value := range option.Values {
+			options[i].Values = append(options[i].Values, models.ProductOptionValue{
+				Value:           value.Value,
+				SKUCode:         value.SKUCode,
+				PriceAdjustment: value.PriceAdjustment,
+			})
+		}
+	}
+
+	tx := c.db.Begin()
+	if err := catalog.ReplaceOptions(tx, product.ID, options); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Product options updated successfully"})
+}
+
+// Generate creates the variants for every combination of the product's option
+// values. Combinations that already have a variant are skipped, so it can be
+// run again after adding a value.
+func (c *VariantController) Generate(ctx *gin.Context) {
+	var input dto.GenerateVariantsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := c.db.First(&product, ctx.Param("productId")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	tx := c.db.Begin()
+	result, err := catalog.GenerateVariants(tx, &product, input.Stock, currentUserID(ctx))
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, catalog.ErrNoOptions) || errors.Is(err, catalog.ErrTooManyVariants) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate variants"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// Helper functions
+func variantAttributesError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, catalog.ErrInvalidAttributes):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, catalog.ErrDuplicateVariant):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check variant attributes"})
+	}
+}
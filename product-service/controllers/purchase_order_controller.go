@@ -0,0 +1,433 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseOrderController struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderController(db *gorm.DB) *PurchaseOrderController {
+	return &PurchaseOrderController{db: db}
+}
+
+func (c *PurchaseOrderController) Create(ctx *gin.Context) {
+	var input dto.CreatePurchaseOrderDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var supplier models.Supplier
+	if err := c.db.Where("is_active = ?", true).First(&supplier, input.SupplierID).Error; err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	items, total, err := buildPurchaseOrderItems(c.db, input.Items)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order := models.PurchaseOrder{
+		Number:     documentNumber("PO"),
+		SupplierID: supplier.ID,
+		Status:     models.PurchaseOrderDraft,
+		TotalCost:  total,
+		Notes:      input.Notes,
+		ExpectedAt: input.ExpectedAt,
+		CreatedBy:  currentUserID(ctx),
+		Items:      items,
+	}
+
+	if err := c.db.Create(&order).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create purchase order"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Purchase order created successfully", "id": order.ID, "number": order.Number})
+}
+
+// Update edits a draft purchase order. Orders that were sent can no longer be
+// changed.
+func (c *PurchaseOrderController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdatePurchaseOrderDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var order models.PurchaseOrder
+	if err := c.db.First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	if order.Status != models.PurchaseOrderDraft {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft purchase orders can be edited"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.SupplierID != nil {
+		var supplier models.Supplier
+		if err := c.db.Where("is_active = ?", true).First(&supplier, *input.SupplierID).Error; err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Supplier not found"})
+			return
+		}
+		updates["supplier_id"] = supplier.ID
+	}
+	if input.ExpectedAt != nil {
+		updates["expected_at"] = input.ExpectedAt
+	}
+	if input.Notes != "" {
+		updates["notes"] = input.Notes
+	}
+
+	tx := c.db.Begin()
+
+	if len(input.Items) > 0 {
+		items, total, err := buildPurchaseOrderItems(tx, input.Items)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Unscoped().Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace purchase order items"})
+			return
+		}
+
+		for i := range items {
+			items[i].PurchaseOrderID = order.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace purchase order items"})
+			return
+		}
+		updates["total_cost"] = total
+	}
+
+	if err := tx.Model(&order).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update purchase order"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order updated successfully"})
+}
+
+func (c *PurchaseOrderController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var order models.PurchaseOrder
+	if err := c.db.First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	if order.Status != models.PurchaseOrderDraft {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft purchase orders can be deleted; close it instead"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete purchase order"})
+		return
+	}
+	if err := tx.Delete(&order).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete purchase order"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order deleted successfully"})
+}
+
+func (c *PurchaseOrderController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var order models.PurchaseOrder
+	if err := c.db.Preload("Supplier").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+func (c *PurchaseOrderController) List(ctx *gin.Context) {
+	var params dto.PurchaseOrderQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Preload("Supplier").Order("created_at desc")
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.SupplierID != nil {
+		query = query.Where("supplier_id = ?", *params.SupplierID)
+	}
+
+	var orders []models.PurchaseOrder
+	if err := query.Find(&orders).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch purchase orders"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+// Send marks a draft as sent to the supplier, after which it can be received.
+func (c *PurchaseOrderController) Send(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var order models.PurchaseOrder
+	if err := c.db.First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	if order.Status != models.PurchaseOrderDraft {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft purchase orders can be sent"})
+		return
+	}
+
+	if err := c.db.Model(&order).Updates(map[string]interface{}{
+		"status":  models.PurchaseOrderSent,
+		"sent_at": time.Now(),
+	}).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send purchase order"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order sent successfully"})
+}
+
+// Receive books delivered quantities into stock. Each received line posts a
+// receiving movement at the line cost, which feeds the item's cost price.
+func (c *PurchaseOrderController) Receive(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.ReceivePurchaseOrderDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	var order models.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Supplier").
+		Preload("Items").
+		First(&order, id).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	if order.Status != models.PurchaseOrderSent && order.Status != models.PurchaseOrderPartiallyReceived {
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only sent or partially received purchase orders can be received"})
+		return
+	}
+
+	lines := make(map[uint]*models.PurchaseOrderItem, len(order.Items))
+	for i := range order.Items {
+		lines[order.Items[i].ID] = &order.Items[i]
+	}
+
+	for _, received := range input.Items {
+		line, ok := lines[received.ItemID]
+		if !ok {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Item %d is not on this purchase order", received.ItemID)})
+			return
+		}
+
+		if remaining := line.Quantity - line.ReceivedQuantity; received.Quantity > remaining {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Only %d of %s remain to be received", remaining, line.Name)})
+			return
+		}
+
+		unitCost := line.UnitCost
+		if received.UnitCost != nil {
+			unitCost = *received.UnitCost
+		}
+
+		// Lines are ordered in the purchase unit; stock is kept in the
+		// stock unit
+		factor := line.UnitFactor
+		if factor < 1 {
+			factor = 1
+		}
+
+		itemType, itemID := models.ItemTypeProduct, line.ProductID
+		if line.VariantID != nil {
+			itemType, itemID = models.ItemTypeVariant, *line.VariantID
+		}
+
+		reason := "Received from " + order.Supplier.Name
+		if input.Notes != "" {
+			reason += ": " + input.Notes
+		}
+		if _, err := inventory.Receive(tx, inventory.Movement{
+			ItemType:  itemType,
+			ItemID:    itemID,
+			Quantity:  received.Quantity * factor,
+			Reason:    reason,
+			Reference: order.Number,
+			UserID:    currentUserID(ctx),
+			Outlet:    input.Outlet,
+		}, unitCost/float64(factor)); err != nil {
+			tx.Rollback()
+			ctx.JSON(inventoryErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		line.ReceivedQuantity += received.Quantity
+		if err := tx.Model(line).Update("received_quantity", line.ReceivedQuantity).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update purchase order item"})
+			return
+		}
+	}
+
+	updates := map[string]interface{}{"status": models.PurchaseOrderPartiallyReceived}
+	if fullyReceived(order.Items) {
+		updates["status"] = models.PurchaseOrderReceived
+		updates["received_at"] = time.Now()
+	}
+	if err := tx.Model(&order).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update purchase order"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Goods received successfully", "status": updates["status"]})
+}
+
+// Close finishes a purchase order. Quantities still outstanding on a sent or
+// partially received order are no longer expected.
+func (c *PurchaseOrderController) Close(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var order models.PurchaseOrder
+	if err := c.db.First(&order, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Purchase order not found"})
+		return
+	}
+
+	switch order.Status {
+	case models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived, models.PurchaseOrderReceived:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only sent or received purchase orders can be closed"})
+		return
+	}
+
+	if err := c.db.Model(&order).Updates(map[string]interface{}{
+		"status":    models.PurchaseOrderClosed,
+		"closed_at": time.Now(),
+	}).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close purchase order"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order closed successfully"})
+}
+
+// Helper functions
+func buildPurchaseOrderItems(db *gorm.DB, input []dto.PurchaseOrderItemDTO) ([]models.PurchaseOrderItem, float64, error) {
+	items := make([]models.PurchaseOrderItem, 0, len(input))
+	var total float64
+
+	for _, line := range input {
+		var product models.Product
+		if err := db.Preload("Variants").First(&product, line.ProductID).Error; err != nil {
+			return nil, 0, fmt.Errorf("product %d not found", line.ProductID)
+		}
+
+		units, err := catalog.ProductUnits(db, &product)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		item := models.PurchaseOrderItem{
+			ProductID:  product.ID,
+			Name:       product.Name,
+			SKU:        product.SKU,
+			Quantity:   line.Quantity,
+			Unit:       units.PurchaseCode(),
+			UnitFactor: units.PurchaseFactor,
+			UnitCost:   line.UnitCost,
+			LineTotal:  math.Round(float64(line.Quantity)*line.UnitCost*100) / 100,
+		}
+
+		if line.VariantID != nil {
+			var variant *models.ProductVariant
+			for i := range product.Variants {
+				if product.Variants[i].ID == *line.VariantID {
+					variant = &product.Variants[i]
+				}
+			}
+			if variant == nil {
+				return nil, 0, fmt.Errorf("variant %d does not belong to product %d", *line.VariantID, product.ID)
+			}
+			item.VariantID = &variant.ID
+			item.Name = strings.TrimSpace(product.Name + " " + variant.Name)
+			item.SKU = variant.SKU
+		} else if len(product.Variants) > 0 {
+			return nil, 0, fmt.Errorf("product %d has variants; order a specific variant", product.ID)
+		}
+
+		items = append(items, item)
+		total += item.LineTotal
+	}
+
+	return items, math.Round(total*100) / 100, nil
+}
+
+func fullyReceived(items []models.PurchaseOrderItem) bool {
+	for _, item := range items {
+		if item.ReceivedQuantity < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// documentNumber returns a readable, unique number such as PO-20240131-1A2B3C.
+func documentNumber(prefix string) string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%s-%s", prefix, time.Now().Format("20060102"), strings.ToUpper(hex.EncodeToString(suffix)))
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type RecipeController struct {
+	db *gorm.DB
+}
+
+func NewRecipeController(db *gorm.DB) *RecipeController {
+	return &RecipeController{db: db}
+}
+
+func (c *RecipeController) GetProductRecipe(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	c.getRecipe(ctx, models.ItemTypeProduct, product.ID, nil)
+}
+
+func (c *RecipeController) SetProductRecipe(ctx *gin.Context) {
+	var product models.Product
+	if err := c.db.Select("id").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	c.setRecipe(ctx, models.ItemTypeProduct, product.ID)
+}
+
+// GetVariantRecipe returns the variant's own recipe or, when it has none, the
+// product's recipe it is made with.
+func (c *RecipeController) GetVariantRecipe(ctx *gin.Context) {
+	var variant models.ProductVariant
+	if err := c.db.Select("id, product_id").First(&variant, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+	c.getRecipe(ctx, models.ItemTypeVariant, variant.ID, &variant.ProductID)
+}
+
+func (c *RecipeController) SetVariantRecipe(ctx *gin.Context) {
+	var variant models.ProductVariant
+	if err := c.db.Select("id").First(&variant, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+	c.setRecipe(ctx, models.ItemTypeVariant, variant.ID)
+}
+
+func (c *RecipeController) GetAddonRecipe(ctx *gin.Context) {
+	var addon models.ProductAddon
+	if err := c.db.Select("id").First(&addon, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Addon not found"})
+		return
+	}
+	c.getRecipe(ctx, models.ItemTypeAddon, addon.ID, nil)
+}
+
+func (c *RecipeController) SetAddonRecipe(ctx *gin.Context) {
+	var addon models.ProductAddon
+	if err := c.db.Select("id").First(&addon, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Addon not found"})
+		return
+	}
+	c.setRecipe(ctx, models.ItemTypeAddon, addon.ID)
+}
+
+// Helper functions
+func (c *RecipeController) getRecipe(ctx *gin.Context, itemType string, itemID uint, productID *uint) {
+	lines, err := inventory.ItemRecipe(c.db, itemType, itemID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+		return
+	}
+
+	recipe := dto.RecipeDTO{ItemType: itemType, ItemID: itemID, Lines: lines}
+	if len(lines) == 0 && productID != nil {
+		if recipe.Lines, err = inventory.ItemRecipe(c.db, models.ItemTypeProduct, *productID); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+			return
+		}
+		recipe.Inherited = len(recipe.Lines) > 0
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+func (c *RecipeController) setRecipe(ctx *gin.Context, itemType string, itemID uint) {
+	var input dto.SetRecipeDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	lines := make([]models.RecipeLine, len(input.Lines))
+	for i, line := range input.Lines {
+		lines[i] = models.RecipeLine{IngredientID: line.IngredientID, Quantity: line.Quantity}
+	}
+
+	tx := c.db.Begin()
+	if err := inventory.ReplaceRecipe(tx, itemType, itemID, lines); err != nil {
+		tx.Rollback()
+		if errors.Is(err, inventory.ErrRecipeIngredient) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recipe"})
+		return
+	}
+	tx.Commit()
+
+	c.getRecipe(ctx, itemType, itemID, nil)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+// ReservationController serves the internal stock reservation API used by
+// order-service at checkout.
+type ReservationController struct {
+	db *gorm.DB
+}
+
+func NewReservationController(db *gorm.DB) *ReservationController {
+	return &ReservationController{db: db}
+}
+
+func (c *ReservationController) Reserve(ctx *gin.Context) {
+	var input dto.ReserveStockDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ttl := reservationTTL()
+	switch {
+	case input.Held:
+		ttl = 0
+	case input.TTLSeconds > 0:
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+	}
+
+	// Quantities arrive in the unit items are sold in
+	items := make([]inventory.ReservationItem, len(input.Items))
+	for i, item := range input.Items {
+		units, err := catalog.UnitsOf(c.db, item.ItemType, item.ItemID)
+		if err != nil {
+			ctx.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		quantity, err := units.ToStock(item.Quantity)
+		if err != nil {
+			// 422 so callers can tell a quantity the item cannot be sold in
+			// from a malformed request
+			ctx.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		items[i] = inventory.ReservationItem{
+			ItemType:     item.ItemType,
+			ItemID:       item.ItemID,
+			Quantity:     quantity,
+			SellQuantity: item.Quantity,
+		}
+	}
+
+	tx := c.db.Begin()
+	reservation, created, err := inventory.Reserve(tx, input.Key, input.Reference, input.Outlet, ttl, items)
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	ctx.JSON(status, reservation)
+}
+
+func (c *ReservationController) GetByKey(ctx *gin.Context) {
+	var reservation models.StockReservation
+	if err := c.db.Preload("Items").Where("key = ?", ctx.Param("key")).First(&reservation).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Reservation not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+func (c *ReservationController) Commit(ctx *gin.Context) {
+	tx := c.db.Begin()
+	reservation, err := inventory.Commit(tx, ctx.Param("key"), nil)
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+func (c *ReservationController) Release(ctx *gin.Context) {
+	tx := c.db.Begin()
+	reservation, err := inventory.Release(tx, ctx.Param("key"))
+	if err != nil {
+		tx.Rollback()
+		ctx.JSON(reservationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// Helper functions
+func reservationTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("STOCK_RESERVATION_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+func reservationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, inventory.ErrReservationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, inventory.ErrReservationClosed),
+		errors.Is(err, inventory.ErrReservationCommitted),
+		errors.Is(err, inventory.ErrReservationMismatch):
+		return http.StatusConflict
+	default:
+		return inventoryErrorStatus(err)
+	}
+}
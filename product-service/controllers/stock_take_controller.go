@@ -0,0 +1,433 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockTakeController struct {
+	db *gorm.DB
+}
+
+func NewStockTakeController(db *gorm.DB) *StockTakeController {
+	return &StockTakeController{db: db}
+}
+
+// Start opens a count session and freezes the system quantity of every item in
+// scope: products without variants, variants, and for a whole outlet count
+// also addons and ingredients.
+func (c *StockTakeController) Start(ctx *gin.Context) {
+	var input dto.StartStockTakeDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.CategoryID != nil {
+		var category models.ProductCategory
+		if err := c.db.First(&category, *input.CategoryID).Error; err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return
+		}
+	}
+
+	session := models.StockTake{
+		Number:     documentNumber("ST"),
+		Outlet:     input.Outlet,
+		CategoryID: input.CategoryID,
+		Status:     models.StockTakeOpen,
+		Notes:      input.Notes,
+		StartedBy:  currentUserID(ctx),
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Create(&session).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start stock take"})
+		return
+	}
+
+	if err := tx.Exec(stockTakeSnapshotSQL(input.CategoryID != nil), map[string]interface{}{
+		"session":  session.ID,
+		"category": input.CategoryID,
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to snapshot stock"})
+		return
+	}
+	tx.Commit()
+
+	var lines int64
+	c.db.Model(&models.StockTakeLine{}).Where("stock_take_id = ?", session.ID).Count(&lines)
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Stock take started", "id": session.ID, "number": session.Number, "lines": lines})
+}
+
+func (c *StockTakeController) List(ctx *gin.Context) {
+	query := c.db.Order("created_at desc")
+	if status := ctx.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var sessions []models.StockTake
+	if err := query.Find(&sessions).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stock takes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// GetByID returns a session with its lines, optionally only counted,
+// uncounted or variance lines.
+func (c *StockTakeController) GetByID(ctx *gin.Context) {
+	var params dto.StockTakeLineQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var session models.StockTake
+	if err := c.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return filterStockTakeLines(db, params.Status).Order("name asc")
+	}).First(&session, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock take not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// RecordCounts stores counted quantities. Counts can be entered again until
+// the session is approved; the latest count wins unless mode is "add".
+func (c *StockTakeController) RecordCounts(ctx *gin.Context) {
+	var input dto.RecordCountsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	session, ok := lockOpenStockTake(ctx, tx)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	updated := make([]models.StockTakeLine, 0, len(input.Counts))
+	for _, count := range input.Counts {
+		line, err := findStockTakeLine(tx, session.ID, count)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		counted := count.Quantity
+		if count.Mode == "add" && line.CountedQuantity != nil {
+			counted += *line.CountedQuantity
+		}
+		variance := counted - line.SystemQuantity
+
+		line.CountedQuantity = &counted
+		line.Variance = variance
+		line.VarianceValue = math.Round(float64(variance)*line.UnitCost*100) / 100
+		line.CountedBy = currentUserID(ctx)
+		line.CountedAt = &now
+
+		if err := tx.Model(line).Updates(map[string]interface{}{
+			"counted_quantity": counted,
+			"variance":         line.Variance,
+			"variance_value":   line.VarianceValue,
+			"counted_by":       line.CountedBy,
+			"counted_at":       now,
+		}).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record count"})
+			return
+		}
+		updated = append(updated, *line)
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// Variances summarises the count and lists every line whose count differs
+// from the frozen system quantity.
+func (c *StockTakeController) Variances(ctx *gin.Context) {
+	var session models.StockTake
+	if err := c.db.First(&session, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock take not found"})
+		return
+	}
+
+	var summary struct {
+		TotalLines    int
+		CountedLines  int
+		VarianceLines int
+		VarianceValue float64
+	}
+	if err := c.db.Model(&models.StockTakeLine{}).
+		Select(`count(*) AS total_lines,
+			count(counted_quantity) AS counted_lines,
+			count(*) FILTER (WHERE counted_quantity IS NOT NULL AND variance <> 0) AS variance_lines,
+			coalesce(sum(variance_value) FILTER (WHERE counted_quantity IS NOT NULL), 0) AS variance_value`).
+		Where("stock_take_id = ?", session.ID).
+		Scan(&summary).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarise stock take"})
+		return
+	}
+
+	var lines []models.StockTakeLine
+	if err := filterStockTakeLines(c.db, "variance").
+		Where("stock_take_id = ?", session.ID).
+		Order("abs(variance_value) desc, name asc").
+		Find(&lines).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch variances"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.StockTakeVarianceDTO{
+		StockTake:      session,
+		TotalLines:     summary.TotalLines,
+		CountedLines:   summary.CountedLines,
+		UncountedLines: summary.TotalLines - summary.CountedLines,
+		VarianceLines:  summary.VarianceLines,
+		VarianceValue:  math.Round(summary.VarianceValue*100) / 100,
+		Lines:          lines,
+	})
+}
+
+// Approve posts an adjustment for every counted line with a variance. The
+// variance is applied to current stock rather than overwriting it, so sales
+// made while counting are kept. The session and its lines stay for audit.
+func (c *StockTakeController) Approve(ctx *gin.Context) {
+	var input dto.ApproveStockTakeDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	session, ok := lockOpenStockTake(ctx, tx)
+	if !ok {
+		return
+	}
+
+	var lines []models.StockTakeLine
+	if err := tx.Where("stock_take_id = ?", session.ID).Order("id asc").Find(&lines).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stock take lines"})
+		return
+	}
+
+	var adjusted int
+	var varianceValue float64
+	for _, line := range lines {
+		updates := map[string]interface{}{}
+		if line.CountedQuantity == nil {
+			if !input.ZeroUncounted {
+				continue
+			}
+			zero := 0
+			line.CountedQuantity = &zero
+			line.Variance = -line.SystemQuantity
+			updates["counted_quantity"] = 0
+			updates["variance"] = line.Variance
+		}
+
+		if line.Variance != 0 {
+			movement, err := inventory.Post(tx, inventory.Movement{
+				ItemType:      line.ItemType,
+				ItemID:        line.ItemID,
+				MovementType:  models.MovementAdjustment,
+				Quantity:      line.Variance,
+				Reason:        "Stock take variance",
+				Reference:     session.Number,
+				UserID:        currentUserID(ctx),
+				UnitCost:      &line.UnitCost,
+				Outlet:        session.Outlet,
+				AllowNegative: true,
+			})
+			if err != nil && !errors.Is(err, inventory.ErrItemNotFound) {
+				tx.Rollback()
+				ctx.JSON(inventoryErrorStatus(err), gin.H{"error": fmt.Sprintf("%s: %v", line.Name, err)})
+				return
+			}
+			if movement != nil {
+				line.UnitCost = movement.UnitCost
+				updates["movement_id"] = movement.ID
+				updates["unit_cost"] = movement.UnitCost
+				adjusted++
+			}
+		}
+
+		line.VarianceValue = math.Round(float64(line.Variance)*line.UnitCost*100) / 100
+		updates["variance_value"] = line.VarianceValue
+		varianceValue += line.VarianceValue
+
+		if err := tx.Model(&line).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock take line"})
+			return
+		}
+	}
+
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"status":      models.StockTakeApproved,
+		"approved_by": currentUserID(ctx),
+		"approved_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve stock take"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Stock take approved",
+		"adjustments":   adjusted,
+		"varianceValue": math.Round(varianceValue*100) / 100,
+	})
+}
+
+func (c *StockTakeController) Cancel(ctx *gin.Context) {
+	tx := c.db.Begin()
+
+	session, ok := lockOpenStockTake(ctx, tx)
+	if !ok {
+		return
+	}
+
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"status":       models.StockTakeCancelled,
+		"cancelled_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel stock take"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock take cancelled"})
+}
+
+// Helper functions
+func stockTakeSnapshotSQL(byCategory bool) string {
+	categoryFilter := ""
+	if byCategory {
+		categoryFilter = " AND p.category_id IN (SELECT c.id FROM product_categories c WHERE c.path LIKE (SELECT path FROM product_categories WHERE id = @category) || '%')"
+	}
+
+	query := `
+		INSERT INTO stock_take_lines (created_at, updated_at, stock_take_id, item_type, item_id, name, sku, bar_code, system_quantity, unit_cost, variance, variance_value)
+		SELECT now(), now(), @session, 'product', p.id, p.name, p.sku, coalesce(p.bar_code, ''), p.stock, p.cost_price, 0, 0
+		FROM products p
+		WHERE p.deleted_at IS NULL` + categoryFilter + `
+			AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL)
+		UNION ALL
+		SELECT now(), now(), @session, 'variant', v.id, p.name || ' ' || v.name, v.sku, coalesce(v.bar_code, ''), v.stock, v.cost_price, 0, 0
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id AND p.deleted_at IS NULL
+		WHERE v.deleted_at IS NULL` + categoryFilter
+
+	if !byCategory {
+		query += `
+		UNION ALL
+		SELECT now(), now(), @session, 'addon', a.id, a.name, a.sku, coalesce(a.bar_code, ''), a.stock, a.cost_price, 0, 0
+		FROM product_addons a
+		WHERE a.deleted_at IS NULL
+		UNION ALL
+		SELECT now(), now(), @session, 'ingredient', i.id, i.name, i.sku, '', i.stock, i.cost_price, 0, 0
+		FROM ingredients i
+		WHERE i.deleted_at IS NULL`
+	}
+	return query
+}
+
+func filterStockTakeLines(db *gorm.DB, status string) *gorm.DB {
+	switch status {
+	case "counted":
+		return db.Where("counted_quantity IS NOT NULL")
+	case "uncounted":
+		return db.Where("counted_quantity IS NULL")
+	case "variance":
+		return db.Where("counted_quantity IS NOT NULL AND variance <> 0")
+	}
+	return db
+}
+
+// lockOpenStockTake locks the session for the request and writes the error
+// response itself when it cannot be changed.
+func lockOpenStockTake(ctx *gin.Context, tx *gorm.DB) (*models.StockTake, bool) {
+	var session models.StockTake
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, ctx.Param("id")).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock take not found"})
+		return nil, false
+	}
+
+	if session.Status != models.StockTakeOpen {
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Stock take is " + session.Status})
+		return nil, false
+	}
+	return &session, true
+}
+
+// findStockTakeLine resolves a count to its line by line ID, item, or a
+// scanned barcode or SKU.
+func findStockTakeLine(tx *gorm.DB, sessionID uint, count dto.StockCountDTO) (*models.StockTakeLine, error) {
+	query := tx.Where("stock_take_id = ?", sessionID)
+
+	switch {
+	case count.LineID != nil:
+		query = query.Where("id = ?", *count.LineID)
+	case count.ItemType != "" && count.ItemID != nil:
+		query = query.Where("item_type = ? AND item_id = ?", count.ItemType, *count.ItemID)
+	case count.Code != "":
+		// A bad check digit still matches a numeric SKU exactly
+		scanned, _ := utils.ParseScannedCode(count.Code)
+		// Candidates are most specific first, barcodes before SKUs
+		for _, code := range scanned.Candidates {
+			for _, column := range []string{"bar_code", "sku"} {
+				var line models.StockTakeLine
+				result := tx.Where("stock_take_id = ? AND "+column+" = ?", sessionID, code).Limit(1).Find(&line)
+				if result.Error != nil {
+					return nil, result.Error
+				}
+				if result.RowsAffected > 0 {
+					return &line, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("no item in this stock take matches code %s", count.Code)
+	default:
+		return nil, errors.New("each count needs a lineId, an itemType and itemId, or a code")
+	}
+
+	var line models.StockTakeLine
+	result := query.Limit(1).Find(&line)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("no item in this stock take matches the count")
+	}
+	return &line, nil
+}
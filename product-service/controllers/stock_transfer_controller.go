@@ -0,0 +1,512 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockTransferController struct {
+	db *gorm.DB
+}
+
+func NewStockTransferController(db *gorm.DB) *StockTransferController {
+	return &StockTransferController{db: db}
+}
+
+func (c *StockTransferController) Create(ctx *gin.Context) {
+	var input dto.CreateStockTransferDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := buildStockTransferItems(c.db, input.Items)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer := models.StockTransfer{
+		Number:            documentNumber("TR"),
+		SourceOutlet:      input.SourceOutlet,
+		DestinationOutlet: input.DestinationOutlet,
+		Status:            models.StockTransferDraft,
+		Notes:             input.Notes,
+		CreatedBy:         currentUserID(ctx),
+		Items:             items,
+	}
+
+	if err := c.db.Create(&transfer).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create stock transfer"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Stock transfer created successfully", "id": transfer.ID, "number": transfer.Number})
+}
+
+// Update edits a draft transfer. Transfers that were sent can no longer be
+// changed.
+func (c *StockTransferController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateStockTransferDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var transfer models.StockTransfer
+	if err := c.db.First(&transfer, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock transfer not found"})
+		return
+	}
+
+	if transfer.Status != models.StockTransferDraft {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft stock transfers can be edited"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	source, destination := transfer.SourceOutlet, transfer.DestinationOutlet
+	if input.SourceOutlet != "" {
+		source = input.SourceOutlet
+		updates["source_outlet"] = source
+	}
+	if input.DestinationOutlet != "" {
+		destination = input.DestinationOutlet
+		updates["destination_outlet"] = destination
+	}
+	if source == destination {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Source and destination outlet must differ"})
+		return
+	}
+	if input.Notes != "" {
+		updates["notes"] = input.Notes
+	}
+
+	tx := c.db.Begin()
+
+	if len(input.Items) > 0 {
+		items, err := buildStockTransferItems(tx, input.Items)
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := tx.Unscoped().Where("stock_transfer_id = ?", transfer.ID).Delete(&models.StockTransferItem{}).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace stock transfer items"})
+			return
+		}
+
+		for i := range items {
+			items[i].StockTransferID = transfer.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace stock transfer items"})
+			return
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := tx.Model(&transfer).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock transfer"})
+			return
+		}
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock transfer updated successfully"})
+}
+
+func (c *StockTransferController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var transfer models.StockTransfer
+	if err := c.db.First(&transfer, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock transfer not found"})
+		return
+	}
+
+	if transfer.Status != models.StockTransferDraft {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft stock transfers can be deleted; cancel it instead"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Where("stock_transfer_id = ?", transfer.ID).Delete(&models.StockTransferItem{}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete stock transfer"})
+		return
+	}
+	if err := tx.Delete(&transfer).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete stock transfer"})
+		return
+	}
+	tx.Commit()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock transfer deleted successfully"})
+}
+
+func (c *StockTransferController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var transfer models.StockTransfer
+	if err := c.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		First(&transfer, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock transfer not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+func (c *StockTransferController) List(ctx *gin.Context) {
+	var params dto.StockTransferQuery
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Order("created_at desc")
+	if params.Status != "" {
+		query = query.Where("status = ?", params.Status)
+	}
+	if params.Outlet != "" {
+		query = query.Where("source_outlet = ? OR destination_outlet = ?", params.Outlet, params.Outlet)
+	}
+
+	var transfers []models.StockTransfer
+	if err := query.Find(&transfers).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch stock transfers"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// Send takes the goods out of the source outlet. Each line posts a transfer
+// movement that fails when the source does not hold enough, and the goods stay
+// in transit until the destination receives them.
+func (c *StockTransferController) Send(ctx *gin.Context) {
+	tx := c.db.Begin()
+
+	transfer, ok := lockStockTransfer(ctx, tx)
+	if !ok {
+		return
+	}
+
+	if transfer.Status != models.StockTransferDraft {
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft stock transfers can be sent"})
+		return
+	}
+
+	for i := range transfer.Items {
+		line := &transfer.Items[i]
+		movement, err := inventory.Post(tx, inventory.Movement{
+			ItemType:     line.ItemType,
+			ItemID:       line.ItemID,
+			MovementType: models.MovementTransfer,
+			Quantity:     -line.Quantity,
+			Reason:       "Sent to " + transfer.DestinationOutlet,
+			Reference:    transfer.Number,
+			UserID:       currentUserID(ctx),
+			Outlet:       transfer.SourceOutlet,
+		})
+		if err != nil {
+			tx.Rollback()
+			ctx.JSON(inventoryErrorStatus(err), gin.H{"error": fmt.Sprintf("%s: %v", line.Name, err)})
+			return
+		}
+
+		if err := tx.Model(line).Updates(map[string]interface{}{
+			"unit_cost":        movement.UnitCost,
+			"send_movement_id": movement.ID,
+		}).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock transfer item"})
+			return
+		}
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":  models.StockTransferInTransit,
+		"sent_by": currentUserID(ctx),
+		"sent_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send stock transfer"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock transfer sent successfully"})
+}
+
+// Receive books what arrived into the destination outlet at the cost it left
+// the source. Any shortage is recorded on the line as a discrepancy.
+func (c *StockTransferController) Receive(ctx *gin.Context) {
+	var input dto.ReceiveStockTransferDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	transfer, ok := lockStockTransfer(ctx, tx)
+	if !ok {
+		return
+	}
+
+	if transfer.Status != models.StockTransferInTransit {
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only stock transfers in transit can be received"})
+		return
+	}
+
+	received := make(map[uint]dto.ReceiveTransferItemDTO, len(input.Items))
+	for _, item := range input.Items {
+		received[item.ItemID] = item
+	}
+	for itemID := range received {
+		if !hasTransferLine(transfer.Items, itemID) {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Item %d is not on this stock transfer", itemID)})
+			return
+		}
+	}
+
+	var discrepancies int
+	for i := range transfer.Items {
+		line := &transfer.Items[i]
+
+		quantity, reason := line.Quantity, ""
+		if item, ok := received[line.ID]; ok {
+			quantity, reason = *item.ReceivedQuantity, item.Reason
+		}
+		if quantity > line.Quantity {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Only %d of %s were sent", line.Quantity, line.Name)})
+			return
+		}
+
+		updates := map[string]interface{}{
+			"received_quantity":  quantity,
+			"discrepancy":        line.Quantity - quantity,
+			"discrepancy_reason": reason,
+		}
+
+		if quantity > 0 {
+			movement, err := inventory.Post(tx, inventory.Movement{
+				ItemType:     line.ItemType,
+				ItemID:       line.ItemID,
+				MovementType: models.MovementTransfer,
+				Quantity:     quantity,
+				Reason:       "Received from " + transfer.SourceOutlet,
+				Reference:    transfer.Number,
+				UserID:       currentUserID(ctx),
+				UnitCost:     &line.UnitCost,
+				Outlet:       transfer.DestinationOutlet,
+			})
+			if err != nil {
+				tx.Rollback()
+				ctx.JSON(inventoryErrorStatus(err), gin.H{"error": fmt.Sprintf("%s: %v", line.Name, err)})
+				return
+			}
+			updates["receive_movement_id"] = movement.ID
+		}
+
+		if shortage := line.Quantity - quantity; shortage > 0 {
+			discrepancies++
+			if input.ReturnShortage {
+				movement, err := inventory.Post(tx, inventory.Movement{
+					ItemType:     line.ItemType,
+					ItemID:       line.ItemID,
+					MovementType: models.MovementTransfer,
+					Quantity:     shortage,
+					Reason:       transferReturnReason(transfer.DestinationOutlet, reason),
+					Reference:    transfer.Number,
+					UserID:       currentUserID(ctx),
+					UnitCost:     &line.UnitCost,
+					Outlet:       transfer.SourceOutlet,
+				})
+				if err != nil {
+					tx.Rollback()
+					ctx.JSON(inventoryErrorStatus(err), gin.H{"error": fmt.Sprintf("%s: %v", line.Name, err)})
+					return
+				}
+				updates["return_movement_id"] = movement.ID
+			}
+		}
+
+		if err := tx.Model(line).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock transfer item"})
+			return
+		}
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":      models.StockTransferReceived,
+		"received_by": currentUserID(ctx),
+		"received_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to receive stock transfer"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock transfer received successfully", "discrepancies": discrepancies})
+}
+
+// Cancel stops a transfer. Goods already in transit go back to the source
+// outlet.
+func (c *StockTransferController) Cancel(ctx *gin.Context) {
+	tx := c.db.Begin()
+
+	transfer, ok := lockStockTransfer(ctx, tx)
+	if !ok {
+		return
+	}
+
+	switch transfer.Status {
+	case models.StockTransferDraft:
+	case models.StockTransferInTransit:
+		for i := range transfer.Items {
+			line := &transfer.Items[i]
+			movement, err := inventory.Post(tx, inventory.Movement{
+				ItemType:     line.ItemType,
+				ItemID:       line.ItemID,
+				MovementType: models.MovementTransfer,
+				Quantity:     line.Quantity,
+				Reason:       "Transfer cancelled",
+				Reference:    transfer.Number,
+				UserID:       currentUserID(ctx),
+				UnitCost:     &line.UnitCost,
+				Outlet:       transfer.SourceOutlet,
+			})
+			if err != nil {
+				tx.Rollback()
+				ctx.JSON(inventoryErrorStatus(err), gin.H{"error": fmt.Sprintf("%s: %v", line.Name, err)})
+				return
+			}
+			if err := tx.Model(line).Update("return_movement_id", movement.ID).Error; err != nil {
+				tx.Rollback()
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock transfer item"})
+				return
+			}
+		}
+	default:
+		tx.Rollback()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Only draft or in transit stock transfers can be cancelled"})
+		return
+	}
+
+	if err := tx.Model(transfer).Updates(map[string]interface{}{
+		"status":       models.StockTransferCancelled,
+		"cancelled_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel stock transfer"})
+		return
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Stock transfer cancelled successfully"})
+}
+
+// Helper functions
+func buildStockTransferItems(db *gorm.DB, input []dto.StockTransferItemDTO) ([]models.StockTransferItem, error) {
+	items := make([]models.StockTransferItem, 0, len(input))
+	seen := map[string]bool{}
+
+	for _, line := range input {
+		key := fmt.Sprintf("%s:%d", line.ItemType, line.ItemID)
+		if seen[key] {
+			return nil, fmt.Errorf("%s %d appears more than once", line.ItemType, line.ItemID)
+		}
+		seen[key] = true
+
+		item := models.StockTransferItem{
+			ItemType: line.ItemType,
+			ItemID:   line.ItemID,
+			Quantity: line.Quantity,
+		}
+
+		switch line.ItemType {
+		case models.ItemTypeProduct:
+			var product models.Product
+			if err := db.Preload("Variants").First(&product, line.ItemID).Error; err != nil {
+				return nil, fmt.Errorf("product %d not found", line.ItemID)
+			}
+			if len(product.Variants) > 0 {
+				return nil, fmt.Errorf("product %d has variants; transfer a specific variant", product.ID)
+			}
+			item.Name, item.SKU = product.Name, product.SKU
+		case models.ItemTypeVariant:
+			var variant models.ProductVariant
+			if err := db.First(&variant, line.ItemID).Error; err != nil {
+				return nil, fmt.Errorf("variant %d not found", line.ItemID)
+			}
+			var product models.Product
+			if err := db.First(&product, variant.ProductID).Error; err != nil {
+				return nil, fmt.Errorf("variant %d not found", line.ItemID)
+			}
+			item.Name, item.SKU = strings.TrimSpace(product.Name+" "+variant.Name), variant.SKU
+		case models.ItemTypeAddon:
+			var addon models.ProductAddon
+			if err := db.First(&addon, line.ItemID).Error; err != nil {
+				return nil, fmt.Errorf("addon %d not found", line.ItemID)
+			}
+			item.Name, item.SKU = addon.Name, addon.SKU
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// lockStockTransfer locks the transfer with its lines for the request and
+// writes the error response itself when it is not found.
+func lockStockTransfer(ctx *gin.Context, tx *gorm.DB) (*models.StockTransfer, bool) {
+	var transfer models.StockTransfer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		First(&transfer, ctx.Param("id")).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Stock transfer not found"})
+		return nil, false
+	}
+	return &transfer, true
+}
+
+func hasTransferLine(items []models.StockTransferItem, id uint) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func transferReturnReason(destination, reason string) string {
+	if reason == "" {
+		return "Not received at " + destination
+	}
+	return "Not received at " + destination + ": " + reason
+}
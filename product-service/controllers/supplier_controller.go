@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type SupplierController struct {
+	db *gorm.DB
+}
+
+func NewSupplierController(db *gorm.DB) *SupplierController {
+	return &SupplierController{db: db}
+}
+
+func (c *SupplierController) Create(ctx *gin.Context) {
+	var input dto.CreateSupplierDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	supplier := models.Supplier{
+		Name:         input.Name,
+		ContactName:  input.ContactName,
+		Email:        input.Email,
+		Phone:        input.Phone,
+		Address:      input.Address,
+		TaxNumber:    input.TaxNumber,
+		PaymentTerms: input.PaymentTerms,
+		Notes:        input.Notes,
+		IsActive:     true,
+	}
+
+	if err := c.db.Create(&supplier).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create supplier"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Supplier created successfully", "id": supplier.ID})
+}
+
+func (c *SupplierController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateSupplierDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var supplier models.Supplier
+	if err := c.db.First(&supplier, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.ContactName != "" {
+		updates["contact_name"] = input.ContactName
+	}
+	if input.Email != "" {
+		updates["email"] = input.Email
+	}
+	if input.Phone != "" {
+		updates["phone"] = input.Phone
+	}
+	if input.Address != "" {
+		updates["address"] = input.Address
+	}
+	if input.TaxNumber != "" {
+		updates["tax_number"] = input.TaxNumber
+	}
+	if input.PaymentTerms != "" {
+		updates["payment_terms"] = input.PaymentTerms
+	}
+	if input.Notes != "" {
+		updates["notes"] = input.Notes
+	}
+	if input.IsActive != nil {
+		updates["is_active"] = *input.IsActive
+	}
+
+	if err := c.db.Model(&supplier).Updates(updates).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update supplier"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Supplier updated successfully"})
+}
+
+func (c *SupplierController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	// Keep suppliers that have open purchase orders
+	var openOrders int64
+	if err := c.db.Model(&models.PurchaseOrder{}).
+		Where("supplier_id = ? AND status <> ?", id, models.PurchaseOrderClosed).
+		Count(&openOrders).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check supplier usage"})
+		return
+	}
+
+	if openOrders > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete supplier with open purchase orders"})
+		return
+	}
+
+	if err := c.db.Delete(&models.Supplier{}, id).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete supplier"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Supplier deleted successfully"})
+}
+
+func (c *SupplierController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var supplier models.Supplier
+	if err := c.db.First(&supplier, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Supplier not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+func (c *SupplierController) List(ctx *gin.Context) {
+	query := c.db.Order("name asc")
+	if q := ctx.Query("q"); q != "" {
+		pattern := "%" + escapeLike(q) + "%"
+		query = query.Where("name ILIKE ? OR contact_name ILIKE ? OR email ILIKE ?", pattern, pattern, pattern)
+	}
+	if ctx.Query("active") == "true" {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var suppliers []models.Supplier
+	if err := query.Find(&suppliers).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suppliers"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suppliers)
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"github.com/ridhotamma/yourkasa/product-service/pricing"
+	"gorm.io/gorm"
+)
+
+type TaxClassController struct {
+	db *gorm.DB
+}
+
+func NewTaxClassController(db *gorm.DB) *TaxClassController {
+	return &TaxClassController{db: db}
+}
+
+func (c *TaxClassController) Create(ctx *gin.Context) {
+	var input dto.CreateTaxClassDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(input.Code))
+	var existing int64
+	if err := c.db.Unscoped().Model(&models.TaxClass{}).Where("code = ?", code).Count(&existing).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tax class code"})
+		return
+	}
+	if existing > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Tax class code already exists"})
+		return
+	}
+
+	taxClass := models.TaxClass{
+		Code:      code,
+		Name:      input.Name,
+		Rate:      input.Rate,
+		Inclusive: input.Inclusive,
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Create(&taxClass).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tax class"})
+		return
+	}
+
+	if input.IsDefault {
+		if err := pricing.SetDefaultTaxClass(tx, taxClass.ID); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set default tax class"})
+			return
+		}
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Tax class created successfully", "id": taxClass.ID})
+}
+
+func (c *TaxClassController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateTaxClassDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var taxClass models.TaxClass
+	if err := c.db.First(&taxClass, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tax class not found"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Rate != nil {
+		updates["rate"] = *input.Rate
+	}
+	if input.Inclusive != nil {
+		updates["inclusive"] = *input.Inclusive
+	}
+	if input.IsDefault != nil && !*input.IsDefault {
+		updates["is_default"] = false
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Model(&taxClass).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tax class"})
+		return
+	}
+
+	if input.IsDefault != nil && *input.IsDefault {
+		if err := pricing.SetDefaultTaxClass(tx, taxClass.ID); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set default tax class"})
+			return
+		}
+	}
+
+	tx.Commit()
+	ctx.JSON(http.StatusOK, gin.H{"message": "Tax class updated successfully"})
+}
+
+func (c *TaxClassController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var taxClass models.TaxClass
+	if err := c.db.First(&taxClass, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tax class not found"})
+		return
+	}
+
+	// Check for items taxed under this class
+	var usage int64
+	if err := c.db.Raw(`
+		SELECT (SELECT COUNT(*) FROM products WHERE tax_class_id = @id AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM product_addons WHERE tax_class_id = @id AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM product_groups WHERE tax_class_id = @id AND deleted_at IS NULL)`,
+		map[string]interface{}{"id": taxClass.ID}).Scan(&usage).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tax class usage"})
+		return
+	}
+
+	if usage > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete tax class assigned to products, addons or groups"})
+		return
+	}
+
+	if err := c.db.Delete(&taxClass).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tax class"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Tax class deleted successfully"})
+}
+
+func (c *TaxClassController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var taxClass models.TaxClass
+	if err := c.db.First(&taxClass, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tax class not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, taxClass)
+}
+
+func (c *TaxClassController) List(ctx *gin.Context) {
+	var taxClasses []models.TaxClass
+	if err := c.db.Order("code asc").Find(&taxClasses).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tax classes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, taxClasses)
+}
+
+// Resolve returns the tax class each item is sold under so order-service can
+// tax cart lines.
+func (c *TaxClassController) Resolve(ctx *gin.Context) {
+	var input dto.ResolveTaxesDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]pricing.Item, len(input.Items))
+	for i, item := range input.Items {
+		items[i] = pricing.Item{ItemType: item.ItemType, ItemID: item.ItemID}
+	}
+
+	taxes, err := pricing.ResolveTax(c.db, items)
+	if err != nil {
+		switch {
+		case errors.Is(err, pricing.ErrItemNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, pricing.ErrTaxItemType):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve taxes"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"taxes": taxes})
+}
+
+// Helper functions
+func taxClassExists(db *gorm.DB, id uint) bool {
+	var exists bool
+	db.Model(&models.TaxClass{}).Select("count(*) > 0").Where("id = ?", id).Find(&exists)
+	return exists
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/product-service/dto"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+type UnitController struct {
+	db *gorm.DB
+}
+
+func NewUnitController(db *gorm.DB) *UnitController {
+	return &UnitController{db: db}
+}
+
+func (c *UnitController) Create(ctx *gin.Context) {
+	var input dto.CreateUnitDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := strings.ToLower(strings.TrimSpace(input.Code))
+	var existing int64
+	if err := c.db.Unscoped().Model(&models.UnitOfMeasure{}).Where("code = ?", code).Count(&existing).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check unit code"})
+		return
+	}
+	if existing > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unit code already exists"})
+		return
+	}
+
+	unit := models.UnitOfMeasure{
+		Code:      code,
+		Name:      input.Name,
+		Dimension: input.Dimension,
+		Factor:    input.Factor,
+	}
+
+	if err := c.db.Create(&unit).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create unit"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Unit created successfully", "id": unit.ID})
+}
+
+func (c *UnitController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateUnitDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var unit models.UnitOfMeasure
+	if err := c.db.First(&unit, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	if err := c.db.Model(&unit).Update("name", input.Name).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update unit"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Unit updated successfully"})
+}
+
+func (c *UnitController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var unit models.UnitOfMeasure
+	if err := c.db.First(&unit, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	// Check for products using this unit
+	var productsCount int64
+	if err := c.db.Model(&models.Product{}).
+		Where("stock_unit_id = @id OR sell_unit_id = @id OR purchase_unit_id = @id", map[string]interface{}{"id": unit.ID}).
+		Count(&productsCount).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check unit usage"})
+		return
+	}
+
+	if productsCount > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete unit used by products"})
+		return
+	}
+
+	var ingredientsCount int64
+	if err := c.db.Model(&models.Ingredient{}).Where("unit_id = ?", unit.ID).Count(&ingredientsCount).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check unit usage"})
+		return
+	}
+
+	if ingredientsCount > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete unit used by ingredients"})
+		return
+	}
+
+	if err := c.db.Delete(&unit).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete unit"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Unit deleted successfully"})
+}
+
+func (c *UnitController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var unit models.UnitOfMeasure
+	if err := c.db.First(&unit, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Unit not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, unit)
+}
+
+func (c *UnitController) List(ctx *gin.Context) {
+	query := c.db.Model(&models.UnitOfMeasure{})
+	if dimension := ctx.Query("dimension"); dimension != "" {
+		query = query.Where("dimension = ?", dimension)
+	}
+
+	var units []models.UnitOfMeasure
+	if err := query.Order("dimension asc, factor asc").Find(&units).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch units"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, units)
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type AvailabilityRuleDTO struct {
+	Outlet    string `json:"outlet"`    // Empty applies to every outlet
+	Days      string `json:"days"`      // e.g. mon,tue,wed; empty means every day
+	StartTime string `json:"startTime"` // HH:MM in the store's time zone
+	EndTime   string `json:"endTime"`
+	StartDate string `json:"startDate"` // YYYY-MM-DD
+	EndDate   string `json:"endDate"`   // YYYY-MM-DD, inclusive
+}
+
+// SetAvailabilityDTO replaces the availability rules of a product or group.
+// An empty list makes it available at all times.
+type SetAvailabilityDTO struct {
+	Rules []AvailabilityRuleDTO `json:"rules" binding:"dive"`
+}
+
+type SellableItemDTO struct {
+	ItemType string `json:"itemType" binding:"required,oneof=product variant group"`
+	ItemID   uint   `json:"itemId" binding:"required"`
+}
+
+// CheckSellableDTO asks whether items can be sold at an outlet at a time, now
+// when At is omitted.
+type CheckSellableDTO struct {
+	Items  []SellableItemDTO `json:"items" binding:"required,min=1,dive"`
+	Outlet string            `json:"outlet"`
+	At     *time.Time        `json:"at"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// UpdateProductImageDTO changes an image's alt text.
+type UpdateProductImageDTO struct {
+	AltText string `json:"altText"`
+}
+
+// ReorderProductImagesDTO lists all of a product's images in their new order.
+// The first becomes the product's main image.
+type ReorderProductImagesDTO struct {
+	ImageIDs []uint `json:"imageIds" binding:"required,min=1"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
+
+type CreateIngredientDTO struct {
+	Name      string  `json:"name" binding:"required"`
+	SKU       string  `json:"sku" binding:"required"`
+	UnitID    *uint   `json:"unitId"`                // Pieces when omitted
+	Stock     int     `json:"stock" binding:"gte=0"` // Opening stock in the unit
+	CostPrice float64 `json:"costPrice" binding:"gte=0"`
+	MinStock  *int    `json:"minStock" binding:"omitempty,gte=0"`
+}
+
+type UpdateIngredientDTO struct {
+	Name     string `json:"name"`
+	SKU      string `json:"sku"`
+	UnitID   *uint  `json:"unitId"`   // 0 reverts to pieces; only while the ingredient has no stock
+	MinStock *int   `json:"minStock"` // Negative clears the threshold
+	IsActive *bool  `json:"isActive"`
+}
+
+type RecipeLineDTO struct {
+	IngredientID uint    `json:"ingredientId" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"` // Per sell unit of the item, in the ingredient's unit
+}
+
+// SetRecipeDTO replaces an item's recipe; an empty list removes it.
+type SetRecipeDTO struct {
+	Lines []RecipeLineDTO `json:"lines" binding:"dive"`
+}
+
+type RecipeDTO struct {
+	ItemType  string              `json:"itemType"`
+	ItemID    uint                `json:"itemId"`
+	Lines     []models.RecipeLine `json:"lines"`
+	Inherited bool                `json:"inherited"` // A variant using its product's recipe
+}
+
+type IngredientUsageQuery struct {
+	From         string `form:"from" binding:"required"` // YYYY-MM-DD, inclusive
+	To           string `form:"to" binding:"required"`   // YYYY-MM-DD, inclusive
+	IngredientID uint   `form:"ingredientId"`
+}
+
+type IngredientUsageReportDTO struct {
+	From            string                      `json:"from"`
+	To              string                      `json:"to"`
+	Ingredients     []inventory.IngredientUsage `json:"ingredients"`
+	TheoreticalCost float64                     `json:"theoreticalCost"`
+	ActualCost      float64                     `json:"actualCost"`
+	VarianceCost    float64                     `json:"varianceCost"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
+
+type RecordMovementDTO struct {
+	ItemType     string   `json:"itemType" binding:"required,oneof=product variant addon ingredient"`
+	ItemID       uint     `json:"itemId" binding:"required"`
+	MovementType string   `json:"movementType" binding:"required,oneof=adjustment receiving return waste"`
+	Quantity     int      `json:"quantity" binding:"required,ne=0"` // Signed for adjustments, a positive amount otherwise
+	Reason       string   `json:"reason" binding:"required"`
+	Reference    string   `json:"reference"`
+	UnitCost     *float64 `json:"unitCost" binding:"omitempty,gte=0"` // Cost of incoming stock; the current cost price when omitted
+	Outlet       string   `json:"outlet" binding:"max=100"`           // Outlet whose stock changes; only the total when omitted
+}
+
+type MovementHistoryQuery struct {
+	ItemType     string `form:"itemType" binding:"omitempty,oneof=product variant addon ingredient"`
+	ItemID       *uint  `form:"itemId"`
+	MovementType string `form:"movementType"`
+	Reference    string `form:"reference"`
+	Outlet       string `form:"outlet"`
+	From         string `form:"from"`
+	To           string `form:"to"`
+	Page         int    `form:"page,default=1" binding:"gte=1"`
+	PageSize     int    `form:"pageSize,default=50" binding:"gte=1,lte=200"`
+}
+
+type MovementListResponse struct {
+	Movements   []models.StockMovement `json:"movements"`
+	TotalCount  int64                  `json:"totalCount"`
+	CurrentPage int                    `json:"currentPage"`
+	PageSize    int                    `json:"pageSize"`
+}
+
+type StockLevelDTO struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+	Name     string `json:"name"`
+	SKU      string `json:"sku"`
+	OnHand   int    `json:"onHand"`  // Derived from the ledger
+	Balance  int    `json:"balance"` // Cached balance on the item
+	InSync   bool   `json:"inSync"`
+
+	Outlets    []inventory.OutletLevel `json:"outlets"`
+	Unassigned int                     `json:"unassigned"` // Balance not placed at any outlet
+}
+
+type ReserveStockDTO struct {
+	Key        string               `json:"key" binding:"required,max=100"` // Idempotency key, e.g. the order number
+	Reference  string               `json:"reference"`
+	Outlet     string               `json:"outlet" binding:"max=100"` // Outlet whose stock the sale leaves from
+	TTLSeconds int                  `json:"ttlSeconds" binding:"omitempty,gte=30,lte=86400"`
+	Held       bool                 `json:"held"` // Keep the hold until it is committed or released, e.g. for a saved order
+	Items      []ReservationItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReservationItemDTO struct {
+	ItemType string  `json:"itemType" binding:"required,oneof=product variant addon"`
+	ItemID   uint    `json:"itemId" binding:"required"`
+	Quantity float64 `json:"quantity" binding:"required,gt=0"` // In the sell unit; decimal for weighed items
+}
@@ -0,0 +1,27 @@
+package dto
+
+type GenerateBarcodesDTO struct {
+	ProductIDs []uint `json:"productIds"` // Limit generation to these products; all products when empty
+}
+
+type GeneratedBarcodeDTO struct {
+	VariantID uint   `json:"variantId"`
+	ProductID uint   `json:"productId"`
+	Name      string `json:"name"`
+	BarCode   string `json:"barCode"`
+}
+
+type BarcodeImageQuery struct {
+	Code      string `form:"code" binding:"required"`
+	Symbology string `form:"symbology,default=auto" binding:"oneof=auto ean13 code128"`
+	Format    string `form:"format,default=png" binding:"oneof=png svg"`
+	Width     int    `form:"width,default=300" binding:"gte=50,lte=2000"`
+	Height    int    `form:"height,default=100" binding:"gte=20,lte=1000"`
+}
+
+type LabelSheetDTO struct {
+	ProductIDs []uint `json:"productIds"`
+	VariantIDs []uint `json:"variantIds"`
+	Copies     int    `json:"copies" binding:"omitempty,gte=1,lte=100"`
+	Symbology  string `json:"symbology" binding:"omitempty,oneof=auto ean13 code128"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+type CreateModifierGroupDTO struct {
+	Name          string `json:"name" binding:"required"`
+	Description   string `json:"description"`
+	MinSelections int    `json:"minSelections" binding:"gte=0"`
+	MaxSelections int    `json:"maxSelections" binding:"gte=0"` // 0 means no limit
+	IsActive      *bool  `json:"isActive"`
+	AddonIDs      []uint `json:"addonIds" binding:"required,min=1"`
+}
+
+// UpdateModifierGroupDTO edits a group. AddonIDs, when given, replace all
+// addons in the group.
+type UpdateModifierGroupDTO struct {
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	MinSelections *int   `json:"minSelections" binding:"omitempty,gte=0"`
+	MaxSelections *int   `json:"maxSelections" binding:"omitempty,gte=0"`
+	IsActive      *bool  `json:"isActive"`
+	AddonIDs      []uint `json:"addonIds" binding:"omitempty,min=1"`
+}
+
+type ProductModifierGroupDTO struct {
+	ModifierGroupID uint `json:"modifierGroupId" binding:"required"`
+	MinSelections   *int `json:"minSelections" binding:"omitempty,gte=0"` // Overrides the group's limit for this product
+	MaxSelections   *int `json:"maxSelections" binding:"omitempty,gte=0"`
+}
+
+// AssignModifierGroupsDTO replaces the groups offered on a product, shown in
+// the given order. An empty list removes them all.
+type AssignModifierGroupsDTO struct {
+	ModifierGroups []ProductModifierGroupDTO `json:"modifierGroups" binding:"dive"`
+}
+
+type SelectedAddonDTO struct {
+	AddonID  uint `json:"addonId" binding:"required"`
+	Quantity int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// ValidateModifiersDTO holds the addons picked for one unit of a product, as
+// stored in a cart item's addonsData.
+type ValidateModifiersDTO struct {
+	ProductID uint               `json:"productId" binding:"required"`
+	Addons    []SelectedAddonDTO `json:"addons" binding:"dive"`
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
+
+type CreatePriceScheduleDTO struct {
+	ItemType string     `json:"itemType" binding:"required,oneof=product variant"`
+	ItemID   uint       `json:"itemId" binding:"required"`
+	Name     string     `json:"name"`
+	Price    float64    `json:"price" binding:"required,gt=0"`
+	StartsAt time.Time  `json:"startsAt" binding:"required"`
+	EndsAt   *time.Time `json:"endsAt"` // Open-ended when omitted
+}
+
+type UpdatePriceScheduleDTO struct {
+	Name     string     `json:"name"`
+	Price    float64    `json:"price" binding:"omitempty,gt=0"`
+	StartsAt *time.Time `json:"startsAt"`
+	EndsAt   *time.Time `json:"endsAt"`
+}
+
+type PriceScheduleQuery struct {
+	ItemType string `form:"itemType" binding:"omitempty,oneof=product variant"`
+	ItemID   *uint  `form:"itemId"`
+	Status   string `form:"status" binding:"omitempty,oneof=active upcoming expired"`
+}
+
+type PriceHistoryQuery struct {
+	ItemType string `form:"itemType" binding:"omitempty,oneof=product variant"`
+	ItemID   *uint  `form:"itemId"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Page     int    `form:"page,default=1" binding:"gte=1"`
+	PageSize int    `form:"pageSize,default=50" binding:"gte=1,lte=200"`
+}
+
+type PriceHistoryListResponse struct {
+	Changes     []models.PriceHistory `json:"changes"`
+	TotalCount  int64                 `json:"totalCount"`
+	CurrentPage int                   `json:"currentPage"`
+	PageSize    int                   `json:"pageSize"`
+}
+
+type PriceItemDTO struct {
+	ItemType string `json:"itemType" form:"itemType" binding:"required,oneof=product variant"`
+	ItemID   uint   `json:"itemId" form:"itemId" binding:"required"`
+}
+
+// ResolvePricesDTO asks for the prices of items at a time, now when At is
+// omitted.
+type ResolvePricesDTO struct {
+	Items []PriceItemDTO `json:"items" binding:"required,min=1,dive"`
+	At    *time.Time     `json:"at"`
+}
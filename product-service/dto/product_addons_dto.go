@@ -5,17 +5,22 @@ type CreateAddonDTO struct {
 	Description string  `json:"description"`
 	Price       float64 `json:"price" binding:"required,gt=0"`
 	SKU         string  `json:"sku" binding:"required"`
+	BarCode     string  `json:"barCode"`
 	ImageURL    string  `json:"imageUrl"`
 	Stock       int     `json:"stock" binding:"required,gte=0"`
+	CostPrice   float64 `json:"costPrice" binding:"gte=0"` // Unit cost of the opening stock
 	IsRequired  bool    `json:"isRequired"`
 	MaxQuantity int     `json:"maxQuantity"`
+	TaxClassID  *uint   `json:"taxClassId"` // Default tax class when omitted
 }
 
 type UpdateAddonDTO struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
+	BarCode     string  `json:"barCode"`
 	Price       float64 `json:"price" binding:"omitempty,gt=0"`
-	Stock       int     `json:"stock" binding:"omitempty,gte=0"`
+	Stock       *int    `json:"stock" binding:"omitempty,gte=0"`
 	IsRequired  *bool   `json:"isRequired"`
 	MaxQuantity *int    `json:"maxQuantity"`
+	TaxClassID  *uint   `json:"taxClassId"` // 0 reverts to the default tax class
 }
@@ -6,7 +6,6 @@ type CreateCategoryDTO struct {
 	Description string `json:"description"`
 	ImageURL    string `json:"imageUrl"`
 	ParentID    *uint  `json:"parentId"`
-	Level       int    `json:"level" binding:"required"`
 	IsActive    bool   `json:"isActive"`
 	SortOrder   int    `json:"sortOrder"`
 }
@@ -15,11 +14,38 @@ type UpdateCategoryDTO struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	ImageURL    string `json:"imageUrl"`
-	ParentID    *uint  `json:"parentId"`
+	ParentID    *uint  `json:"parentId"` // 0 moves the category to the root
 	IsActive    *bool  `json:"isActive"`
 	SortOrder   *int   `json:"sortOrder"`
 }
 
+// ReorderCategoriesDTO is the result of a drag and drop: CategoryIDs in their
+// new order under ParentID, or at the root when ParentID is omitted. Categories
+// dropped in from another parent are moved.
+type ReorderCategoriesDTO struct {
+	ParentID    *uint  `json:"parentId"`
+	CategoryIDs []uint `json:"categoryIds" binding:"required,min=1"`
+}
+
+type CategoryTreeQuery struct {
+	IncludeInactive bool `form:"includeInactive"`
+}
+
+type CategoryTreeDTO struct {
+	ID                uint              `json:"id"`
+	Name              string            `json:"name"`
+	Slug              string            `json:"slug"`
+	ImageURL          string            `json:"imageUrl"`
+	ParentID          *uint             `json:"parentId"`
+	Level             int               `json:"level"`
+	Path              string            `json:"path"`
+	SortOrder         int               `json:"sortOrder"`
+	IsActive          bool              `json:"isActive"`
+	ProductCount      int               `json:"productCount"`      // Products directly in this category
+	TotalProductCount int               `json:"totalProductCount"` // Including all subcategories
+	Children          []CategoryTreeDTO `json:"children"`
+}
+
 type CategoryListDTO struct {
 	ID          uint   `json:"id"`
 	Name        string `json:"name"`
@@ -3,6 +3,7 @@ package dto
 import (
 	"time"
 
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
 	"github.com/ridhotamma/yourkasa/product-service/models"
 )
 
@@ -12,15 +13,24 @@ type CreateProductDTO struct {
 	Description      string  `json:"description"`
 	Price            float64 `json:"price" binding:"required,gt=0"`
 	Stock            int     `json:"stock" binding:"required,gte=0"`
+	CostPrice        float64 `json:"costPrice" binding:"gte=0"` // Unit cost of the opening stock
+	MinStock         *int    `json:"minStock" binding:"omitempty,gte=0"`
+	ReorderQuantity  int     `json:"reorderQuantity" binding:"gte=0"`
 	CategoryID       uint    `json:"categoryId" binding:"required"`
 	GroupID          *uint   `json:"groupId"`
 	SKU              string  `json:"sku" binding:"required"`
+	BarCode          string  `json:"barCode"`
 	ImageURL         string  `json:"imageUrl"`
 	Weight           float64 `json:"weight"`
 	Dimensions       string  `json:"dimensions"`
 	Tags             string  `json:"tags"`
 	Status           string  `json:"status" binding:"required,oneof=active inactive discontinued"`
 	AddonIDs         []uint  `json:"addonIds"`
+	TaxClassID       *uint   `json:"taxClassId"`     // Default tax class when omitted
+	StockUnitID      *uint   `json:"stockUnitId"`    // Pieces when omitted
+	SellUnitID       *uint   `json:"sellUnitId"`     // Unit Price is per; the stock unit when omitted
+	PurchaseUnitID   *uint   `json:"purchaseUnitId"` // The stock unit when omitted
+	Weighed          bool    `json:"weighed"`        // Sold in decimal quantities of the sell unit
 }
 
 type UpdateProductDTO struct {
@@ -28,56 +38,87 @@ type UpdateProductDTO struct {
 	ShortDescription string  `json:"shortDescription"`
 	Description      string  `json:"description"`
 	Price            float64 `json:"price" binding:"omitempty,gt=0"`
-	Stock            int     `json:"stock" binding:"omitempty,gte=0"`
+	Stock            *int    `json:"stock" binding:"omitempty,gte=0"` // Differences are posted to the ledger as an adjustment
+	MinStock         *int    `json:"minStock" binding:"omitempty,gte=0"`
+	ReorderQuantity  *int    `json:"reorderQuantity" binding:"omitempty,gte=0"`
 	CategoryID       *uint   `json:"categoryId"`
 	GroupID          *uint   `json:"groupId"`
+	BarCode          string  `json:"barCode"`
 	ImageURL         string  `json:"imageUrl"`
 	Weight           float64 `json:"weight"`
 	Dimensions       string  `json:"dimensions"`
 	Tags             string  `json:"tags"`
 	Status           string  `json:"status" binding:"omitempty,oneof=active inactive discontinued"`
 	AddonIDs         []uint  `json:"addonIds"`
+	TaxClassID       *uint   `json:"taxClassId"`     // 0 reverts to the default tax class
+	StockUnitID      *uint   `json:"stockUnitId"`    // 0 reverts to pieces; only while the product has no stock
+	SellUnitID       *uint   `json:"sellUnitId"`     // 0 reverts to the stock unit
+	PurchaseUnitID   *uint   `json:"purchaseUnitId"` // 0 reverts to the stock unit
+	Weighed          *bool   `json:"weighed"`
 }
 
 type ProductListDTO struct {
-	ID               uint    `json:"id"`
-	Name             string  `json:"name"`
-	ShortDescription string  `json:"shortDescription"`
-	Price            float64 `json:"price"`
-	Stock            int     `json:"stock"`
-	CategoryID       uint    `json:"categoryId"`
-	CategoryName     string  `json:"categoryName"`
-	SKU              string  `json:"sku"`
-	Status           string  `json:"status"`
-	ImageURL         string  `json:"imageUrl"`
-	VariantCount     int     `json:"variantCount"`
+	ID               uint             `json:"id"`
+	Name             string           `json:"name"`
+	ShortDescription string           `json:"shortDescription"`
+	Price            float64          `json:"price"`
+	EffectivePrice   float64          `json:"effectivePrice"` // Price with any scheduled price applied
+	Stock            int              `json:"stock"`
+	CategoryID       uint             `json:"categoryId"`
+	CategoryName     string           `json:"categoryName"`
+	SKU              string           `json:"sku"`
+	Status           string           `json:"status"`
+	ImageURL         string           `json:"imageUrl"`
+	ThumbnailURL     string           `json:"thumbnailUrl"`
+	VariantCount     int              `json:"variantCount"`
+	TaxClassID       *uint            `json:"taxClassId"`
+	TaxClass         *models.TaxClass `json:"taxClass"` // Own or default tax class, nil when untaxed
+	Unit             string           `json:"unit"`     // Unit the price is per, e.g. kg
+	Weighed          bool             `json:"weighed"`
 }
 
 type ProductDetailDTO struct {
-	ID               uint                    `json:"id"`
-	Name             string                  `json:"name"`
-	ShortDescription string                  `json:"shortDescription"`
-	Description      string                  `json:"description"`
-	Price            float64                 `json:"price"`
-	Stock            int                     `json:"stock"`
-	CategoryID       uint                    `json:"categoryId"`
-	Category         models.ProductCategory  `json:"category"`
-	GroupID          *uint                   `json:"groupId"`
-	Group            *models.ProductGroup    `json:"group,omitempty"`
-	SKU              string                  `json:"sku"`
-	ImageURL         string                  `json:"imageUrl"`
-	Weight           float64                 `json:"weight"`
-	Dimensions       string                  `json:"dimensions"`
-	Tags             string                  `json:"tags"`
-	Status           string                  `json:"status"`
-	Variants         []models.ProductVariant `json:"variants"`
-	Addons           []models.ProductAddon   `json:"addons"`
-	CreatedAt        time.Time               `json:"createdAt"`
-	UpdatedAt        time.Time               `json:"updatedAt"`
-	LastSoldAt       *time.Time              `json:"lastSoldAt"`
+	ID               uint                         `json:"id"`
+	Name             string                       `json:"name"`
+	ShortDescription string                       `json:"shortDescription"`
+	Description      string                       `json:"description"`
+	Price            float64                      `json:"price"`
+	EffectivePrice   float64                      `json:"effectivePrice"`
+	PriceSchedule    *models.PriceSchedule        `json:"priceSchedule,omitempty"` // Schedule in force, if any
+	Stock            int                          `json:"stock"`
+	MinStock         *int                         `json:"minStock"`
+	ReorderQuantity  int                          `json:"reorderQuantity"`
+	CategoryID       uint                         `json:"categoryId"`
+	Category         models.ProductCategory       `json:"category"`
+	GroupID          *uint                        `json:"groupId"`
+	Group            *models.ProductGroup         `json:"group,omitempty"`
+	SKU              string                       `json:"sku"`
+	BarCode          string                       `json:"barCode"`
+	ImageURL         string                       `json:"imageUrl"`
+	ThumbnailURL     string                       `json:"thumbnailUrl"`
+	Images           []models.ProductImage        `json:"images"`
+	Weight           float64                      `json:"weight"`
+	Dimensions       string                       `json:"dimensions"`
+	Tags             string                       `json:"tags"`
+	Status           string                       `json:"status"`
+	Options          []models.ProductOption       `json:"options"`
+	Variants         []PricedVariantDTO           `json:"variants"`
+	Addons           []models.ProductAddon        `json:"addons"`
+	ModifierGroups   []catalog.ModifierGroupRules `json:"modifierGroups"`
+	TaxClassID       *uint                        `json:"taxClassId"`
+	TaxClass         *models.TaxClass             `json:"taxClass"` // Own or default tax class, nil when untaxed
+	Units            *catalog.ItemUnits           `json:"units"`
+	CreatedAt        time.Time                    `json:"createdAt"`
+	UpdatedAt        time.Time                    `json:"updatedAt"`
+	LastSoldAt       *time.Time                   `json:"lastSoldAt"`
 }
 
 // Additional DTOs for nested responses
+type PricedVariantDTO struct {
+	models.ProductVariant
+	EffectivePrice float64 `json:"effectivePrice"`
+}
+
 type ProductVariantDTO struct {
 	ID         uint    `json:"id"`
 	Name       string  `json:"name"`
@@ -97,6 +138,7 @@ type ProductAddonDTO struct {
 	ID          uint    `json:"id"`
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
+	BarCode     string  `json:"barCode"`
 	Price       float64 `json:"price"`
 	Stock       int     `json:"stock"`
 	IsRequired  bool    `json:"isRequired"`
@@ -106,17 +148,20 @@ type ProductAddonDTO struct {
 
 // Search and filter DTOs
 type ProductSearchParams struct {
-	CategoryID *uint   `form:"categoryId"`
-	GroupID    *uint   `form:"groupId"`
-	Status     string  `form:"status"`
-	MinPrice   float64 `form:"minPrice"`
-	MaxPrice   float64 `form:"maxPrice"`
-	InStock    *bool   `form:"inStock"`
-	Query      string  `form:"q"`
-	SortBy     string  `form:"sortBy"`
-	SortOrder  string  `form:"sortOrder"`
-	Page       int     `form:"page,default=1"`
-	PageSize   int     `form:"pageSize,default=20"`
+	CategoryID           *uint   `form:"categoryId"`
+	IncludeSubcategories bool    `form:"includeSubcategories"` // Also match products in categories below CategoryID
+	GroupID              *uint   `form:"groupId"`
+	Status               string  `form:"status" binding:"omitempty,oneof=active inactive discontinued"`
+	MinPrice             float64 `form:"minPrice" binding:"gte=0"`
+	MaxPrice             float64 `form:"maxPrice" binding:"gte=0"`
+	InStock              *bool   `form:"inStock"`
+	AvailableNow         bool    `form:"availableNow"` // Only products that can be sold now under availability rules
+	Outlet               string  `form:"outlet"`       // Outlet whose availability rules apply
+	Query                string  `form:"q"`
+	SortBy               string  `form:"sortBy" binding:"omitempty,oneof=name price stock created lastSold"`
+	SortOrder            string  `form:"sortOrder" binding:"omitempty,oneof=asc desc"`
+	Page                 int     `form:"page,default=1" binding:"gte=1"`
+	PageSize             int     `form:"pageSize,default=20" binding:"gte=1,lte=100"`
 }
 
 type ProductListResponse struct {
@@ -126,3 +171,54 @@ type ProductListResponse struct {
 	CurrentPage int              `json:"currentPage"`
 	PageSize    int              `json:"pageSize"`
 }
+
+type ProductSearchQuery struct {
+	Query                string `form:"q" binding:"required"`
+	CategoryID           *uint  `form:"categoryId"`
+	IncludeSubcategories bool   `form:"includeSubcategories"`
+	IncludeInactive      bool   `form:"includeInactive"`
+	Limit                int    `form:"limit,default=20" binding:"gte=1,lte=100"`
+}
+
+type ProductSearchResultDTO struct {
+	ID             uint                     `json:"id"`
+	Name           string                   `json:"name"`
+	SKU            string                   `json:"sku"`
+	Price          float64                  `json:"price"`
+	EffectivePrice float64                  `json:"effectivePrice"`
+	Stock          int                      `json:"stock"`
+	Status         string                   `json:"status"`
+	ImageURL       string                   `json:"imageUrl"`
+	CategoryID     uint                     `json:"categoryId"`
+	Rank           float64                  `json:"rank"`
+	Snippet        string                   `json:"snippet"`
+	MatchedVariant *ProductSearchVariantDTO `json:"matchedVariant,omitempty"`
+}
+
+type ProductSearchVariantDTO struct {
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	SKU            string  `json:"sku"`
+	Price          float64 `json:"price"`
+	EffectivePrice float64 `json:"effectivePrice"`
+}
+
+type ScanLookupDTO struct {
+	Code           string   `json:"code"`
+	Type           string   `json:"type"` // product, variant or addon
+	ID             uint     `json:"id"`
+	ProductID      *uint    `json:"productId"`
+	Name           string   `json:"name"`
+	ProductName    string   `json:"productName,omitempty"`
+	SKU            string   `json:"sku"`
+	BarCode        string   `json:"barCode"`
+	Status         string   `json:"status"`
+	Stock          int      `json:"stock"`
+	ImageURL       string   `json:"imageUrl"`
+	UnitPrice      float64  `json:"unitPrice"`
+	Quantity       float64  `json:"quantity"`
+	EffectivePrice float64  `json:"effectivePrice"`
+	Weighted       bool     `json:"weighted"`
+	EmbeddedPrice  *float64 `json:"embeddedPrice,omitempty"`
+	WeightKg       *float64 `json:"weightKg,omitempty"`
+}
@@ -1,6 +1,11 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/catalog"
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
 
 type CreateGroupDTO struct {
 	Name        string     `json:"name" binding:"required"`
@@ -11,6 +16,10 @@ type CreateGroupDTO struct {
 	EndDate     *time.Time `json:"endDate"`
 	GroupType   string     `json:"groupType" binding:"required"`
 	SortOrder   int        `json:"sortOrder"`
+
+	BundlePrice     *float64 `json:"bundlePrice" binding:"omitempty,gt=0"` // Fixed bundle price; DiscountPercent applies when omitted
+	DiscountPercent float64  `json:"discountPercent" binding:"gte=0,lte=100"`
+	TaxClassID      *uint    `json:"taxClassId"` // Tax on bundle sales; default tax class when omitted
 }
 
 type UpdateGroupDTO struct {
@@ -22,4 +31,42 @@ type UpdateGroupDTO struct {
 	EndDate     *time.Time `json:"endDate"`
 	GroupType   string     `json:"groupType"`
 	SortOrder   *int       `json:"sortOrder"`
+
+	BundlePrice     *float64 `json:"bundlePrice" binding:"omitempty,gte=0"` // 0 removes the fixed price so DiscountPercent applies
+	DiscountPercent *float64 `json:"discountPercent" binding:"omitempty,gte=0,lte=100"`
+	TaxClassID      *uint    `json:"taxClassId"` // 0 reverts to the default tax class
+}
+
+type BundleChoiceDTO struct {
+	ItemType        string  `json:"itemType" binding:"required,oneof=product variant"`
+	ItemID          uint    `json:"itemId" binding:"required"`
+	PriceAdjustment float64 `json:"priceAdjustment"` // Charged on top of the bundle price when chosen
+}
+
+type BundleComponentDTO struct {
+	ItemType string            `json:"itemType" binding:"required,oneof=product variant"`
+	ItemID   uint              `json:"itemId" binding:"required"`
+	Quantity int               `json:"quantity" binding:"required,gt=0"`
+	Choices  []BundleChoiceDTO `json:"choices" binding:"dive"`
+}
+
+// SetBundleComponentsDTO replaces a bundle's components, kept in the given
+// order.
+type SetBundleComponentsDTO struct {
+	Components []BundleComponentDTO `json:"components" binding:"required,min=1,dive"`
+}
+
+type BundleSwapDTO struct {
+	ComponentID uint `json:"componentId" binding:"required"`
+	ChoiceID    uint `json:"choiceId" binding:"required"`
+}
+
+type QuoteBundleDTO struct {
+	GroupID uint            `json:"groupId" binding:"required"`
+	Swaps   []BundleSwapDTO `json:"swaps" binding:"dive"`
+}
+
+type BundleDetailDTO struct {
+	Bundle models.ProductGroup `json:"bundle"`
+	Quote  catalog.BundleQuote `json:"quote"` // Price and stock with the default components
 }
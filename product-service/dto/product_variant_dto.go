@@ -2,24 +2,51 @@ package dto
 
 // dto/variant_dto.go
 type CreateVariantDTO struct {
-	ProductID  uint    `json:"productId" binding:"required"`
-	Name       string  `json:"name" binding:"required"`
-	SKU        string  `json:"sku" binding:"required"`
-	Price      float64 `json:"price" binding:"required,gt=0"`
-	Stock      int     `json:"stock" binding:"required,gte=0"`
-	ImageURL   string  `json:"imageUrl"`
-	Attributes string  `json:"attributes"`
-	IsDefault  bool    `json:"isDefault"`
-	Weight     float64 `json:"weight"`
-	Dimensions string  `json:"dimensions"`
-	BarCode    string  `json:"barCode"`
+	ProductID       uint    `json:"productId" binding:"required"`
+	Name            string  `json:"name" binding:"required"`
+	SKU             string  `json:"sku" binding:"required"`
+	Price           float64 `json:"price" binding:"required,gt=0"`
+	Stock           int     `json:"stock" binding:"required,gte=0"`
+	CostPrice       float64 `json:"costPrice" binding:"gte=0"` // Unit cost of the opening stock
+	MinStock        *int    `json:"minStock" binding:"omitempty,gte=0"`
+	ReorderQuantity int     `json:"reorderQuantity" binding:"gte=0"`
+	ImageURL        string  `json:"imageUrl"`
+	Attributes      string  `json:"attributes"`
+	IsDefault       bool    `json:"isDefault"`
+	Weight          float64 `json:"weight"`
+	Dimensions      string  `json:"dimensions"`
+	BarCode         string  `json:"barCode"`
 }
 
 type UpdateVariantDTO struct {
-	Name       string  `json:"name"`
-	Price      float64 `json:"price" binding:"omitempty,gt=0"`
-	Stock      int     `json:"stock" binding:"omitempty,gte=0"`
-	ImageURL   string  `json:"imageUrl"`
-	Attributes string  `json:"attributes"`
-	IsDefault  *bool   `json:"isDefault"`
+	Name            string  `json:"name"`
+	Price           float64 `json:"price" binding:"omitempty,gt=0"`
+	Stock           *int    `json:"stock" binding:"omitempty,gte=0"`
+	MinStock        *int    `json:"minStock" binding:"omitempty,gte=0"`
+	ReorderQuantity *int    `json:"reorderQuantity" binding:"omitempty,gte=0"`
+	ImageURL        string  `json:"imageUrl"`
+	Attributes      string  `json:"attributes"`
+	IsDefault       *bool   `json:"isDefault"`
+	BarCode         string  `json:"barCode"`
+}
+
+type ProductOptionValueDTO struct {
+	Value           string  `json:"value" binding:"required"`
+	SKUCode         string  `json:"skuCode" binding:"omitempty,max=20"` // Derived from the value when empty
+	PriceAdjustment float64 `json:"priceAdjustment"`
+}
+
+type ProductOptionDTO struct {
+	Name   string                  `json:"name" binding:"required"`
+	Values []ProductOptionValueDTO `json:"values" binding:"required,min=1,dive"`
+}
+
+// SetProductOptionsDTO replaces a product's options, kept in the given order.
+// An empty list removes them all.
+type SetProductOptionsDTO struct {
+	Options []ProductOptionDTO `json:"options" binding:"dive"`
+}
+
+type GenerateVariantsDTO struct {
+	Stock int `json:"stock" binding:"gte=0"` // Opening stock of each new variant
 }
@@ -0,0 +1,65 @@
+package dto
+
+import "time"
+
+type CreateSupplierDTO struct {
+	Name         string `json:"name" binding:"required"`
+	ContactName  string `json:"contactName"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	TaxNumber    string `json:"taxNumber"`
+	PaymentTerms string `json:"paymentTerms"`
+	Notes        string `json:"notes"`
+}
+
+type UpdateSupplierDTO struct {
+	Name         string `json:"name"`
+	ContactName  string `json:"contactName"`
+	Email        string `json:"email" binding:"omitempty,email"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address"`
+	TaxNumber    string `json:"taxNumber"`
+	PaymentTerms string `json:"paymentTerms"`
+	Notes        string `json:"notes"`
+	IsActive     *bool  `json:"isActive"`
+}
+
+type PurchaseOrderItemDTO struct {
+	ProductID uint    `json:"productId" binding:"required"`
+	VariantID *uint   `json:"variantId"` // Required when the product has variants
+	Quantity  int     `json:"quantity" binding:"required,gt=0"`
+	UnitCost  float64 `json:"unitCost" binding:"gte=0"`
+}
+
+type CreatePurchaseOrderDTO struct {
+	SupplierID uint                   `json:"supplierId" binding:"required"`
+	ExpectedAt *time.Time             `json:"expectedAt"`
+	Notes      string                 `json:"notes"`
+	Items      []PurchaseOrderItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdatePurchaseOrderDTO edits a draft. Items, when given, replace all lines.
+type UpdatePurchaseOrderDTO struct {
+	SupplierID *uint                  `json:"supplierId"`
+	ExpectedAt *time.Time             `json:"expectedAt"`
+	Notes      string                 `json:"notes"`
+	Items      []PurchaseOrderItemDTO `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+type PurchaseOrderQuery struct {
+	Status     string `form:"status" binding:"omitempty,oneof=draft sent partially_received received closed"`
+	SupplierID *uint  `form:"supplierId"`
+}
+
+type ReceiveItemDTO struct {
+	ItemID   uint     `json:"itemId" binding:"required"` // Purchase order line ID
+	Quantity int      `json:"quantity" binding:"required,gt=0"`
+	UnitCost *float64 `json:"unitCost" binding:"omitempty,gte=0"` // Invoiced cost when it differs from the order
+}
+
+type ReceivePurchaseOrderDTO struct {
+	Items  []ReceiveItemDTO `json:"items" binding:"required,min=1,dive"`
+	Outlet string           `json:"outlet" binding:"max=100"` // Outlet the goods were delivered to
+	Notes  string           `json:"notes"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+import "github.com/ridhotamma/yourkasa/product-service/models"
+
+type StartStockTakeDTO struct {
+	Outlet     string `json:"outlet"`
+	CategoryID *uint  `json:"categoryId"` // Count only this category and its subcategories; the whole outlet when omitted
+	Notes      string `json:"notes"`
+}
+
+// StockCountDTO records a count for one line, found by line ID, item or a
+// scanned barcode or SKU. With mode "add" the quantity is added to what was
+// counted so far, which suits scanning items one by one.
+type StockCountDTO struct {
+	LineID   *uint  `json:"lineId"`
+	ItemType string `json:"itemType" binding:"omitempty,oneof=product variant addon ingredient"`
+	ItemID   *uint  `json:"itemId"`
+	Code     string `json:"code"`
+	Quantity int    `json:"quantity" binding:"gte=0"`
+	Mode     string `json:"mode" binding:"omitempty,oneof=set add"`
+}
+
+type RecordCountsDTO struct {
+	Counts []StockCountDTO `json:"counts" binding:"required,min=1,dive"`
+}
+
+type ApproveStockTakeDTO struct {
+	ZeroUncounted bool `json:"zeroUncounted"` // Treat items nobody counted as counted zero
+}
+
+type StockTakeLineQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=counted uncounted variance"`
+}
+
+type StockTakeVarianceDTO struct {
+	StockTake      models.StockTake       `json:"stockTake"`
+	TotalLines     int                    `json:"totalLines"`
+	CountedLines   int                    `json:"countedLines"`
+	UncountedLines int                    `json:"uncountedLines"`
+	VarianceLines  int                    `json:"varianceLines"`
+	VarianceValue  float64                `json:"varianceValue"`
+	Lines          []models.StockTakeLine `json:"lines"`
+}
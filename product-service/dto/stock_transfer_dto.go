@@ -0,0 +1,56 @@
+package dto
+
+type StockTransferItemDTO struct {
+	ItemType string `json:"itemType" binding:"required,oneof=product variant addon"`
+	ItemID   uint   `json:"itemId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+}
+
+type CreateStockTransferDTO struct {
+	SourceOutlet      string                 `json:"sourceOutlet" binding:"required,max=100"`
+	DestinationOutlet string                 `json:"destinationOutlet" binding:"required,max=100,nefield=SourceOutlet"`
+	Notes             string                 `json:"notes"`
+	Items             []StockTransferItemDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+// UpdateStockTransferDTO edits a draft. Items, when given, replace all lines.
+type UpdateStockTransferDTO struct {
+	SourceOutlet      string                 `json:"sourceOutlet" binding:"max=100"`
+	DestinationOutlet string                 `json:"destinationOutlet" binding:"max=100"`
+	Notes             string                 `json:"notes"`
+	Items             []StockTransferItemDTO `json:"items" binding:"omitempty,min=1,dive"`
+}
+
+type StockTransferQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=draft in_transit received cancelled"`
+	Outlet string `form:"outlet"` // Either end of the transfer
+}
+
+type ReceiveTransferItemDTO struct {
+	ItemID           uint   `json:"itemId" binding:"required"` // Transfer line ID
+	ReceivedQuantity *int   `json:"receivedQuantity" binding:"required,gte=0"`
+	Reason           string `json:"reason"` // Why the quantity differs, e.g. damaged in transit
+}
+
+// ReceiveStockTransferDTO records what arrived. Lines left out are taken as
+// received in full. A shortage is written off as lost in transit unless
+// ReturnShortage books it back to the source outlet.
+type ReceiveStockTransferDTO struct {
+	Items          []ReceiveTransferItemDTO `json:"items" binding:"omitempty,dive"`
+	ReturnShortage bool                     `json:"returnShortage"`
+}
+
+// AssignOutletStockDTO places unassigned stock at an outlet, or returns it
+// when Quantity is negative. The item's total stock does not change.
+type AssignOutletStockDTO struct {
+	Outlet   string `json:"outlet" binding:"required,max=100"`
+	ItemType string `json:"itemType" binding:"required,oneof=product variant addon"`
+	ItemID   uint   `json:"itemId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,ne=0"`
+}
+
+type OutletStockQuery struct {
+	Outlet   string `form:"outlet"`
+	ItemType string `form:"itemType" binding:"omitempty,oneof=product variant addon"`
+	ItemID   *uint  `form:"itemId"`
+}
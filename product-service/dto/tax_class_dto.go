@@ -0,0 +1,26 @@
+package dto
+
+type CreateTaxClassDTO struct {
+	Code      string  `json:"code" binding:"required,max=20"`
+	Name      string  `json:"name" binding:"required"`
+	Rate      float64 `json:"rate" binding:"gte=0,lte=100"` // Percent
+	Inclusive bool    `json:"inclusive"`                    // Prices already contain the tax
+	IsDefault bool    `json:"isDefault"`
+}
+
+type UpdateTaxClassDTO struct {
+	Name      string   `json:"name"`
+	Rate      *float64 `json:"rate" binding:"omitempty,gte=0,lte=100"`
+	Inclusive *bool    `json:"inclusive"`
+	IsDefault *bool    `json:"isDefault"`
+}
+
+type TaxItemDTO struct {
+	ItemType string `json:"itemType" binding:"required,oneof=product variant addon group"`
+	ItemID   uint   `json:"itemId" binding:"required"`
+}
+
+// ResolveTaxesDTO asks for the tax classes items are sold under.
+type ResolveTaxesDTO struct {
+	Items []TaxItemDTO `json:"items" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+type CreateUnitDTO struct {
+	Code      string  `json:"code" binding:"required,max=20"`
+	Name      string  `json:"name" binding:"required"`
+	Dimension string  `json:"dimension" binding:"required,oneof=count weight volume"`
+	Factor    float64 `json:"factor" binding:"required,gt=0"` // Pieces, grams or millilitres in one unit
+}
+
+// UpdateUnitDTO renames a unit. Its dimension and factor cannot change since
+// stock and purchase orders were recorded in it.
+type UpdateUnitDTO struct {
+	Name string `json:"name" binding:"required"`
+}
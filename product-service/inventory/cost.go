@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"math"
+	"os"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CostingAverage = "average"
+	CostingFIFO    = "fifo"
+)
+
+// CostingMethod returns the method selected by COSTING_METHOD: "average"
+// (weighted average, the default) or "fifo". Cost layers are kept under both
+// methods so switching does not lose history.
+func CostingMethod() string {
+	if strings.ToLower(os.Getenv("COSTING_METHOD")) == CostingFIFO {
+		return CostingFIFO
+	}
+	return CostingAverage
+}
+
+// Receive posts received stock to the ledger at unitCost, which feeds the
+// item's cost price.
+func Receive(tx *gorm.DB, m Movement, unitCost float64) (*models.StockMovement, error) {
+	m.MovementType = models.MovementReceiving
+	m.UnitCost = &unitCost
+	return Post(tx, m)
+}
+
+// averageCost folds incoming stock into a weighted average cost. Stock below
+// zero before the receipt is treated as zero so oversold items take the new
+// cost.
+func averageCost(stock int, costPrice float64, quantity int, unitCost float64) float64 {
+	if stock < 0 {
+		stock = 0
+	}
+	return (float64(stock)*costPrice + float64(quantity)*unitCost) / float64(stock+quantity)
+}
+
+// addLayer records incoming stock as a cost layer. Units that only cover an
+// oversold, negative balance are already gone and do not form a layer.
+func addLayer(tx *gorm.DB, movement models.StockMovement, stockBefore int) error {
+	remaining := movement.Quantity
+	if stockBefore < 0 {
+		remaining += stockBefore
+	}
+	if remaining <= 0 {
+		return nil
+	}
+
+	return tx.Create(&models.CostLayer{
+		ItemType:   movement.ItemType,
+		ItemID:     movement.ItemID,
+		MovementID: movement.ID,
+		Quantity:   movement.Quantity,
+		Remaining:  remaining,
+		UnitCost:   movement.UnitCost,
+	}).Error
+}
+
+// consumeLayers takes quantity from the oldest cost layers and returns the
+// cost of what was taken. Anything beyond the remaining layers is valued at
+// fallback.
+func consumeLayers(tx *gorm.DB, itemType string, itemID uint, quantity int, fallback float64) (float64, error) {
+	var layers []models.CostLayer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("item_type = ? AND item_id = ? AND remaining > 0", itemType, itemID).
+		Order("id asc").
+		Find(&layers).Error; err != nil {
+		return 0, err
+	}
+
+	var value float64
+	for _, layer := range layers {
+		if quantity == 0 {
+			break
+		}
+
+		taken := layer.Remaining
+		if taken > quantity {
+			taken = quantity
+		}
+		if err := tx.Model(&layer).Update("remaining", layer.Remaining-taken).Error; err != nil {
+			return 0, err
+		}
+
+		value += float64(taken) * layer.UnitCost
+		quantity -= taken
+	}
+
+	return value + float64(quantity)*fallback, nil
+}
+
+// refreshLayerCost sets the cost price to the average cost of the layers still
+// in stock. It is left unchanged when nothing remains.
+func refreshLayerCost(tx *gorm.DB, table, itemType string, itemID uint) error {
+	var layer struct {
+		Quantity int
+		Value    float64
+	}
+	if err := tx.Model(&models.CostLayer{}).
+		Select("coalesce(sum(remaining), 0) AS quantity, coalesce(sum(remaining * unit_cost), 0) AS value").
+		Where("item_type = ? AND item_id = ? AND remaining > 0", itemType, itemID).
+		Scan(&layer).Error; err != nil {
+		return err
+	}
+	if layer.Quantity == 0 {
+		return nil
+	}
+
+	return tx.Table(table).Where("id = ?", itemID).
+		Update("cost_price", roundCost(layer.Value/float64(layer.Quantity), 2)).Error
+}
+
+func roundCost(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,246 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrItemNotFound        = errors.New("item not found")
+	ErrInvalidItemType     = errors.New("invalid item type")
+	ErrInvalidMovementType = errors.New("invalid movement type")
+	ErrInvalidQuantity     = errors.New("invalid quantity")
+)
+
+// Movement describes a stock change to post to the ledger.
+type Movement struct {
+	ItemType      string
+	ItemID        uint
+	MovementType  string
+	Quantity      int // Signed change in stock
+	Reason        string
+	Reference     string
+	UserID        *uint
+	Outlet        string   // Also moves the outlet's own stock when set
+	UnitCost      *float64 // Cost of incoming stock; defaults to the item's current cost price
+	AllowNegative bool     // Let the balance drop below zero, e.g. for sales already handed over
+}
+
+// Post records a movement and updates the item's cached stock balance and cost
+// price, and the outlet's stock when the movement is at an outlet. It locks the
+// item row, so call it inside a transaction.
+func Post(tx *gorm.DB, m Movement) (*models.StockMovement, error) {
+	table, err := itemTable(m.ItemType)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDirection(m.MovementType, m.Quantity); err != nil {
+		return nil, err
+	}
+
+	var item struct {
+		ID        uint
+		Stock     int
+		CostPrice float64
+	}
+	result := tx.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, stock, cost_price").
+		Where("id = ? AND deleted_at IS NULL", m.ItemID).
+		Scan(&item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	balance := item.Stock + m.Quantity
+	if balance < 0 && m.Quantity < 0 && !m.AllowNegative {
+		return nil, fmt.Errorf("%w: %s %d has %d on hand", ErrInsufficientStock, m.ItemType, m.ItemID, item.Stock)
+	}
+
+	unitCost, costPrice := item.CostPrice, item.CostPrice
+	if m.Quantity > 0 {
+		if m.UnitCost != nil {
+			unitCost = *m.UnitCost
+		}
+		if CostingMethod() == CostingAverage {
+			costPrice = averageCost(item.Stock, item.CostPrice, m.Quantity, unitCost)
+		}
+	} else {
+		value, err := consumeLayers(tx, m.ItemType, m.ItemID, -m.Quantity, item.CostPrice)
+		if err != nil {
+			return nil, err
+		}
+		if CostingMethod() == CostingFIFO {
+			unitCost = value / float64(-m.Quantity)
+		}
+	}
+
+	if m.Outlet != "" {
+		if err := moveOutletStock(tx, m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Table(table).Where("id = ?", m.ItemID).Updates(map[string]interface{}{
+		"stock":      balance,
+		"cost_price": roundCost(costPrice, 2),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	movement := models.StockMovement{
+		ItemType:     m.ItemType,
+		ItemID:       m.ItemID,
+		MovementType: m.MovementType,
+		Quantity:     m.Quantity,
+		BalanceAfter: balance,
+		UnitCost:     roundCost(unitCost, 4),
+		Reason:       m.Reason,
+		Reference:    m.Reference,
+		UserID:       m.UserID,
+		Outlet:       m.Outlet,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	if m.Quantity > 0 {
+		if err := addLayer(tx, movement, item.Stock); err != nil {
+			return nil, err
+		}
+	}
+	if CostingMethod() == CostingFIFO {
+		if err := refreshLayerCost(tx, table, m.ItemType, m.ItemID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &movement, nil
+}
+
+// SetCount posts an adjustment that brings an item's balance to count and
+// returns nil when the balance already matches.
+func SetCount(tx *gorm.DB, itemType string, itemID uint, count int, reason, reference string, userID *uint) (*models.StockMovement, error) {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return nil, err
+	}
+
+	var item struct {
+		ID    uint
+		Stock int
+	}
+	result := tx.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, stock").
+		Where("id = ? AND deleted_at IS NULL", itemID).
+		Scan(&item)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	if count == item.Stock {
+		return nil, nil
+	}
+
+	return Post(tx, Movement{
+		ItemType:      itemType,
+		ItemID:        itemID,
+		MovementType:  models.MovementAdjustment,
+		Quantity:      count - item.Stock,
+		Reason:        reason,
+		Reference:     reference,
+		UserID:        userID,
+		AllowNegative: true,
+	})
+}
+
+// OnHand derives the quantity on hand of an item from its ledger.
+func OnHand(db *gorm.DB, itemType string, itemID uint) (int, error) {
+	var total int
+	err := db.Model(&models.StockMovement{}).
+		Select("coalesce(sum(quantity), 0)").
+		Where("item_type = ? AND item_id = ?", itemType, itemID).
+		Scan(&total).Error
+	return total, err
+}
+
+// Backfill records an opening balance and cost layer for items that hold stock
+// but have no ledger entries yet, so balances derived from the ledger match the
+// cached stock for data created before the ledger existed.
+func Backfill(db *gorm.DB) error {
+	for itemType, table := range itemTables {
+		if err := db.Exec(`
+			INSERT INTO stock_movements (created_at, updated_at, item_type, item_id, movement_type, quantity, balance_after, reason)
+			SELECT now(), now(), ?, t.id, ?, t.stock, t.stock, 'Opening balance'
+			FROM `+table+` t
+			WHERE t.deleted_at IS NULL AND t.stock <> 0
+				AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.item_type = ? AND m.item_id = t.id)`,
+			itemType, models.MovementAdjustment, itemType).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec(`
+			INSERT INTO cost_layers (created_at, updated_at, item_type, item_id, movement_id, quantity, remaining, unit_cost)
+			SELECT now(), now(), ?, t.id,
+				coalesce((SELECT max(m.id) FROM stock_movements m WHERE m.item_type = ? AND m.item_id = t.id), 0),
+				t.stock, t.stock, t.cost_price
+			FROM `+table+` t
+			WHERE t.deleted_at IS NULL AND t.stock > 0
+				AND NOT EXISTS (SELECT 1 FROM cost_layers l WHERE l.item_type = ? AND l.item_id = t.id)`,
+			itemType, itemType, itemType).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var itemTables = map[string]string{
+	models.ItemTypeProduct:    "products",
+	models.ItemTypeVariant:    "product_variants",
+	models.ItemTypeAddon:      "product_addons",
+	models.ItemTypeIngredient: "ingredients",
+}
+
+func itemTable(itemType string) (string, error) {
+	table, ok := itemTables[itemType]
+	if !ok {
+		return "", ErrInvalidItemType
+	}
+	return table, nil
+}
+
+// checkDirection enforces the sign of each movement type: stock comes in on
+// receiving and returns, goes out on sales, waste and consumption, and either
+// way on adjustments and transfers.
+func checkDirection(movementType string, quantity int) error {
+	if quantity == 0 {
+		return ErrInvalidQuantity
+	}
+
+	switch movementType {
+	case models.MovementReceiving, models.MovementReturn:
+		if quantity < 0 {
+			return ErrInvalidQuantity
+		}
+	case models.MovementSale, models.MovementWaste, models.MovementConsumption:
+		if quantity > 0 {
+			return ErrInvalidQuantity
+		}
+	case models.MovementAdjustment, models.MovementTransfer:
+	default:
+		return ErrInvalidMovementType
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+// LowStockItem is a product or variant whose available stock is at or below
+// its reorder point.
+type LowStockItem struct {
+	ItemType          string     `json:"itemType"`
+	ItemID            uint       `json:"itemId"`
+	ProductID         uint       `json:"productId"`
+	Name              string     `json:"name"`
+	VariantName       string     `json:"variantName,omitempty"`
+	SKU               string     `json:"sku"`
+	Stock             int        `json:"stock"`
+	Reserved          int        `json:"reserved"`
+	Available         int        `json:"available"`
+	MinStock          int        `json:"minStock"`
+	ReorderQuantity   int        `json:"reorderQuantity"`
+	SuggestedQuantity int        `json:"suggestedQuantity"`
+	LastAlertAt       *time.Time `json:"lastAlertAt"`
+}
+
+// Notifier delivers low-stock alerts to the people who reorder stock.
+type Notifier interface {
+	NotifyLowStock(ctx context.Context, items []LowStockItem) error
+}
+
+// Products with variants are tracked per variant; a variant without its own
+// threshold uses the product's.
+const lowStockSQL = `
+WITH reserved AS (
+	SELECT i.item_type, i.item_id, sum(i.quantity) AS quantity
+	FROM stock_reservation_items i
+	JOIN stock_reservations r ON r.id = i.reservation_id AND r.deleted_at IS NULL
+	WHERE i.deleted_at IS NULL AND r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > now())
+	GROUP BY i.item_type, i.item_id
+), items AS (
+	SELECT 'product' AS item_type, p.id AS item_id, p.id AS product_id, p.name, '' AS variant_name, p.sku,
+		p.stock, coalesce(res.quantity, 0) AS reserved, p.min_stock, p.reorder_quantity,
+		p.low_stock_alert_at AS last_alert_at
+	FROM products p
+	LEFT JOIN reserved res ON res.item_type = 'product' AND res.item_id = p.id
+	WHERE p.deleted_at IS NULL AND p.status = 'active' AND p.min_stock IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.deleted_at IS NULL)
+	UNION ALL
+	SELECT 'variant', v.id, p.id, p.name, v.name, v.sku,
+		v.stock, coalesce(res.quantity, 0), coalesce(v.min_stock, p.min_stock),
+		coalesce(nullif(v.reorder_quantity, 0), p.reorder_quantity), v.low_stock_alert_at
+	FROM product_variants v
+	JOIN products p ON p.id = v.product_id AND p.deleted_at IS NULL AND p.status = 'active'
+	LEFT JOIN reserved res ON res.item_type = 'variant' AND res.item_id = v.id
+	WHERE v.deleted_at IS NULL AND coalesce(v.status, 'active') = 'active'
+		AND coalesce(v.min_stock, p.min_stock) IS NOT NULL
+)
+SELECT item_type, item_id, product_id, name, variant_name, sku, stock, reserved,
+	stock - reserved AS available, min_stock, reorder_quantity, last_alert_at
+FROM items
+WHERE stock - reserved <= min_stock
+ORDER BY stock - reserved - min_stock, name, variant_name`
+
+// LowStock lists every item at or below its reorder point, most urgent first.
+func LowStock(db *gorm.DB) ([]LowStockItem, error) {
+	var items []LowStockItem
+	if err := db.Raw(lowStockSQL).Scan(&items).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		shortfall := items[i].MinStock - items[i].Available
+		items[i].SuggestedQuantity = items[i].ReorderQuantity
+		if shortfall > items[i].SuggestedQuantity {
+			items[i].SuggestedQuantity = shortfall
+		}
+	}
+	return items, nil
+}
+
+// LowStockMonitor periodically checks stock levels and alerts once per item
+// when it drops to its reorder point. An item is alerted again only after it
+// has been restocked above the threshold and dropped again.
+type LowStockMonitor struct {
+	db       *gorm.DB
+	notifier Notifier
+	interval time.Duration
+}
+
+func NewLowStockMonitor(db *gorm.DB, notifier Notifier, interval time.Duration) *LowStockMonitor {
+	return &LowStockMonitor{db: db, notifier: notifier, interval: interval}
+}
+
+// Run checks stock levels until ctx is cancelled.
+func (m *LowStockMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.check(ctx); err != nil {
+			log.Printf("Failed to check low stock: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *LowStockMonitor) check(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+
+	items, err := LowStock(db)
+	if err != nil {
+		return err
+	}
+
+	low := map[string][]uint{models.ItemTypeProduct: {}, models.ItemTypeVariant: {}}
+	for _, item := range items {
+		low[item.ItemType] = append(low[item.ItemType], item.ItemID)
+	}
+
+	// Clear the alert marker on items that were restocked
+	for itemType, ids := range low {
+		query := db.Table(itemTables[itemType]).Where("low_stock_alert_at IS NOT NULL")
+		if len(ids) > 0 {
+			query = query.Where("id NOT IN ?", ids)
+		}
+		if err := query.Update("low_stock_alert_at", nil).Error; err != nil {
+			return err
+		}
+	}
+
+	var pending []LowStockItem
+	for _, item := range items {
+		if item.LastAlertAt == nil {
+			pending = append(pending, item)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := m.notifier.NotifyLowStock(ctx, pending); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, item := range pending {
+		if err := db.Table(itemTables[item.ItemType]).
+			Where("id = ?", item.ItemID).
+			Update("low_stock_alert_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package inventory
+
+import (
+	"fmt"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutletLevel is an item's stock at one outlet.
+type OutletLevel struct {
+	Outlet   string `json:"outlet"`
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+	Name     string `json:"name"`
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+// OutletFilter narrows OutletLevels. Zero values match everything.
+type OutletFilter struct {
+	Outlet   string
+	ItemType string
+	ItemID   *uint
+}
+
+const outletLevelsSQL = `
+	SELECT s.outlet, s.item_type, s.item_id, p.name, p.sku, s.quantity
+	FROM outlet_stocks s
+	JOIN products p ON s.item_type = 'product' AND p.id = s.item_id
+	WHERE s.deleted_at IS NULL
+	UNION ALL
+	SELECT s.outlet, s.item_type, s.item_id, p.name || ' ' || v.name, v.sku, s.quantity
+	FROM outlet_stocks s
+	JOIN product_variants v ON s.item_type = 'variant' AND v.id = s.item_id
+	JOIN products p ON p.id = v.product_id
+	WHERE s.deleted_at IS NULL
+	UNION ALL
+	SELECT s.outlet, s.item_type, s.item_id, a.name, a.sku, s.quantity
+	FROM outlet_stocks s
+	JOIN product_addons a ON s.item_type = 'addon' AND a.id = s.item_id
+	WHERE s.deleted_at IS NULL`
+
+// OutletLevels lists stock held at outlets, ordered by outlet and name.
+func OutletLevels(db *gorm.DB, filter OutletFilter) ([]OutletLevel, error) {
+	query := db.Table("(" + outletLevelsSQL + ") AS levels")
+	if filter.Outlet != "" {
+		query = query.Where("outlet = ?", filter.Outlet)
+	}
+	if filter.ItemType != "" {
+		query = query.Where("item_type = ?", filter.ItemType)
+	}
+	if filter.ItemID != nil {
+		query = query.Where("item_id = ?", *filter.ItemID)
+	}
+
+	var levels []OutletLevel
+	err := query.Order("outlet asc, name asc").Scan(&levels).Error
+	return levels, err
+}
+
+// moveOutletStock applies a movement to the outlet's stock, creating the row
+// on the first movement at that outlet.
+func moveOutletStock(tx *gorm.DB, m Movement) error {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.OutletStock{
+		Outlet:   m.Outlet,
+		ItemType: m.ItemType,
+		ItemID:   m.ItemID,
+	}).Error; err != nil {
+		return err
+	}
+
+	var stock models.OutletStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("outlet = ? AND item_type = ? AND item_id = ?", m.Outlet, m.ItemType, m.ItemID).
+		First(&stock).Error; err != nil {
+		return err
+	}
+
+	balance := stock.Quantity + m.Quantity
+	if balance < 0 && m.Quantity < 0 && !m.AllowNegative {
+		return fmt.Errorf("%w: %s %d has %d at %s", ErrInsufficientStock, m.ItemType, m.ItemID, stock.Quantity, m.Outlet)
+	}
+
+	return tx.Model(&stock).Update("quantity", balance).Error
+}
+
+// AssignOutletStock places quantity of an item's unassigned stock at an
+// outlet, e.g. to record where opening stock is held, without changing the
+// item's total. A negative quantity returns stock from the outlet to
+// unassigned.
+func AssignOutletStock(tx *gorm.DB, outlet, itemType string, itemID uint, quantity int) error {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return err
+	}
+	if quantity == 0 {
+		return ErrInvalidQuantity
+	}
+
+	var item struct {
+		ID    uint
+		Stock int
+	}
+	result := tx.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, stock").
+		Where("id = ? AND deleted_at IS NULL", itemID).
+		Scan(&item)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	if quantity > 0 {
+		var assigned int
+		if err := tx.Model(&models.OutletStock{}).
+			Select("coalesce(sum(quantity), 0)").
+			Where("item_type = ? AND item_id = ?", itemType, itemID).
+			Scan(&assigned).Error; err != nil {
+			return err
+		}
+		if unassigned := item.Stock - assigned; quantity > unassigned {
+			return fmt.Errorf("%w: %s %d has %d unassigned", ErrInsufficientStock, itemType, itemID, unassigned)
+		}
+	}
+
+	return moveOutletStock(tx, Movement{Outlet: outlet, ItemType: itemType, ItemID: itemID, Quantity: quantity})
+}
@@ -0,0 +1,204 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRecipeItemType   = errors.New("recipes belong to products, variants or addons")
+	ErrRecipeIngredient = errors.New("invalid recipe ingredient")
+)
+
+// IngredientUsage compares, for one ingredient over a period, what recipes
+// say sales should have used with what actually left stock through sales,
+// waste and count adjustments. A positive variance means more was used than
+// the recipes account for.
+type IngredientUsage struct {
+	IngredientID    uint    `json:"ingredientId"`
+	Name            string  `json:"name"`
+	SKU             string  `json:"sku"`
+	Unit            string  `json:"unit"`
+	Theoretical     int     `json:"theoretical"`
+	Actual          int     `json:"actual"`
+	Waste           int     `json:"waste"` // Part of Actual recorded as waste
+	Variance        int     `json:"variance"`
+	VariancePct     float64 `json:"variancePct"`
+	TheoreticalCost float64 `json:"theoreticalCost"`
+	ActualCost      float64 `json:"actualCost"`
+	VarianceCost    float64 `json:"varianceCost"`
+}
+
+// Theoretical usage is what recipes consumed; actual usage is everything that
+// left stock other than transfers between outlets, counted at the cost it left
+// at. Adjustments are signed, so stock found at a count reduces usage.
+const ingredientUsageSQL = `
+SELECT i.id AS ingredient_id, i.name, i.sku, coalesce(u.code, 'pcs') AS unit,
+	coalesce(-sum(m.quantity) FILTER (WHERE m.movement_type = 'consumption'), 0) AS theoretical,
+	coalesce(-sum(m.quantity) FILTER (WHERE m.movement_type IN ('consumption', 'waste', 'adjustment')), 0) AS actual,
+	coalesce(-sum(m.quantity) FILTER (WHERE m.movement_type = 'waste'), 0) AS waste,
+	coalesce(-sum(m.quantity * m.unit_cost) FILTER (WHERE m.movement_type = 'consumption'), 0) AS theoretical_cost,
+	coalesce(-sum(m.quantity * m.unit_cost) FILTER (WHERE m.movement_type IN ('consumption', 'waste', 'adjustment')), 0) AS actual_cost
+FROM ingredients i
+LEFT JOIN unit_of_measures u ON u.id = i.unit_id
+LEFT JOIN stock_movements m ON m.item_type = 'ingredient' AND m.item_id = i.id AND m.deleted_at IS NULL
+	AND m.created_at >= @from AND m.created_at < @to
+WHERE i.deleted_at IS NULL AND (@ingredient = 0 OR i.id = @ingredient)
+GROUP BY i.id, i.name, i.sku, u.code
+ORDER BY i.name`
+
+// ItemRecipe returns the recipe of a product, variant or addon.
+func ItemRecipe(db *gorm.DB, itemType string, itemID uint) ([]models.RecipeLine, error) {
+	var lines []models.RecipeLine
+	err := db.Preload("Ingredient.Unit").
+		Where("item_type = ? AND item_id = ?", itemType, itemID).
+		Order("id asc").
+		Find(&lines).Error
+	return lines, err
+}
+
+// ReplaceRecipe validates and replaces the recipe of a product, variant or
+// addon. An empty recipe removes it.
+func ReplaceRecipe(tx *gorm.DB, itemType string, itemID uint, lines []models.RecipeLine) error {
+	if itemType == models.ItemTypeIngredient {
+		return ErrRecipeItemType
+	}
+	table, err := itemTable(itemType)
+	if err != nil {
+		return ErrRecipeItemType
+	}
+	var exists int64
+	if err := tx.Table(table).Where("id = ? AND deleted_at IS NULL", itemID).Count(&exists).Error; err != nil {
+		return err
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: %s %d", ErrItemNotFound, itemType, itemID)
+	}
+
+	seen := map[uint]bool{}
+	for i := range lines {
+		if seen[lines[i].IngredientID] {
+			return fmt.Errorf("%w: ingredient %d is listed more than once", ErrRecipeIngredient, lines[i].IngredientID)
+		}
+		seen[lines[i].IngredientID] = true
+		if lines[i].Quantity <= 0 {
+			return fmt.Errorf("%w: quantity of ingredient %d must be positive", ErrRecipeIngredient, lines[i].IngredientID)
+		}
+
+		var found int64
+		if err := tx.Model(&models.Ingredient{}).Where("id = ?", lines[i].IngredientID).Count(&found).Error; err != nil {
+			return err
+		}
+		if found == 0 {
+			return fmt.Errorf("%w: ingredient %d not found", ErrRecipeIngredient, lines[i].IngredientID)
+		}
+
+		lines[i].ID = 0
+		lines[i].ItemType = itemType
+		lines[i].ItemID = itemID
+	}
+
+	// Removed lines are deleted outright so an ingredient can be added back
+	if err := tx.Unscoped().Where("item_type = ? AND item_id = ?", itemType, itemID).Delete(&models.RecipeLine{}).Error; err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return tx.Omit("Ingredient").Create(&lines).Error
+}
+
+// IngredientUsageReport lists theoretical against actual usage of every
+// ingredient, or of one when ingredientID is not zero, between from and to.
+func IngredientUsageReport(db *gorm.DB, from, to time.Time, ingredientID uint) ([]IngredientUsage, error) {
+	var rows []IngredientUsage
+	if err := db.Raw(ingredientUsageSQL, map[string]interface{}{
+		"from":       from,
+		"to":         to,
+		"ingredient": ingredientID,
+	}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].Variance = rows[i].Actual - rows[i].Theoretical
+		if rows[i].Theoretical != 0 {
+			rows[i].VariancePct = math.Round(float64(rows[i].Variance)/float64(rows[i].Theoretical)*10000) / 100
+		}
+		rows[i].TheoreticalCost = math.Round(rows[i].TheoreticalCost*100) / 100
+		rows[i].ActualCost = math.Round(rows[i].ActualCost*100) / 100
+		rows[i].VarianceCost = math.Round((rows[i].ActualCost-rows[i].TheoreticalCost)*100) / 100
+	}
+	return rows, nil
+}
+
+// consumeIngredients posts the ingredients used by the items of a committed
+// reservation. Quantities are summed per ingredient before rounding to whole
+// units, so ten lattes with 7.5 g of beans each use 75 g.
+func consumeIngredients(tx *gorm.DB, items []models.StockReservationItem, reference, outlet string, userID *uint) error {
+	used := map[uint]float64{}
+	for _, item := range items {
+		sold := item.SellQuantity
+		if sold <= 0 {
+			sold = float64(item.Quantity)
+		}
+
+		lines, err := recipeFor(tx, item.ItemType, item.ItemID)
+		if err != nil {
+			return err
+		}
+		for _, line := range lines {
+			used[line.IngredientID] += line.Quantity * sold
+		}
+	}
+
+	// Lock ingredients in a fixed order so concurrent commits cannot deadlock
+	ingredientIDs := make([]uint, 0, len(used))
+	for id := range used {
+		ingredientIDs = append(ingredientIDs, id)
+	}
+	sort.Slice(ingredientIDs, func(i, j int) bool { return ingredientIDs[i] < ingredientIDs[j] })
+
+	for _, id := range ingredientIDs {
+		quantity := int(math.Round(used[id]))
+		if quantity == 0 {
+			continue
+		}
+		if _, err := Post(tx, Movement{
+			ItemType:      models.ItemTypeIngredient,
+			ItemID:        id,
+			MovementType:  models.MovementConsumption,
+			Quantity:      -quantity,
+			Reason:        "Used by order items",
+			Reference:     reference,
+			UserID:        userID,
+			Outlet:        outlet,
+			AllowNegative: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recipeFor returns the recipe an item is made with. Variants without a recipe
+// of their own use their product's.
+func recipeFor(tx *gorm.DB, itemType string, itemID uint) ([]models.RecipeLine, error) {
+	var lines []models.RecipeLine
+	if err := tx.Where("item_type = ? AND item_id = ?", itemType, itemID).Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	if len(lines) > 0 || itemType != models.ItemTypeVariant {
+		return lines, nil
+	}
+
+	err := tx.Where("item_type = ? AND item_id = (SELECT product_id FROM product_variants WHERE id = ?)",
+		models.ItemTypeProduct, itemID).Find(&lines).Error
+	return lines, err
+}
@@ -0,0 +1,360 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationClosed    = errors.New("reservation was released or has expired")
+	ErrReservationCommitted = errors.New("reservation is already committed")
+	ErrReservationMismatch  = errors.New("reservation key was already used for different items")
+)
+
+// ReservationItem is a quantity of one product, variant or addon to hold.
+// Quantity is in stock units and SellQuantity in the unit it is sold in.
+type ReservationItem struct {
+	ItemType     string
+	ItemID       uint
+	Quantity     int
+	SellQuantity float64
+}
+
+// Reserve holds stock for every item or for none of them. Item rows are locked
+// in a fixed order so concurrent reservations cannot deadlock. A ttl of zero
+// keeps the hold until it is committed or released. Calling it again with the
+// same key, even concurrently, returns the existing reservation and created is
+// false while it is active, and an error once it was committed, released or
+// expired.
+func Reserve(tx *gorm.DB, key, reference, outlet string, ttl time.Duration, items []ReservationItem) (reservation *models.StockReservation, created bool, err error) {
+	merged, err := mergeItems(items)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reservation = &models.StockReservation{
+		Key:       key,
+		Reference: reference,
+		Outlet:    outlet,
+		Status:    models.ReservationActive,
+	}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		reservation.ExpiresAt = &expiresAt
+	}
+
+	// Claim the key before touching stock. A concurrent request for the same
+	// key waits on the insert and then replays the reservation it made.
+	result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "key"}}, DoNothing: true}).Create(reservation)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		existing, err := replayReservation(tx, key, merged)
+		return existing, false, err
+	}
+
+	for _, item := range merged {
+		available, costPrice, err := lockAvailable(tx, item.ItemType, item.ItemID)
+		if err != nil {
+			return nil, false, err
+		}
+		if available < item.Quantity {
+			return nil, false, fmt.Errorf("%w: %s %d has %d available", ErrInsufficientStock, item.ItemType, item.ItemID, available)
+		}
+
+		reservation.Items = append(reservation.Items, models.StockReservationItem{
+			ReservationID: reservation.ID,
+			ItemType:      item.ItemType,
+			ItemID:        item.ItemID,
+			Quantity:      item.Quantity,
+			SellQuantity:  item.SellQuantity,
+			UnitCost:      costPrice,
+		})
+	}
+
+	if err := tx.Create(&reservation.Items).Error; err != nil {
+		return nil, false, err
+	}
+	return reservation, true, nil
+}
+
+// replayReservation returns the reservation already made under key when it
+// holds the same items and is still in place.
+func replayReservation(tx *gorm.DB, key string, items []ReservationItem) (*models.StockReservation, error) {
+	var existing models.StockReservation
+	if err := tx.Preload("Items").Where("key = ?", key).First(&existing).Error; err != nil {
+		return nil, err
+	}
+	if !sameItems(existing.Items, items) {
+		return nil, ErrReservationMismatch
+	}
+	if existing.Status == models.ReservationCommitted {
+		return nil, ErrReservationCommitted
+	}
+	if existing.Status != models.ReservationActive || expired(&existing, time.Now()) {
+		return nil, ErrReservationClosed
+	}
+	return &existing, nil
+}
+
+// Commit turns a reservation into sales in the ledger and uses up the
+// ingredients in the sold items' recipes. Item unit costs are updated to the
+// cost of the sales. Committing twice is a no-op.
+func Commit(tx *gorm.DB, key string, userID *uint) (*models.StockReservation, error) {
+	reservation, err := lockReservation(tx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	committed, err := committable(reservation)
+	if err != nil {
+		return nil, err
+	}
+	if committed {
+		return reservation, nil
+	}
+
+	reference := reservation.Reference
+	if reference == "" {
+		reference = reservation.Key
+	}
+	for i, item := range reservation.Items {
+		movement, err := Post(tx, Movement{
+			ItemType:      item.ItemType,
+			ItemID:        item.ItemID,
+			MovementType:  models.MovementSale,
+			Quantity:      -item.Quantity,
+			Reason:        "Order committed",
+			Reference:     reference,
+			UserID:        userID,
+			Outlet:        reservation.Outlet,
+			AllowNegative: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// The held cost was the cost price at reservation time; keep the cost
+		// the sale actually took out of stock, e.g. its FIFO layers
+		if err := tx.Model(&reservation.Items[i]).Update("unit_cost", movement.UnitCost).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := consumeIngredients(tx, reservation.Items, reference, reservation.Outlet, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := tx.Model(reservation).Updates(map[string]interface{}{
+		"status":       models.ReservationCommitted,
+		"committed_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	reservation.Status = models.ReservationCommitted
+	reservation.CommittedAt = &now
+
+	return reservation, nil
+}
+
+// Release gives reserved stock back. Releasing a reservation that was already
+// released or has expired is a no-op.
+func Release(tx *gorm.DB, key string) (*models.StockReservation, error) {
+	reservation, err := lockReservation(tx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch reservation.Status {
+	case models.ReservationReleased, models.ReservationExpired:
+		return reservation, nil
+	case models.ReservationCommitted:
+		return nil, ErrReservationCommitted
+	}
+
+	now := time.Now()
+	if err := tx.Model(reservation).Updates(map[string]interface{}{
+		"status":      models.ReservationReleased,
+		"released_at": now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	reservation.Status = models.ReservationReleased
+	reservation.ReleasedAt = &now
+
+	return reservation, nil
+}
+
+// Reserved returns the quantity of an item held by active reservations that
+// have not run out.
+func Reserved(db *gorm.DB, itemType string, itemID uint) (int, error) {
+	var total int
+	err := db.Model(&models.StockReservationItem{}).
+		Joins("JOIN stock_reservations r ON r.id = stock_reservation_items.reservation_id AND r.deleted_at IS NULL").
+		Select("coalesce(sum(stock_reservation_items.quantity), 0)").
+		Where("stock_reservation_items.item_type = ? AND stock_reservation_items.item_id = ?", itemType, itemID).
+		Where("r.status = ? AND (r.expires_at IS NULL OR r.expires_at > ?)", models.ReservationActive, time.Now()).
+		Scan(&total).Error
+	return total, err
+}
+
+// ExpireReservations marks active reservations past their expiry as expired.
+// Expired holds already stop counting against availability; this keeps their
+// status accurate.
+func ExpireReservations(db *gorm.DB) (int64, error) {
+	result := db.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at <= ?", models.ReservationActive, time.Now()).
+		Update("status", models.ReservationExpired)
+	return result.RowsAffected, result.Error
+}
+
+// SweepReservations expires stale reservations every interval until ctx is
+// cancelled.
+func SweepReservations(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := ExpireReservations(db.WithContext(ctx))
+		if err != nil {
+			log.Printf("Failed to expire stock reservations: %v", err)
+		} else if expired > 0 {
+			log.Printf("Expired %d stock reservations", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lockAvailable locks the item row and returns its stock less active holds,
+// along with its current cost price.
+func lockAvailable(tx *gorm.DB, itemType string, itemID uint) (int, float64, error) {
+	table, err := itemTable(itemType)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var item struct {
+		ID        uint
+		Stock     int
+		CostPrice float64
+	}
+	result := tx.Table(table).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, stock, cost_price").
+		Where("id = ? AND deleted_at IS NULL", itemID).
+		Scan(&item)
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, 0, fmt.Errorf("%w: %s %d", ErrItemNotFound, itemType, itemID)
+	}
+
+	reserved, err := Reserved(tx, itemType, itemID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return item.Stock - reserved, item.CostPrice, nil
+}
+
+// committable reports whether a reservation was already committed, and
+// ErrReservationClosed once it was released. A hold that expired no longer
+// keeps its stock aside, but the sale it stood for still goes ahead against
+// whatever stock is left, which may go negative like any other sale.
+func committable(reservation *models.StockReservation) (committed bool, err error) {
+	switch reservation.Status {
+	case models.ReservationCommitted:
+		return true, nil
+	case models.ReservationReleased:
+		return false, ErrReservationClosed
+	}
+	return false, nil
+}
+
+// expired reports whether a reservation's hold ran out before now. Holds
+// without an expiry never run out.
+func expired(reservation *models.StockReservation, now time.Time) bool {
+	return reservation.ExpiresAt != nil && !reservation.ExpiresAt.After(now)
+}
+
+func lockReservation(tx *gorm.DB, key string) (*models.StockReservation, error) {
+	var reservation models.StockReservation
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("key = ?", key).
+		Limit(1).
+		Find(&reservation)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrReservationNotFound
+	}
+
+	if err := tx.Where("reservation_id = ?", reservation.ID).Find(&reservation.Items).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// mergeItems sums quantities of repeated items and sorts them by type and ID.
+func mergeItems(items []ReservationItem) ([]ReservationItem, error) {
+	type itemKey struct {
+		itemType string
+		itemID   uint
+	}
+	totals := map[itemKey]int{}
+	sold := map[itemKey]float64{}
+	for _, item := range items {
+		if _, err := itemTable(item.ItemType); err != nil {
+			return nil, err
+		}
+		if item.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		totals[itemKey{item.ItemType, item.ItemID}] += item.Quantity
+		sold[itemKey{item.ItemType, item.ItemID}] += item.SellQuantity
+	}
+
+	merged := make([]ReservationItem, 0, len(totals))
+	for k, quantity := range totals {
+		merged = append(merged, ReservationItem{ItemType: k.itemType, ItemID: k.itemID, Quantity: quantity, SellQuantity: sold[k]})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].ItemType != merged[j].ItemType {
+			return merged[i].ItemType < merged[j].ItemType
+		}
+		return merged[i].ItemID < merged[j].ItemID
+	})
+	return merged, nil
+}
+
+func sameItems(held []models.StockReservationItem, requested []ReservationItem) bool {
+	if len(held) != len(requested) {
+		return false
+	}
+	quantities := map[string]int{}
+	for _, item := range held {
+		quantities[fmt.Sprintf("%s:%d", item.ItemType, item.ItemID)] = item.Quantity
+	}
+	for _, item := range requested {
+		if quantities[fmt.Sprintf("%s:%d", item.ItemType, item.ItemID)] != item.Quantity {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+)
+
+func TestCommittable(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		status    string
+		expiresAt *time.Time
+		committed bool
+		err       error
+	}{
+		{"active hold", models.ReservationActive, &future, false, nil},
+		{"order hold without expiry", models.ReservationActive, nil, false, nil},
+		{"hold past its expiry", models.ReservationActive, &past, false, nil},
+		{"hold marked expired by the sweeper", models.ReservationExpired, &past, false, nil},
+		{"already committed", models.ReservationCommitted, &future, true, nil},
+		{"released", models.ReservationReleased, &future, false, ErrReservationClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservation := &models.StockReservation{Status: tt.status, ExpiresAt: tt.expiresAt}
+			committed, err := committable(reservation)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("committable() error = %v, want %v", err, tt.err)
+			}
+			if committed != tt.committed {
+				t.Errorf("committable() = %v, want %v", committed, tt.committed)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name      string
+		expiresAt *time.Time
+		want      bool
+	}{
+		{"no expiry", nil, false},
+		{"expires later", &future, false},
+		{"expires now", &now, true},
+		{"expired", &past, true},
+	}
+
+	for _, tt := range tests {
+		reservation := &models.StockReservation{ExpiresAt: tt.expiresAt}
+		if got := expired(reservation, now); got != tt.want {
+			t.Errorf("%s: expired() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
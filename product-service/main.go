@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/ridhotamma/yourkasa/product-service/config"
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+	"github.com/ridhotamma/yourkasa/product-service/notify"
 	"github.com/ridhotamma/yourkasa/product-service/routes"
+	"github.com/ridhotamma/yourkasa/product-service/storage"
 	"github.com/ridhotamma/yourkasa/product-service/utils"
 )
 
@@ -49,15 +55,36 @@ func prometheusMiddleware() gin.HandlerFunc {
 
 func main() {
 	db := config.InitDB()
+
+	go inventory.SweepReservations(context.Background(), db, time.Minute)
+
+	notifier, err := notify.NewFromEnv()
+	if err != nil {
+		log.Fatal("Failed to set up low-stock notifier:", err)
+	}
+	go inventory.NewLowStockMonitor(db, notifier, lowStockInterval()).Run(context.Background())
+
+	images, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatal("Failed to set up image storage:", err)
+	}
+
 	r := gin.Default()
 
 	r.Use(prometheusMiddleware())
 	r.Use(utils.ValidationMiddleware())
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	routes.SetupRoutes(r, db)
+	routes.SetupRoutes(r, db, images)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+func lowStockInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("LOW_STOCK_CHECK_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return time.Hour
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"os"
 	"strings"
@@ -85,3 +86,20 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// RequireServiceToken guards internal endpoints that are only called by other
+// services. Callers must send the shared INTERNAL_SERVICE_TOKEN.
+func RequireServiceToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		expected := os.Getenv("INTERNAL_SERVICE_TOKEN")
+		provided := ctx.GetHeader("X-Service-Token")
+
+		if expected == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(expected)) != 1 {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid service token"})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+const ItemTypeGroup = "group"
+
+// AvailabilityRule limits when a product or group can be sold. An item without
+// rules is always available; otherwise it is available while any of its rules
+// matches. Rules without an outlet apply to every outlet.
+type AvailabilityRule struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	ItemType  string     `json:"itemType" gorm:"type:varchar(20);not null;index:idx_availability_rules_item"` // product or group
+	ItemID    uint       `json:"itemId" gorm:"not null;index:idx_availability_rules_item"`
+	Outlet    string     `json:"outlet" gorm:"type:varchar(100);not null;default:''"`
+	Days      string     `json:"days" gorm:"type:varchar(27);not null;default:''"`     // e.g. mon,tue,wed; empty means every day
+	StartTime string     `json:"startTime" gorm:"type:varchar(5);not null;default:''"` // HH:MM, empty means all day
+	EndTime   string     `json:"endTime" gorm:"type:varchar(5);not null;default:''"`   // HH:MM, before StartTime when the range passes midnight
+	StartDate *time.Time `json:"startDate" gorm:"type:date"`
+	EndDate   *time.Time `json:"endDate" gorm:"type:date"` // Inclusive
+}
@@ -0,0 +1,27 @@
+package models
+
+// GroupTypeBundle marks a ProductGroup that is sold as one item made of its
+// components.
+const GroupTypeBundle = "bundle"
+
+// BundleComponent is a product or variant included in a bundle, such as the
+// burger in a combo meal. Choices list what the customer may swap it for.
+type BundleComponent struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	GroupID   uint           `json:"groupId" gorm:"not null;index"`
+	ItemType  string         `json:"itemType" gorm:"type:varchar(20);not null"` // product or variant
+	ItemID    uint           `json:"itemId" gorm:"not null"`
+	Quantity  int            `json:"quantity" gorm:"not null;default:1"`
+	SortOrder int            `json:"sortOrder" gorm:"default:0"`
+	Choices   []BundleChoice `json:"choices" gorm:"foreignKey:ComponentID;constraint:OnDelete:CASCADE"`
+}
+
+// BundleChoice is an alternative to a component's default item, e.g. a large
+// drink instead of a regular one for an extra charge.
+type BundleChoice struct {
+	ID              uint    `json:"id" gorm:"primaryKey"`
+	ComponentID     uint    `json:"componentId" gorm:"not null;index"`
+	ItemType        string  `json:"itemType" gorm:"type:varchar(20);not null"`
+	ItemID          uint    `json:"itemId" gorm:"not null"`
+	PriceAdjustment float64 `json:"priceAdjustment" gorm:"type:decimal(12,2);default:0"`
+}
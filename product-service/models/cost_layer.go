@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// CostLayer is a batch of stock that came in at one unit cost. Outgoing stock
+// consumes layers oldest first, which gives FIFO cost of goods sold.
+type CostLayer struct {
+	gorm.Model
+	ItemType   string  `json:"itemType" gorm:"type:varchar(20);not null;index:idx_cost_layers_item"`
+	ItemID     uint    `json:"itemId" gorm:"not null;index:idx_cost_layers_item"`
+	MovementID uint    `json:"movementId" gorm:"not null"`
+	Quantity   int     `json:"quantity" gorm:"not null"`
+	Remaining  int     `json:"remaining" gorm:"not null"`
+	UnitCost   float64 `json:"unitCost" gorm:"type:decimal(12,4);not null"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Ingredient is stock that is not sold on its own but used up by the items
+// that have it in their recipe, e.g. coffee beans and milk for a latte. Its
+// stock is kept in the inventory ledger like products, counted in its unit.
+type Ingredient struct {
+	gorm.Model
+	Name      string         `json:"name" gorm:"not null"`
+	SKU       string         `json:"sku" gorm:"uniqueIndex;not null"`
+	UnitID    *uint          `json:"unitId"` // Pieces when nil
+	Unit      *UnitOfMeasure `json:"unit"`
+	Stock     int            `json:"stock" gorm:"not null;default:0"`
+	CostPrice float64        `json:"costPrice" gorm:"type:decimal(12,2);default:0"` // Per unit
+	MinStock  *int           `json:"minStock"`
+	IsActive  bool           `json:"isActive" gorm:"default:true"`
+}
+
+// RecipeLine is the quantity of an ingredient used by one sell unit of a
+// product, variant or addon. A variant with its own recipe uses it instead of
+// its product's.
+type RecipeLine struct {
+	gorm.Model
+	ItemType     string     `json:"itemType" gorm:"type:varchar(20);not null;uniqueIndex:idx_recipe_lines_item_ingredient"`
+	ItemID       uint       `json:"itemId" gorm:"not null;uniqueIndex:idx_recipe_lines_item_ingredient"`
+	IngredientID uint       `json:"ingredientId" gorm:"not null;uniqueIndex:idx_recipe_lines_item_ingredient;index"`
+	Ingredient   Ingredient `json:"ingredient"`
+	Quantity     float64    `json:"quantity" gorm:"type:decimal(12,3);not null"` // In the ingredient's unit
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ModifierGroup bundles addons a customer picks from, such as "Size: choose 1"
+// or "Toppings: up to 4". Selections count addon quantities, so a double
+// topping counts twice.
+type ModifierGroup struct {
+	gorm.Model
+	Name          string         `json:"name" gorm:"not null"`
+	Description   string         `json:"description"`
+	MinSelections int            `json:"minSelections" gorm:"not null;default:0"` // 1 or more makes the group required
+	MaxSelections int            `json:"maxSelections" gorm:"not null;default:0"` // 0 means no limit
+	IsActive      bool           `json:"isActive" gorm:"default:true"`
+	Addons        []ProductAddon `json:"addons" gorm:"many2many:modifier_group_addons;"`
+}
+
+// ProductModifierGroup offers a modifier group on a product, optionally with
+// its own selection limits.
+type ProductModifierGroup struct {
+	ProductID       uint          `json:"productId" gorm:"primaryKey"`
+	ModifierGroupID uint          `json:"modifierGroupId" gorm:"primaryKey"`
+	SortOrder       int           `json:"sortOrder" gorm:"default:0"`
+	MinSelections   *int          `json:"minSelections"` // Overrides the group's limit when set
+	MaxSelections   *int          `json:"maxSelections"`
+	ModifierGroup   ModifierGroup `json:"modifierGroup"`
+}
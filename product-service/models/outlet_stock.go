@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// OutletStock is the quantity of an item held at one outlet. Movements posted
+// with an outlet keep it in step; the Stock column on the item stays the total
+// across all outlets, and any stock not yet placed at an outlet is unassigned.
+type OutletStock struct {
+	gorm.Model
+	Outlet   string `json:"outlet" gorm:"type:varchar(100);not null;uniqueIndex:idx_outlet_stocks_item"`
+	ItemType string `json:"itemType" gorm:"type:varchar(20);not null;uniqueIndex:idx_outlet_stocks_item"`
+	ItemID   uint   `json:"itemId" gorm:"not null;uniqueIndex:idx_outlet_stocks_item"`
+	Quantity int    `json:"quantity" gorm:"not null;default:0"`
+}
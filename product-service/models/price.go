@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PriceSourceManual    = "manual"    // Edited on the product or variant
+	PriceSourceImport    = "import"    // Catalogue import
+	PriceSourceGenerated = "generated" // Variant generated from product options
+)
+
+// PriceSchedule overrides the price of a product or variant between StartsAt
+// and EndsAt, e.g. for a promotion. A schedule without EndsAt stays in force
+// until it is removed. Schedules of the same item may not overlap.
+type PriceSchedule struct {
+	gorm.Model
+	ItemType  string     `json:"itemType" gorm:"type:varchar(20);not null;index:idx_price_schedules_item"`
+	ItemID    uint       `json:"itemId" gorm:"not null;index:idx_price_schedules_item"`
+	Name      string     `json:"name"`
+	Price     float64    `json:"price" gorm:"type:decimal(12,2);not null"`
+	StartsAt  time.Time  `json:"startsAt" gorm:"not null;index"`
+	EndsAt    *time.Time `json:"endsAt" gorm:"index"`
+	CreatedBy *uint      `json:"createdBy"`
+}
+
+// PriceHistory records a change to the regular price of a product or variant.
+// OldPrice is nil for the price an item was created with.
+type PriceHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ItemType  string    `json:"itemType" gorm:"type:varchar(20);not null;index:idx_price_histories_item"`
+	ItemID    uint      `json:"itemId" gorm:"not null;index:idx_price_histories_item"`
+	OldPrice  *float64  `json:"oldPrice" gorm:"type:decimal(12,2)"`
+	NewPrice  float64   `json:"newPrice" gorm:"type:decimal(12,2);not null"`
+	Source    string    `json:"source" gorm:"type:varchar(20);not null"`
+	UserID    *uint     `json:"userId"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+}
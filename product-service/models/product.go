@@ -12,9 +12,16 @@ type Product struct {
 	Description      string           `json:"description" gorm:"type:text"` // Extended description
 	ShortDescription string           `json:"shortDescription" gorm:"type:varchar(255)"`
 	Price            float64          `json:"price" gorm:"not null"`
+	CostPrice        float64          `json:"costPrice" gorm:"type:decimal(12,2);default:0"` // Weighted average cost of stock on hand
 	Stock            int              `json:"stock" gorm:"not null"`
+	MinStock         *int             `json:"minStock"`        // Reorder point; no alerts when nil
+	ReorderQuantity  int              `json:"reorderQuantity"` // Suggested quantity to order once below MinStock
+	LowStockAlertAt  *time.Time       `json:"lowStockAlertAt"` // Set while an alert is outstanding, cleared on restock
 	SKU              string           `json:"sku" gorm:"uniqueIndex;not null"`
-	ImageURL         string           `json:"imageUrl"`
+	BarCode          string           `json:"barCode" gorm:"index"`
+	ImageURL         string           `json:"imageUrl"`     // First of Images, or a link set by hand
+	ThumbnailURL     string           `json:"thumbnailUrl"` // Thumbnail of the first of Images
+	Images           []ProductImage   `json:"images,omitempty"`
 	LastSoldAt       *time.Time       `json:"lastSoldAt"`
 	CategoryID       uint             `json:"categoryId" gorm:"not null"`
 	Category         ProductCategory  `json:"category"`
@@ -22,6 +29,12 @@ type Product struct {
 	Group            *ProductGroup    `json:"group"`
 	Variants         []ProductVariant `json:"variants"`
 	Addons           []ProductAddon   `json:"addons" gorm:"many2many:product_addon_mappings;"`
+	TaxClassID       *uint            `json:"taxClassId"`                   // Default tax class when nil; variants use their product's
+	StockUnitID      *uint            `json:"stockUnitId"`                  // Unit Stock is counted in; pieces when nil
+	SellUnitID       *uint            `json:"sellUnitId"`                   // Unit Price is per; the stock unit when nil
+	PurchaseUnitID   *uint            `json:"purchaseUnitId"`               // Unit purchase orders are placed in; the stock unit when nil
+	Weighed          bool             `json:"weighed" gorm:"default:false"` // Sold in decimal quantities of the sell unit, e.g. 0.25 kg
+	TaxClass         *TaxClass        `json:"taxClass,omitempty"`
 	Status           string           `json:"status" gorm:"type:varchar(20);default:'active'"` // active, inactive, discontinued
 	Weight           float64          `json:"weight" gorm:"type:decimal(10,2)"`                // in kg
 	Dimensions       string           `json:"dimensions"`                                      // JSON string storing length, width, height
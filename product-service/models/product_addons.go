@@ -6,16 +6,21 @@ import (
 
 type ProductAddon struct {
 	gorm.Model
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price" gorm:"not null"`
-	SKU         string    `json:"sku" gorm:"uniqueIndex;not null"`
-	ImageURL    string    `json:"imageUrl"`
-	Stock       int       `json:"stock" gorm:"not null"`
-	IsRequired  bool      `json:"isRequired" gorm:"default:false"`
-	MaxQuantity int       `json:"maxQuantity" gorm:"default:1"`
-	Status      string    `json:"status" gorm:"type:varchar(20);default:'active'"`
-	Products    []Product `json:"products" gorm:"many2many:product_addon_mappings;"`
+	Name         string    `json:"name" gorm:"not null"`
+	Description  string    `json:"description"`
+	Price        float64   `json:"price" gorm:"not null"`
+	CostPrice    float64   `json:"costPrice" gorm:"type:decimal(12,2);default:0"`
+	SKU          string    `json:"sku" gorm:"uniqueIndex;not null"`
+	BarCode      string    `json:"barCode" gorm:"index"`
+	ImageURL     string    `json:"imageUrl"`
+	ThumbnailURL string    `json:"thumbnailUrl"`
+	Stock        int       `json:"stock" gorm:"not null"`
+	IsRequired   bool      `json:"isRequired" gorm:"default:false"`
+	MaxQuantity  int       `json:"maxQuantity" gorm:"default:1"`
+	Status       string    `json:"status" gorm:"type:varchar(20);default:'active'"`
+	TaxClassID   *uint     `json:"taxClassId"` // Default tax class when nil
+	TaxClass     *TaxClass `json:"taxClass,omitempty"`
+	Products     []Product `json:"products" gorm:"many2many:product_addon_mappings;"`
 }
 
 type ProductAddonMapping struct {
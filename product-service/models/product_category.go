@@ -6,14 +6,16 @@ import (
 
 type ProductCategory struct {
 	gorm.Model
-	Name        string           `json:"name" gorm:"not null"`
-	Slug        string           `json:"slug" gorm:"uniqueIndex;not null"`
-	Description string           `json:"description"`
-	ImageURL    string           `json:"imageUrl"`
-	ParentID    *uint            `json:"parentId"` // For nested categories
-	Parent      *ProductCategory `json:"parent" gorm:"foreignKey:ParentID"`
-	Products    []Product        `json:"products"`
-	Level       int              `json:"level" gorm:"not null"` // Depth level in category tree
-	IsActive    bool             `json:"isActive" gorm:"default:true"`
-	SortOrder   int              `json:"sortOrder" gorm:"default:0"`
+	Name         string           `json:"name" gorm:"not null"`
+	Slug         string           `json:"slug" gorm:"uniqueIndex;not null"`
+	Description  string           `json:"description"`
+	ImageURL     string           `json:"imageUrl"`
+	ThumbnailURL string           `json:"thumbnailUrl"`
+	ParentID     *uint            `json:"parentId"` // For nested categories
+	Parent       *ProductCategory `json:"parent" gorm:"foreignKey:ParentID"`
+	Products     []Product        `json:"products"`
+	Level        int              `json:"level" gorm:"not null"`               // Depth in the category tree, 0 for root categories
+	Path         string           `json:"path" gorm:"type:varchar(255);index"` // Materialized path of IDs from the root, e.g. /1/4/9/
+	IsActive     bool             `json:"isActive" gorm:"default:true"`
+	SortOrder    int              `json:"sortOrder" gorm:"default:0"`
 }
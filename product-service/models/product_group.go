@@ -8,13 +8,21 @@ import (
 
 type ProductGroup struct {
 	gorm.Model
-	Name        string     `json:"name" gorm:"not null"`
-	Description string     `json:"description"`
-	ImageURL    string     `json:"imageUrl"`
-	Products    []Product  `json:"products"`
-	IsActive    bool       `json:"isActive" gorm:"default:true"`
-	StartDate   *time.Time `json:"startDate"`                         // For temporary/seasonal groups
-	EndDate     *time.Time `json:"endDate"`                           // For temporary/seasonal groups
-	GroupType   string     `json:"groupType" gorm:"type:varchar(50)"` // bundle, collection, seasonal, etc.
-	SortOrder   int        `json:"sortOrder" gorm:"default:0"`
+	Name         string     `json:"name" gorm:"not null"`
+	Description  string     `json:"description"`
+	ImageURL     string     `json:"imageUrl"`
+	ThumbnailURL string     `json:"thumbnailUrl"`
+	Products     []Product  `json:"products"`
+	IsActive     bool       `json:"isActive" gorm:"default:true"`
+	StartDate    *time.Time `json:"startDate"`                         // For temporary/seasonal groups
+	EndDate      *time.Time `json:"endDate"`                           // For temporary/seasonal groups
+	GroupType    string     `json:"groupType" gorm:"type:varchar(50)"` // bundle, collection, seasonal, etc.
+	SortOrder    int        `json:"sortOrder" gorm:"default:0"`
+
+	// Bundle pricing, used when GroupType is "bundle": a fixed price, or when
+	// nil the components' prices less DiscountPercent
+	BundlePrice     *float64          `json:"bundlePrice" gorm:"type:decimal(12,2)"`
+	DiscountPercent float64           `json:"discountPercent" gorm:"type:decimal(5,2);default:0"`
+	Components      []BundleComponent `json:"components,omitempty" gorm:"foreignKey:GroupID"`
+	TaxClassID      *uint             `json:"taxClassId"` // Tax on bundle sales; the default tax class when nil
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// ProductImage is one of a product's uploaded images, shown in SortOrder. The
+// first image is also kept in the product's ImageURL.
+type ProductImage struct {
+	gorm.Model
+	ProductID    uint   `json:"productId" gorm:"not null;index"`
+	URL          string `json:"url" gorm:"not null"`
+	ThumbnailURL string `json:"thumbnailUrl"`
+	ContentType  string `json:"contentType" gorm:"type:varchar(50)"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Size         int    `json:"size"` // Stored file size in bytes
+	AltText      string `json:"altText"`
+	SortOrder    int    `json:"sortOrder" gorm:"default:0"`
+}
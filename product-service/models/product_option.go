@@ -0,0 +1,20 @@
+package models
+
+// ProductOption is a dimension a product varies along, such as Size or Color.
+// Variants are generated from the combinations of option values.
+type ProductOption struct {
+	ID        uint                 `json:"id" gorm:"primaryKey"`
+	ProductID uint                 `json:"productId" gorm:"not null;index"`
+	Name      string               `json:"name" gorm:"not null"`
+	SortOrder int                  `json:"sortOrder" gorm:"default:0"`
+	Values    []ProductOptionValue `json:"values" gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
+}
+
+type ProductOptionValue struct {
+	ID              uint    `json:"id" gorm:"primaryKey"`
+	OptionID        uint    `json:"optionId" gorm:"not null;index"`
+	Value           string  `json:"value" gorm:"not null"`
+	SKUCode         string  `json:"skuCode" gorm:"type:varchar(20)"`                     // Used in generated SKUs; derived from the value when empty
+	PriceAdjustment float64 `json:"priceAdjustment" gorm:"type:decimal(12,2);default:0"` // Added to the product price for generated variants
+	SortOrder       int     `json:"sortOrder" gorm:"default:0"`
+}
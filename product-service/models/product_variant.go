@@ -1,22 +1,30 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type ProductVariant struct {
 	gorm.Model
-	ProductID  uint    `json:"productId" gorm:"not null"`
-	Name       string  `json:"name" gorm:"not null"`
-	SKU        string  `json:"sku" gorm:"uniqueIndex;not null"`
-	Price      float64 `json:"price" gorm:"not null"`
-	Stock      int     `json:"stock" gorm:"not null"`
-	ImageURL   string  `json:"imageUrl"`
-	Attributes string  `json:"attributes" gorm:"type:jsonb"` // JSON string storing variant attributes (color, size, etc.)
-	IsDefault  bool    `json:"isDefault" gorm:"default:false"`
-	Status     string  `json:"status" gorm:"type:varchar(20);default:'active'"`
-	Weight     float64 `json:"weight" gorm:"type:decimal(10,2)"`
-	Dimensions string  `json:"dimensions"`
-	BarCode    string  `json:"barCode"`
-	Product    Product `json:"-"`
+	ProductID       uint       `json:"productId" gorm:"not null;uniqueIndex:idx_product_variants_attributes,where:deleted_at IS NULL AND attribute_key <> ''"`
+	Name            string     `json:"name" gorm:"not null"`
+	SKU             string     `json:"sku" gorm:"uniqueIndex;not null"`
+	Price           float64    `json:"price" gorm:"not null"`
+	CostPrice       float64    `json:"costPrice" gorm:"type:decimal(12,2);default:0"`
+	Stock           int        `json:"stock" gorm:"not null"`
+	MinStock        *int       `json:"minStock"`        // Falls back to the product's MinStock when nil
+	ReorderQuantity int        `json:"reorderQuantity"` // Falls back to the product's ReorderQuantity when zero
+	LowStockAlertAt *time.Time `json:"lowStockAlertAt"`
+	ImageURL        string     `json:"imageUrl"`
+	ThumbnailURL    string     `json:"thumbnailUrl"`
+	Attributes      string     `json:"attributes" gorm:"type:jsonb"`                                           // JSON string storing variant attributes (color, size, etc.)
+	AttributeKey    string     `json:"-" gorm:"type:varchar(255);uniqueIndex:idx_product_variants_attributes"` // Normalized attributes, unique per product
+	IsDefault       bool       `json:"isDefault" gorm:"default:false"`
+	Status          string     `json:"status" gorm:"type:varchar(20);default:'active'"`
+	Weight          float64    `json:"weight" gorm:"type:decimal(10,2)"`
+	Dimensions      string     `json:"dimensions"`
+	BarCode         string     `json:"barCode" gorm:"index"`
+	Product         Product    `json:"-"`
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderClosed            = "closed"
+)
+
+type PurchaseOrder struct {
+	gorm.Model
+	Number     string              `json:"number" gorm:"uniqueIndex;not null"`
+	SupplierID uint                `json:"supplierId" gorm:"not null;index"`
+	Supplier   Supplier            `json:"supplier"`
+	Status     string              `json:"status" gorm:"type:varchar(20);not null;default:'draft';index"`
+	TotalCost  float64             `json:"totalCost" gorm:"type:decimal(12,2)"`
+	Notes      string              `json:"notes" gorm:"type:text"`
+	ExpectedAt *time.Time          `json:"expectedAt"`
+	SentAt     *time.Time          `json:"sentAt"`
+	ReceivedAt *time.Time          `json:"receivedAt"` // Set once every line is fully received
+	ClosedAt   *time.Time          `json:"closedAt"`
+	CreatedBy  *uint               `json:"createdBy"`
+	Items      []PurchaseOrderItem `json:"items"`
+}
+
+type PurchaseOrderItem struct {
+	gorm.Model
+	PurchaseOrderID  uint    `json:"purchaseOrderId" gorm:"not null;index"`
+	ProductID        uint    `json:"productId" gorm:"not null"`
+	VariantID        *uint   `json:"variantId"`
+	Name             string  `json:"name"` // Product and variant name at the time of ordering
+	SKU              string  `json:"sku"`
+	Quantity         int     `json:"quantity" gorm:"not null"` // In the purchase unit
+	ReceivedQuantity int     `json:"receivedQuantity" gorm:"not null;default:0"`
+	Unit             string  `json:"unit" gorm:"type:varchar(20)"`         // Purchase unit code when ordered
+	UnitFactor       int     `json:"unitFactor" gorm:"not null;default:1"` // Stock units per purchase unit when ordered
+	UnitCost         float64 `json:"unitCost" gorm:"type:decimal(12,2);not null"`
+	LineTotal        float64 `json:"lineTotal" gorm:"type:decimal(12,2);not null"`
+}
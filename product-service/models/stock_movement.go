@@ -0,0 +1,39 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	ItemTypeProduct    = "product"
+	ItemTypeVariant    = "variant"
+	ItemTypeAddon      = "addon"
+	ItemTypeIngredient = "ingredient"
+)
+
+const (
+	MovementSale        = "sale"
+	MovementReturn      = "return"
+	MovementAdjustment  = "adjustment"
+	MovementReceiving   = "receiving"
+	MovementTransfer    = "transfer"
+	MovementWaste       = "waste"
+	MovementConsumption = "consumption" // Ingredients used by the items of an order
+)
+
+// StockMovement is an entry in the inventory ledger. On-hand quantity of an
+// item is the sum of its movements; the Stock columns on products, variants,
+// addons and ingredients are a cached balance kept in step with the ledger.
+type StockMovement struct {
+	gorm.Model
+	ItemType     string  `json:"itemType" gorm:"type:varchar(20);not null;index:idx_stock_movements_item"`
+	ItemID       uint    `json:"itemId" gorm:"not null;index:idx_stock_movements_item"`
+	MovementType string  `json:"movementType" gorm:"type:varchar(20);not null;index"`
+	Quantity     int     `json:"quantity" gorm:"not null"` // Signed: positive adds stock, negative removes it
+	BalanceAfter int     `json:"balanceAfter" gorm:"not null"`
+	UnitCost     float64 `json:"unitCost" gorm:"type:decimal(12,4);not null;default:0"` // Cost per unit in, or cost of goods per unit out
+	Reason       string  `json:"reason"`
+	Reference    string  `json:"reference" gorm:"index"`                // Order number, purchase order, etc.
+	Outlet       string  `json:"outlet" gorm:"type:varchar(100);index"` // Outlet whose stock changed, if any
+	UserID       *uint   `json:"userId"`
+}
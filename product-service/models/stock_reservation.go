@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// StockReservation holds stock for a pending order. Reserved quantities are
+// not available to other reservations until the hold is released, expires or
+// is committed, at which point the stock leaves the ledger as a sale. Holds
+// backing a saved order have no expiry and last until the order is completed
+// or cancelled.
+type StockReservation struct {
+	gorm.Model
+	Key         string                 `json:"key" gorm:"type:varchar(100);uniqueIndex;not null"` // Idempotency key supplied by the caller
+	Reference   string                 `json:"reference" gorm:"index"`
+	Outlet      string                 `json:"outlet" gorm:"type:varchar(100)"` // Outlet the stock leaves from on commit, if any
+	Status      string                 `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	ExpiresAt   *time.Time             `json:"expiresAt" gorm:"index"` // Nil for holds kept until committed or released
+	CommittedAt *time.Time             `json:"committedAt"`
+	ReleasedAt  *time.Time             `json:"releasedAt"`
+	Items       []StockReservationItem `json:"items" gorm:"foreignKey:ReservationID"`
+}
+
+type StockReservationItem struct {
+	gorm.Model
+	ReservationID uint    `json:"reservationId" gorm:"not null;index"`
+	ItemType      string  `json:"itemType" gorm:"type:varchar(20);not null;index:idx_stock_reservation_items_item"`
+	ItemID        uint    `json:"itemId" gorm:"not null;index:idx_stock_reservation_items_item"`
+	Quantity      int     `json:"quantity" gorm:"not null"`               // In stock units
+	SellQuantity  float64 `json:"sellQuantity" gorm:"type:decimal(12,3)"` // Quantity in the unit it is sold in
+	UnitCost      float64 `json:"unitCost" gorm:"type:decimal(12,4)"`     // Cost price when reserved, cost of the sale once committed
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StockTakeOpen      = "open"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTake is a physical count session. System quantities are frozen when the
+// session starts, so sales during counting do not show up as variances.
+type StockTake struct {
+	gorm.Model
+	Number      string          `json:"number" gorm:"uniqueIndex;not null"`
+	Outlet      string          `json:"outlet"`
+	CategoryID  *uint           `json:"categoryId"` // Whole outlet when nil
+	Status      string          `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	Notes       string          `json:"notes" gorm:"type:text"`
+	StartedBy   *uint           `json:"startedBy"`
+	ApprovedBy  *uint           `json:"approvedBy"`
+	ApprovedAt  *time.Time      `json:"approvedAt"`
+	CancelledAt *time.Time      `json:"cancelledAt"`
+	Lines       []StockTakeLine `json:"lines,omitempty"`
+}
+
+type StockTakeLine struct {
+	gorm.Model
+	StockTakeID     uint       `json:"stockTakeId" gorm:"not null;uniqueIndex:idx_stock_take_lines_item"`
+	ItemType        string     `json:"itemType" gorm:"type:varchar(20);not null;uniqueIndex:idx_stock_take_lines_item"`
+	ItemID          uint       `json:"itemId" gorm:"not null;uniqueIndex:idx_stock_take_lines_item"`
+	Name            string     `json:"name"`
+	SKU             string     `json:"sku"`
+	BarCode         string     `json:"barCode"`
+	SystemQuantity  int        `json:"systemQuantity" gorm:"not null"`
+	CountedQuantity *int       `json:"countedQuantity"` // Nil until the item is counted
+	Variance        int        `json:"variance"`
+	UnitCost        float64    `json:"unitCost" gorm:"type:decimal(12,4)"`
+	VarianceValue   float64    `json:"varianceValue" gorm:"type:decimal(12,2)"`
+	CountedBy       *uint      `json:"countedBy"`
+	CountedAt       *time.Time `json:"countedAt"`
+	MovementID      *uint      `json:"movementId"` // Adjustment posted on approval
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StockTransferDraft     = "draft"
+	StockTransferInTransit = "in_transit"
+	StockTransferReceived  = "received"
+	StockTransferCancelled = "cancelled"
+)
+
+// StockTransfer moves goods from one outlet to another. Stock leaves the source
+// when the transfer is sent and arrives at the destination when it is received,
+// so goods in transit are on hand at neither.
+type StockTransfer struct {
+	gorm.Model
+	Number            string              `json:"number" gorm:"uniqueIndex;not null"`
+	SourceOutlet      string              `json:"sourceOutlet" gorm:"type:varchar(100);not null;index"`
+	DestinationOutlet string              `json:"destinationOutlet" gorm:"type:varchar(100);not null;index"`
+	Status            string              `json:"status" gorm:"type:varchar(20);not null;default:'draft';index"`
+	Notes             string              `json:"notes" gorm:"type:text"`
+	CreatedBy         *uint               `json:"createdBy"`
+	SentBy            *uint               `json:"sentBy"`
+	SentAt            *time.Time          `json:"sentAt"`
+	ReceivedBy        *uint               `json:"receivedBy"`
+	ReceivedAt        *time.Time          `json:"receivedAt"`
+	CancelledAt       *time.Time          `json:"cancelledAt"`
+	Items             []StockTransferItem `json:"items"`
+}
+
+type StockTransferItem struct {
+	gorm.Model
+	StockTransferID   uint    `json:"stockTransferId" gorm:"not null;index"`
+	ItemType          string  `json:"itemType" gorm:"type:varchar(20);not null"`
+	ItemID            uint    `json:"itemId" gorm:"not null"`
+	Name              string  `json:"name"`
+	SKU               string  `json:"sku"`
+	Quantity          int     `json:"quantity" gorm:"not null"`
+	ReceivedQuantity  *int    `json:"receivedQuantity"` // Nil until received
+	Discrepancy       int     `json:"discrepancy"`      // Sent less received
+	DiscrepancyReason string  `json:"discrepancyReason"`
+	UnitCost          float64 `json:"unitCost" gorm:"type:decimal(12,4)"` // Cost of the goods when sent
+	SendMovementID    *uint   `json:"sendMovementId"`
+	ReceiveMovementID *uint   `json:"receiveMovementId"`
+	ReturnMovementID  *uint   `json:"returnMovementId"` // Shortage booked back to the source
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Supplier struct {
+	gorm.Model
+	Name         string `json:"name" gorm:"not null"`
+	ContactName  string `json:"contactName"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Address      string `json:"address" gorm:"type:text"`
+	TaxNumber    string `json:"taxNumber"`
+	PaymentTerms string `json:"paymentTerms"` // e.g. "NET 30"
+	Notes        string `json:"notes" gorm:"type:text"`
+	IsActive     bool   `json:"isActive" gorm:"default:true"`
+}
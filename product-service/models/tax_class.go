@@ -0,0 +1,17 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// TaxClass is a tax charged on sales, such as PB1 on restaurant sales or PPN
+// (VAT). Prices of items in an inclusive class already contain the tax; for
+// exclusive classes it is added on top at checkout.
+type TaxClass struct {
+	gorm.Model
+	Code      string  `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name      string  `json:"name" gorm:"not null"`
+	Rate      float64 `json:"rate" gorm:"type:decimal(5,2);not null;default:0"` // Percent, 0 for exempt items
+	Inclusive bool    `json:"inclusive" gorm:"default:false"`
+	IsDefault bool    `json:"isDefault" gorm:"default:false"` // Applies to items without a class of their own
+}
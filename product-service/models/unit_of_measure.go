@@ -0,0 +1,22 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+const (
+	DimensionCount  = "count"
+	DimensionWeight = "weight"
+	DimensionVolume = "volume"
+)
+
+// UnitOfMeasure is a unit items are stocked, sold or bought in. Factor is its
+// size in the base unit of its dimension (pieces, grams or millilitres), so a
+// kg has a factor of 1000 and a carton of 24 a factor of 24.
+type UnitOfMeasure struct {
+	gorm.Model
+	Code      string  `json:"code" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Name      string  `json:"name" gorm:"not null"`
+	Dimension string  `json:"dimension" gorm:"type:varchar(20);not null"` // count, weight or volume
+	Factor    float64 `json:"factor" gorm:"type:decimal(14,4);not null;default:1"`
+}
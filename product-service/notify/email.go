@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+)
+
+// EmailNotifier sends a plain text summary of low-stock items over SMTP.
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+func NewEmailNotifier(host, port, username, password, from string, to []string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailNotifier{addr: net.JoinHostPort(host, port), auth: auth, from: from, to: to}
+}
+
+// NewEmailNotifierFromEnv reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD, LOW_STOCK_EMAIL_FROM and LOW_STOCK_EMAIL_TO.
+func NewEmailNotifierFromEnv() (*EmailNotifier, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required for the email notifier")
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	var to []string
+	for _, address := range strings.Split(os.Getenv("LOW_STOCK_EMAIL_TO"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			to = append(to, address)
+		}
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("LOW_STOCK_EMAIL_TO is required for the email notifier")
+	}
+
+	from := os.Getenv("LOW_STOCK_EMAIL_FROM")
+	if from == "" {
+		from = "yourkasa@" + host
+	}
+
+	return NewEmailNotifier(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from, to), nil
+}
+
+func (n *EmailNotifier) NotifyLowStock(_ context.Context, items []inventory.LowStockItem) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", n.from)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&body, "Subject: Low stock: %d item(s) need reordering\r\n", len(items))
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+
+	body.WriteString("The following items are at or below their reorder point:\r\n\r\n")
+	for _, item := range items {
+		fmt.Fprintf(&body, "- %s (%s): %d available, reorder point %d, suggested order %d\r\n",
+			itemLabel(item), item.SKU, item.Available, item.MinStock, item.SuggestedQuantity)
+	}
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, []byte(body.String())); err != nil {
+		return fmt.Errorf("low-stock email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"context"
+	"log"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+)
+
+// LogNotifier writes alerts to the service log.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) NotifyLowStock(_ context.Context, items []inventory.LowStockItem) error {
+	for _, item := range items {
+		log.Printf("Low stock: %s (%s) has %d available, reorder point %d, suggested order %d",
+			itemLabel(item), item.SKU, item.Available, item.MinStock, item.SuggestedQuantity)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ridhotamma/yourkasa/product-service/inventory"
+)
+
+// NewFromEnv builds the low-stock notifier selected by LOW_STOCK_NOTIFIERS, a
+// comma-separated list of log, webhook and email. It defaults to log.
+func NewFromEnv() (inventory.Notifier, error) {
+	var notifiers Multi
+	for _, name := range strings.Split(os.Getenv("LOW_STOCK_NOTIFIERS"), ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "log":
+			notifiers = append(notifiers, NewLogNotifier())
+		case "webhook":
+			url := os.Getenv("LOW_STOCK_WEBHOOK_URL")
+			if url == "" {
+				return nil, fmt.Errorf("LOW_STOCK_WEBHOOK_URL is required for the webhook notifier")
+			}
+			notifiers = append(notifiers, NewWebhookNotifier(url, os.Getenv("LOW_STOCK_WEBHOOK_SECRET")))
+		case "email":
+			notifier, err := NewEmailNotifierFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			notifiers = append(notifiers, notifier)
+		default:
+			return nil, fmt.Errorf("unknown low-stock notifier %q", name)
+		}
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return notifiers, nil
+}
+
+// Multi sends alerts through every notifier and reports all failures.
+type Multi []inventory.Notifier
+
+func (m Multi) NotifyLowStock(ctx context.Context, items []inventory.LowStockItem) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.NotifyLowStock(ctx, items); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func itemLabel(item inventory.LowStockItem) string {
+	if item.VariantName != "" {
+		return item.Name + " - " + item.VariantName
+	}
+	return item.Name
+}
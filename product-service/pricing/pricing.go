@@ -0,0 +1,191 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidItemType = errors.New("item type must be product or variant")
+	ErrItemNotFound    = errors.New("item not found")
+	ErrOverlap         = errors.New("schedule overlaps another schedule of the same item")
+	ErrInvalidPeriod   = errors.New("endsAt must be after startsAt")
+)
+
+var itemTables = map[string]string{
+	models.ItemTypeProduct: "products",
+	models.ItemTypeVariant: "product_variants",
+}
+
+// Item identifies a product or variant to price.
+type Item struct {
+	ItemType string `json:"itemType"`
+	ItemID   uint   `json:"itemId"`
+}
+
+// Price is what an item sells for. ScheduleID and EndsAt are set while a
+// scheduled price is in force.
+type Price struct {
+	ItemType     string     `json:"itemType"`
+	ItemID       uint       `json:"itemId"`
+	RegularPrice float64    `json:"regularPrice"`
+	Price        float64    `json:"price"`
+	ScheduleID   *uint      `json:"scheduleId,omitempty"`
+	EndsAt       *time.Time `json:"endsAt,omitempty"`
+}
+
+// Scheduled returns the schedules in force at a time for the given items of
+// one type, keyed by item ID. Items without one are absent.
+func Scheduled(db *gorm.DB, itemType string, itemIDs []uint, at time.Time) (map[uint]models.PriceSchedule, error) {
+	schedules := map[uint]models.PriceSchedule{}
+	if len(itemIDs) == 0 {
+		return schedules, nil
+	}
+
+	var active []models.PriceSchedule
+	if err := db.Where("item_type = ? AND item_id IN ?", itemType, itemIDs).
+		Where("starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", at, at).
+		Order("starts_at asc").
+		Find(&active).Error; err != nil {
+		return nil, err
+	}
+
+	// Schedules cannot overlap, but if they ever do the latest start wins
+	for _, schedule := range active {
+		schedules[schedule.ItemID] = schedule
+	}
+	return schedules, nil
+}
+
+// Effective returns the price of one item at a time given its regular price.
+func Effective(db *gorm.DB, itemType string, itemID uint, regularPrice float64, at time.Time) (Price, error) {
+	price := Price{ItemType: itemType, ItemID: itemID, RegularPrice: regularPrice, Price: regularPrice}
+
+	schedules, err := Scheduled(db, itemType, []uint{itemID}, at)
+	if err != nil {
+		return price, err
+	}
+	if schedule, ok := schedules[itemID]; ok {
+		price.apply(schedule)
+	}
+	return price, nil
+}
+
+// Resolve looks up the regular price of each item and applies the schedules
+// in force at a time. Prices are returned in the order of items.
+func Resolve(db *gorm.DB, items []Item, at time.Time) ([]Price, error) {
+	ids := map[string][]uint{}
+	for _, item := range items {
+		if _, ok := itemTables[item.ItemType]; !ok {
+			return nil, ErrInvalidItemType
+		}
+		ids[item.ItemType] = append(ids[item.ItemType], item.ItemID)
+	}
+
+	regular := map[string]float64{}
+	scheduled := map[string]models.PriceSchedule{}
+	for itemType, itemIDs := range ids {
+		var rows []struct {
+			ID    uint
+			Price float64
+		}
+		if err := db.Table(itemTables[itemType]).
+			Select("id, price").
+			Where("id IN ? AND deleted_at IS NULL", itemIDs).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			regular[key(itemType, row.ID)] = row.Price
+		}
+
+		schedules, err := Scheduled(db, itemType, itemIDs, at)
+		if err != nil {
+			return nil, err
+		}
+		for itemID, schedule := range schedules {
+			scheduled[key(itemType, itemID)] = schedule
+		}
+	}
+
+	prices := make([]Price, len(items))
+	for i, item := range items {
+		regularPrice, ok := regular[key(item.ItemType, item.ItemID)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %d", ErrItemNotFound, item.ItemType, item.ItemID)
+		}
+		prices[i] = Price{ItemType: item.ItemType, ItemID: item.ItemID, RegularPrice: regularPrice, Price: regularPrice}
+		if schedule, ok := scheduled[key(item.ItemType, item.ItemID)]; ok {
+			prices[i].apply(schedule)
+		}
+	}
+	return prices, nil
+}
+
+// CheckSchedule validates a schedule's period and rejects it when it overlaps
+// another schedule of the same item. excludeID skips the schedule being
+// edited.
+func CheckSchedule(db *gorm.DB, schedule *models.PriceSchedule, excludeID uint) error {
+	table, ok := itemTables[schedule.ItemType]
+	if !ok {
+		return ErrInvalidItemType
+	}
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(schedule.StartsAt) {
+		return ErrInvalidPeriod
+	}
+
+	var exists int64
+	if err := db.Table(table).Where("id = ? AND deleted_at IS NULL", schedule.ItemID).Count(&exists).Error; err != nil {
+		return err
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: %s %d", ErrItemNotFound, schedule.ItemType, schedule.ItemID)
+	}
+
+	query := db.Model(&models.PriceSchedule{}).
+		Where("item_type = ? AND item_id = ? AND id <> ?", schedule.ItemType, schedule.ItemID, excludeID).
+		Where("ends_at IS NULL OR ends_at > ?", schedule.StartsAt)
+	if schedule.EndsAt != nil {
+		query = query.Where("starts_at < ?", *schedule.EndsAt)
+	}
+
+	var overlapping int64
+	if err := query.Count(&overlapping).Error; err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return ErrOverlap
+	}
+	return nil
+}
+
+// RecordChange adds a price history entry when an item's regular price is set
+// or changes. oldPrice is nil when the item is new.
+func RecordChange(tx *gorm.DB, itemType string, itemID uint, oldPrice *float64, newPrice float64, source string, userID *uint) error {
+	if oldPrice != nil && *oldPrice == newPrice {
+		return nil
+	}
+	return tx.Create(&models.PriceHistory{
+		ItemType: itemType,
+		ItemID:   itemID,
+		OldPrice: oldPrice,
+		NewPrice: newPrice,
+		Source:   source,
+		UserID:   userID,
+	}).Error
+}
+
+func (p *Price) apply(schedule models.PriceSchedule) {
+	id := schedule.ID
+	p.Price = schedule.Price
+	p.ScheduleID = &id
+	p.EndsAt = schedule.EndsAt
+}
+
+func key(itemType string, itemID uint) string {
+	return fmt.Sprintf("%s:%d", itemType, itemID)
+}
@@ -0,0 +1,142 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ridhotamma/yourkasa/product-service/models"
+	"gorm.io/gorm"
+)
+
+var ErrTaxItemType = errors.New("item type must be product, variant, addon or group")
+
+// taxClassSQL selects the tax class assigned to items of each type. Variants
+// are taxed like their product.
+var taxClassSQL = map[string]string{
+	models.ItemTypeProduct: `SELECT id, tax_class_id FROM products WHERE id IN @ids AND deleted_at IS NULL`,
+	models.ItemTypeVariant: `SELECT v.id, p.tax_class_id FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.id IN @ids AND v.deleted_at IS NULL`,
+	models.ItemTypeAddon: `SELECT id, tax_class_id FROM product_addons WHERE id IN @ids AND deleted_at IS NULL`,
+	models.ItemTypeGroup: `SELECT id, tax_class_id FROM product_groups WHERE id IN @ids AND deleted_at IS NULL`,
+}
+
+// Tax is the tax class an item is sold under. Items without a class and no
+// default class configured have a nil TaxClassID and a zero rate.
+type Tax struct {
+	ItemType   string  `json:"itemType"`
+	ItemID     uint    `json:"itemId"`
+	TaxClassID *uint   `json:"taxClassId"`
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	Rate       float64 `json:"rate"`
+	Inclusive  bool    `json:"inclusive"`
+}
+
+// DefaultTaxClass returns the class applied to items without one, or nil when
+// none is marked as default.
+func DefaultTaxClass(db *gorm.DB) (*models.TaxClass, error) {
+	var taxClass models.TaxClass
+	result := db.Where("is_default = ?", true).Order("id asc").Limit(1).Find(&taxClass)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &taxClass, nil
+}
+
+// ResolveTax returns the tax class of each item, falling back to the default
+// class. Taxes are returned in the order of items.
+func ResolveTax(db *gorm.DB, items []Item) ([]Tax, error) {
+	ids := map[string][]uint{}
+	for _, item := range items {
+		if _, ok := taxClassSQL[item.ItemType]; !ok {
+			return nil, ErrTaxItemType
+		}
+		ids[item.ItemType] = append(ids[item.ItemType], item.ItemID)
+	}
+
+	assigned := map[string]*uint{}
+	for itemType, itemIDs := range ids {
+		var rows []struct {
+			ID         uint
+			TaxClassID *uint
+		}
+		if err := db.Raw(taxClassSQL[itemType], map[string]interface{}{"ids": itemIDs}).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			assigned[key(itemType, row.ID)] = row.TaxClassID
+		}
+	}
+
+	var taxClasses []models.TaxClass
+	if err := db.Find(&taxClasses).Error; err != nil {
+		return nil, err
+	}
+	classes := map[uint]models.TaxClass{}
+	var fallback *models.TaxClass
+	for i, taxClass := range taxClasses {
+		classes[taxClass.ID] = taxClass
+		if taxClass.IsDefault && (fallback == nil || taxClass.ID < fallback.ID) {
+			fallback = &taxClasses[i]
+		}
+	}
+
+	taxes := make([]Tax, len(items))
+	for i, item := range items {
+		taxClassID, ok := assigned[key(item.ItemType, item.ItemID)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %d", ErrItemNotFound, item.ItemType, item.ItemID)
+		}
+
+		taxes[i] = Tax{ItemType: item.ItemType, ItemID: item.ItemID}
+		taxClass, found := models.TaxClass{}, false
+		if taxClassID != nil {
+			taxClass, found = classes[*taxClassID]
+		}
+		if !found && fallback != nil {
+			taxClass, found = *fallback, true
+		}
+		if found {
+			id := taxClass.ID
+			taxes[i].TaxClassID = &id
+			taxes[i].Code = taxClass.Code
+			taxes[i].Name = taxClass.Name
+			taxes[i].Rate = taxClass.Rate
+			taxes[i].Inclusive = taxClass.Inclusive
+		}
+	}
+	return taxes, nil
+}
+
+// SetDefaultTaxClass marks one class as the default and clears the flag on
+// the others.
+func SetDefaultTaxClass(tx *gorm.DB, id uint) error {
+	if err := tx.Model(&models.TaxClass{}).Where("id <> ? AND is_default = ?", id, true).
+		Update("is_default", false).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.TaxClass{}).Where("id = ?", id).Update("is_default", true).Error
+}
+
+// SeedTaxClasses creates the common Indonesian tax classes the first time the
+// service starts. PB1 is the default so untaxed items keep the 10% checkout
+// previously charged on every order.
+func SeedTaxClasses(db *gorm.DB) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.TaxClass{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return db.Create(&[]models.TaxClass{
+		{Code: "PB1", Name: "Pajak Restoran (PB1)", Rate: 10, IsDefault: true},
+		{Code: "PPN", Name: "Pajak Pertambahan Nilai (PPN)", Rate: 11, Inclusive: true},
+		{Code: "EXEMPT", Name: "Tax exempt", Rate: 0},
+	}).Error
+}
@@ -4,16 +4,53 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/ridhotamma/yourkasa/product-service/controllers"
 	"github.com/ridhotamma/yourkasa/product-service/middleware"
+	"github.com/ridhotamma/yourkasa/product-service/storage"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(r *gin.Engine, db *gorm.DB) {
+func SetupRoutes(r *gin.Engine, db *gorm.DB, images storage.Storage) {
 	// Initialize all controllers
 	productController := controllers.NewProductController(db)
 	categoryController := controllers.NewCategoryController(db)
 	groupController := controllers.NewGroupController(db)
 	variantController := controllers.NewVariantController(db)
 	addonController := controllers.NewAddonController(db)
+	labelController := controllers.NewLabelController(db)
+	catalogController := controllers.NewCatalogController(db)
+	inventoryController := controllers.NewInventoryController(db)
+	reservationController := controllers.NewReservationController(db)
+	supplierController := controllers.NewSupplierController(db)
+	purchaseOrderController := controllers.NewPurchaseOrderController(db)
+	stockTakeController := controllers.NewStockTakeController(db)
+	stockTransferController := controllers.NewStockTransferController(db)
+	modifierGroupController := controllers.NewModifierGroupController(db)
+	priceController := controllers.NewPriceController(db)
+	availabilityController := controllers.NewAvailabilityController(db)
+	taxClassController := controllers.NewTaxClassController(db)
+	unitController := controllers.NewUnitController(db)
+	ingredientController := controllers.NewIngredientController(db)
+	recipeController := controllers.NewRecipeController(db)
+	imageController := controllers.NewImageController(db, images)
+
+	// Images kept on local disk are served by the service itself
+	if local, ok := images.(*storage.LocalStorage); ok {
+		r.Static(storage.LocalPath, local.Dir)
+	}
+
+	// Service-to-service routes, not exposed through the gateway
+	internal := r.Group("/internal")
+	internal.Use(middleware.RequireServiceToken())
+	{
+		internal.POST("/reservations", reservationController.Reserve)
+		internal.GET("/reservations/:key", reservationController.GetByKey)
+		internal.POST("/reservations/:key/commit", reservationController.Commit)
+		internal.POST("/reservations/:key/release", reservationController.Release)
+		internal.POST("/modifiers/validate", modifierGroupController.Validate)
+		internal.POST("/bundles/quote", groupController.Quote)
+		internal.POST("/prices/resolve", priceController.Resolve)
+		internal.POST("/availability/check", availabilityController.Check)
+		internal.POST("/taxes/resolve", taxClassController.Resolve)
+	}
 
 	api := r.Group("/api/v1")
 	{
@@ -22,16 +59,30 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 		products.Use(middleware.AuthMiddleware())
 		{
 			// Public routes (require authentication)
+			products.GET("/search", productController.Search)
+			products.GET("/lookup", productController.Lookup)
 			products.GET("/:id", productController.GetByID)
+			products.GET("/:id/availability", availabilityController.GetProductRules)
+			products.GET("/:id/recipe", recipeController.GetProductRecipe)
+			products.GET("/:id/images", imageController.ListProductImages)
 			products.GET("/", productController.List)
 
 			// Admin/Owner only routes
 			authorizedProducts := products.Group("/")
 			authorizedProducts.Use(middleware.RequireRole("admin", "owner"))
 			{
+				authorizedProducts.GET("/export", catalogController.Export)
+				authorizedProducts.POST("/import", catalogController.Import)
 				authorizedProducts.POST("/", productController.Create)
 				authorizedProducts.PUT("/:id", productController.Update)
 				authorizedProducts.DELETE("/:id", productController.Delete)
+				authorizedProducts.PUT("/:id/modifier-groups", modifierGroupController.AssignToProduct)
+				authorizedProducts.PUT("/:id/availability", availabilityController.SetProductRules)
+				authorizedProducts.PUT("/:id/recipe", recipeController.SetProductRecipe)
+				authorizedProducts.POST("/:id/images", imageController.UploadProductImage)
+				authorizedProducts.PUT("/:id/images/order", imageController.ReorderProductImages)
+				authorizedProducts.PUT("/:id/images/:imageId", imageController.UpdateProductImage)
+				authorizedProducts.DELETE("/:id/images/:imageId", imageController.DeleteProductImage)
 			}
 		}
 
@@ -40,6 +91,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 		categories.Use(middleware.AuthMiddleware())
 		{
 			// Public routes
+			categories.GET("/tree", categoryController.Tree)
 			categories.GET("/:id", categoryController.GetByID)
 			categories.GET("/", categoryController.List)
 
@@ -48,8 +100,10 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 			authorizedCategories.Use(middleware.RequireRole("admin", "owner"))
 			{
 				authorizedCategories.POST("/", categoryController.Create)
+				authorizedCategories.PUT("/reorder", categoryController.Reorder)
 				authorizedCategories.PUT("/:id", categoryController.Update)
 				authorizedCategories.DELETE("/:id", categoryController.Delete)
+				authorizedCategories.PUT("/:id/image", imageController.UploadCategoryImage)
 			}
 		}
 
@@ -59,6 +113,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 		{
 			// Public routes
 			groups.GET("/:id", groupController.GetByID)
+			groups.GET("/:id/bundle", groupController.GetBundle)
+			groups.GET("/:id/availability", availabilityController.GetGroupRules)
 			groups.GET("/", groupController.List)
 
 			// Admin/Owner only routes
@@ -68,8 +124,11 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				authorizedGroups.POST("/", groupController.Create)
 				authorizedGroups.PUT("/:id", groupController.Update)
 				authorizedGroups.DELETE("/:id", groupController.Delete)
+				authorizedGroups.PUT("/:id/image", imageController.UploadGroupImage)
 				authorizedGroups.POST("/:id/products", groupController.AddProductToGroup)
 				authorizedGroups.DELETE("/:id/products/:productId", groupController.RemoveProductFromGroup)
+				authorizedGroups.PUT("/:id/components", groupController.SetComponents)
+				authorizedGroups.PUT("/:id/availability", availabilityController.SetGroupRules)
 			}
 		}
 
@@ -79,7 +138,9 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 		{
 			// Public routes
 			variants.GET("/:id", variantController.GetByID)
+			variants.GET("/:id/recipe", recipeController.GetVariantRecipe)
 			variants.GET("/product/:productId", variantController.GetByProductID)
+			variants.GET("/product/:productId/options", variantController.GetOptions)
 
 			// Admin/Owner only routes
 			authorizedVariants := variants.Group("/")
@@ -88,6 +149,105 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				authorizedVariants.POST("/", variantController.Create)
 				authorizedVariants.PUT("/:id", variantController.Update)
 				authorizedVariants.DELETE("/:id", variantController.Delete)
+				authorizedVariants.PUT("/:id/recipe", recipeController.SetVariantRecipe)
+				authorizedVariants.PUT("/:id/image", imageController.UploadVariantImage)
+				authorizedVariants.PUT("/product/:productId/options", variantController.SetOptions)
+				authorizedVariants.POST("/product/:productId/generate", variantController.Generate)
+			}
+		}
+
+		// Barcode and label routes
+		labels := api.Group("/labels")
+		labels.Use(middleware.AuthMiddleware())
+		{
+			labels.GET("/barcode", labelController.BarcodeImage)
+
+			authorizedLabels := labels.Group("/")
+			authorizedLabels.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedLabels.POST("/generate-barcodes", labelController.GenerateBarcodes)
+				authorizedLabels.POST("/sheet", labelController.Sheet)
+			}
+		}
+
+		// Inventory ledger routes
+		inventory := api.Group("/inventory")
+		inventory.Use(middleware.AuthMiddleware())
+		{
+			inventory.GET("/movements", inventoryController.ListMovements)
+			inventory.GET("/low-stock", inventoryController.LowStock)
+			inventory.GET("/outlets", inventoryController.OutletStock)
+			inventory.GET("/:itemType/:itemId", inventoryController.StockLevel)
+
+			authorizedInventory := inventory.Group("/")
+			authorizedInventory.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedInventory.POST("/movements", inventoryController.RecordMovement)
+				authorizedInventory.POST("/outlets/assign", inventoryController.AssignOutletStock)
+			}
+		}
+
+		// Supplier routes
+		suppliers := api.Group("/suppliers")
+		suppliers.Use(middleware.AuthMiddleware())
+		suppliers.Use(middleware.RequireRole("admin", "owner"))
+		{
+			suppliers.GET("/:id", supplierController.GetByID)
+			suppliers.GET("/", supplierController.List)
+			suppliers.POST("/", supplierController.Create)
+			suppliers.PUT("/:id", supplierController.Update)
+			suppliers.DELETE("/:id", supplierController.Delete)
+		}
+
+		// Purchase order routes
+		purchaseOrders := api.Group("/purchase-orders")
+		purchaseOrders.Use(middleware.AuthMiddleware())
+		purchaseOrders.Use(middleware.RequireRole("admin", "owner"))
+		{
+			purchaseOrders.GET("/:id", purchaseOrderController.GetByID)
+			purchaseOrders.GET("/", purchaseOrderController.List)
+			purchaseOrders.POST("/", purchaseOrderController.Create)
+			purchaseOrders.PUT("/:id", purchaseOrderController.Update)
+			purchaseOrders.DELETE("/:id", purchaseOrderController.Delete)
+			purchaseOrders.POST("/:id/send", purchaseOrderController.Send)
+			purchaseOrders.POST("/:id/receive", purchaseOrderController.Receive)
+			purchaseOrders.POST("/:id/close", purchaseOrderController.Close)
+		}
+
+		// Stock take routes
+		stockTakes := api.Group("/stock-takes")
+		stockTakes.Use(middleware.AuthMiddleware())
+		{
+			stockTakes.GET("/", stockTakeController.List)
+			stockTakes.GET("/:id", stockTakeController.GetByID)
+			stockTakes.GET("/:id/variances", stockTakeController.Variances)
+			stockTakes.POST("/:id/counts", stockTakeController.RecordCounts)
+
+			authorizedStockTakes := stockTakes.Group("/")
+			authorizedStockTakes.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedStockTakes.POST("/", stockTakeController.Start)
+				authorizedStockTakes.POST("/:id/approve", stockTakeController.Approve)
+				authorizedStockTakes.POST("/:id/cancel", stockTakeController.Cancel)
+			}
+		}
+
+		// Stock transfer routes
+		stockTransfers := api.Group("/stock-transfers")
+		stockTransfers.Use(middleware.AuthMiddleware())
+		{
+			stockTransfers.GET("/", stockTransferController.List)
+			stockTransfers.GET("/:id", stockTransferController.GetByID)
+			stockTransfers.POST("/:id/receive", stockTransferController.Receive)
+
+			authorizedStockTransfers := stockTransfers.Group("/")
+			authorizedStockTransfers.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedStockTransfers.POST("/", stockTransferController.Create)
+				authorizedStockTransfers.PUT("/:id", stockTransferController.Update)
+				authorizedStockTransfers.DELETE("/:id", stockTransferController.Delete)
+				authorizedStockTransfers.POST("/:id/send", stockTransferController.Send)
+				authorizedStockTransfers.POST("/:id/cancel", stockTransferController.Cancel)
 			}
 		}
 
@@ -97,6 +257,7 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 		{
 			// Public routes
 			addons.GET("/:id", addonController.GetByID)
+			addons.GET("/:id/recipe", recipeController.GetAddonRecipe)
 			addons.GET("/product/:productId", addonController.GetByProductID)
 
 			// Admin/Owner only routes
@@ -106,6 +267,99 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				authorizedAddons.POST("/", addonController.Create)
 				authorizedAddons.PUT("/:id", addonController.Update)
 				authorizedAddons.DELETE("/:id", addonController.Delete)
+				authorizedAddons.PUT("/:id/image", imageController.UploadAddonImage)
+				authorizedAddons.PUT("/:id/recipe", recipeController.SetAddonRecipe)
+			}
+		}
+
+		// Modifier group routes
+		modifierGroups := api.Group("/modifier-groups")
+		modifierGroups.Use(middleware.AuthMiddleware())
+		{
+			// Public routes
+			modifierGroups.GET("/", modifierGroupController.List)
+			modifierGroups.GET("/:id", modifierGroupController.GetByID)
+
+			// Admin/Owner only routes
+			authorizedModifierGroups := modifierGroups.Group("/")
+			authorizedModifierGroups.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedModifierGroups.POST("/", modifierGroupController.Create)
+				authorizedModifierGroups.PUT("/:id", modifierGroupController.Update)
+				authorizedModifierGroups.DELETE("/:id", modifierGroupController.Delete)
+			}
+		}
+
+		// Tax class routes
+		taxClasses := api.Group("/tax-classes")
+		taxClasses.Use(middleware.AuthMiddleware())
+		{
+			// Public routes
+			taxClasses.GET("/", taxClassController.List)
+			taxClasses.GET("/:id", taxClassController.GetByID)
+
+			// Admin/Owner only routes
+			authorizedTaxClasses := taxClasses.Group("/")
+			authorizedTaxClasses.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedTaxClasses.POST("/", taxClassController.Create)
+				authorizedTaxClasses.PUT("/:id", taxClassController.Update)
+				authorizedTaxClasses.DELETE("/:id", taxClassController.Delete)
+			}
+		}
+
+		// Unit of measure routes
+		units := api.Group("/units")
+		units.Use(middleware.AuthMiddleware())
+		{
+			// Public routes
+			units.GET("/", unitController.List)
+			units.GET("/:id", unitController.GetByID)
+
+			// Admin/Owner only routes
+			authorizedUnits := units.Group("/")
+			authorizedUnits.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedUnits.POST("/", unitController.Create)
+				authorizedUnits.PUT("/:id", unitController.Update)
+				authorizedUnits.DELETE("/:id", unitController.Delete)
+			}
+		}
+
+		// Ingredient routes
+		ingredients := api.Group("/ingredients")
+		ingredients.Use(middleware.AuthMiddleware())
+		{
+			// Public routes
+			ingredients.GET("/", ingredientController.List)
+			ingredients.GET("/:id", ingredientController.GetByID)
+
+			// Admin/Owner only routes
+			authorizedIngredients := ingredients.Group("/")
+			authorizedIngredients.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedIngredients.GET("/usage", ingredientController.Usage)
+				authorizedIngredients.POST("/", ingredientController.Create)
+				authorizedIngredients.PUT("/:id", ingredientController.Update)
+				authorizedIngredients.DELETE("/:id", ingredientController.Delete)
+			}
+		}
+
+		// Price schedule and history routes
+		prices := api.Group("/prices")
+		prices.Use(middleware.AuthMiddleware())
+		{
+			prices.GET("/effective", priceController.Effective)
+			prices.GET("/history", priceController.History)
+			prices.GET("/schedules", priceController.ListSchedules)
+			prices.GET("/schedules/:id", priceController.GetSchedule)
+
+			authorizedPrices := prices.Group("/")
+			authorizedPrices.Use(middleware.RequireRole("admin", "owner"))
+			{
+				authorizedPrices.POST("/schedules", priceController.CreateSchedule)
+				authorizedPrices.PUT("/schedules/:id", priceController.UpdateSchedule)
+				authorizedPrices.DELETE("/schedules/:id", priceController.DeleteSchedule)
 			}
 		}
 	}
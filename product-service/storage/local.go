@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalPath is where product-service serves files kept in local storage.
+const LocalPath = "/uploads"
+
+// LocalStorage keeps files in a directory that product-service serves under
+// LocalPath.
+type LocalStorage struct {
+	Dir     string
+	baseURL string
+}
+
+// NewLocalStorage stores files in dir and links them under baseURL, e.g.
+// /uploads or a CDN in front of the gateway.
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// NewLocalStorageFromEnv reads IMAGE_STORAGE_DIR, defaulting to uploads, and
+// IMAGE_BASE_URL, defaulting to LocalPath.
+func NewLocalStorageFromEnv() *LocalStorage {
+	dir := os.Getenv("IMAGE_STORAGE_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("IMAGE_BASE_URL")
+	if baseURL == "" {
+		baseURL = LocalPath
+	}
+	return NewLocalStorage(dir, baseURL)
+}
+
+func (s *LocalStorage) Put(_ context.Context, key string, data []byte, _ string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so readers never see a partial image
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return s.baseURL + "/" + key, nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) Key(url string) (string, bool) {
+	return keyFromURL(s.baseURL, url)
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files, such as product images, and serves them from
+// a public URL.
+type Storage interface {
+	// Put stores data under key, replacing any file already there, and
+	// returns its public URL.
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	// Delete removes the file under key. Deleting a missing file is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// Key returns the key of a URL returned by Put, or false when the URL
+	// points elsewhere, e.g. an image linked by hand.
+	Key(url string) (string, bool)
+}
+
+// NewFromEnv builds the storage selected by IMAGE_STORAGE, local or s3. It
+// defaults to local.
+func NewFromEnv() (Storage, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("IMAGE_STORAGE"))) {
+	case "", "local":
+		return NewLocalStorageFromEnv(), nil
+	case "s3":
+		return NewS3StorageFromEnv()
+	default:
+		return nil, fmt.Errorf("unknown image storage %q", os.Getenv("IMAGE_STORAGE"))
+	}
+}
+
+// checkKey rejects keys that could escape the storage root.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+func keyFromURL(baseURL, url string) (string, bool) {
+	prefix := strings.TrimRight(baseURL, "/") + "/"
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(url, prefix)
+	return key, checkKey(key) == nil
+}
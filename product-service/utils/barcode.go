@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCheckDigit = errors.New("invalid barcode check digit")
+
+// ScannedCode is a scanner input normalised for lookup.
+type ScannedCode struct {
+	Raw        string
+	Candidates []string // Codes to look up, most specific first
+	Weighted   bool
+	Price      *float64 // Price embedded in a weighted barcode
+	WeightKg   *float64 // Weight embedded in a weighted barcode
+}
+
+// ParseScannedCode validates EAN-13 and UPC-A check digits and decodes
+// in-store weighted barcodes (EAN-13 prefixes 20-29). Anything that is not a
+// 12 or 13 digit number is treated as a SKU or custom barcode. A 12 or 13
+// digit number with a wrong check digit returns ErrInvalidCheckDigit along
+// with the raw code alone, so callers can still match it as a numeric SKU.
+func ParseScannedCode(code string) (*ScannedCode, error) {
+	code = strings.TrimSpace(code)
+	scanned := &ScannedCode{Raw: code, Candidates: []string{code}}
+
+	if !isDigits(code) || (len(code) != 12 && len(code) != 13) {
+		return scanned, nil
+	}
+
+	// UPC-A is EAN-13 with a leading zero
+	ean := code
+	if len(code) == 12 {
+		ean = "0" + code
+		scanned.Candidates = append(scanned.Candidates, ean)
+	}
+
+	if CheckDigit(ean[:12]) != ean[12] {
+		scanned.Candidates = []string{code}
+		return scanned, ErrInvalidCheckDigit
+	}
+
+	if ean[0] != '2' {
+		return scanned, nil
+	}
+
+	// Weighted item: 2 digit prefix, 5 digit item code, 5 digit value, check digit
+	prefix, itemCode := ean[:2], ean[2:7]
+	value, _ := strconv.Atoi(ean[7:12])
+
+	scanned.Weighted = true
+	scanned.Candidates = append(scanned.Candidates, prefix+itemCode, itemCode)
+	if isWeightPrefix(prefix) {
+		weight := float64(value) / 1000
+		scanned.WeightKg = &weight
+	} else {
+		price := float64(value)
+		for i := 0; i < priceDecimals(); i++ {
+			price /= 10
+		}
+		scanned.Price = &price
+	}
+
+	return scanned, nil
+}
+
+// CheckDigit returns the EAN-13 check digit for the first 12 digits.
+func CheckDigit(digits string) byte {
+	sum := 0
+	for i, r := range digits {
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ValidEAN13 reports whether code is a 13 digit number with a valid check digit.
+func ValidEAN13(code string) bool {
+	return len(code) == 13 && isDigits(code) && CheckDigit(code[:12]) == code[12]
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isWeightPrefix reports whether a weighted barcode prefix embeds a weight in
+// grams rather than a price. Configured through WEIGHT_BARCODE_PREFIXES,
+// defaulting to 25-29.
+func isWeightPrefix(prefix string) bool {
+	prefixes := os.Getenv("WEIGHT_BARCODE_PREFIXES")
+	if prefixes == "" {
+		prefixes = "25,26,27,28,29"
+	}
+	for _, p := range strings.Split(prefixes, ",") {
+		if strings.TrimSpace(p) == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// priceDecimals is the number of implied decimals in an embedded price,
+// configured through BARCODE_PRICE_DECIMALS (0 for rupiah).
+func priceDecimals() int {
+	if v, err := strconv.Atoi(os.Getenv("BARCODE_PRICE_DECIMALS")); err == nil && v >= 0 && v <= 4 {
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/go-pdf/fpdf"
+)
+
+// EncodeBarcode encodes code as EAN-13 or Code128. With symbology "auto",
+// valid EAN-13 codes use EAN-13 and everything else uses Code128.
+func EncodeBarcode(code, symbology string) (barcode.Barcode, error) {
+	if symbology == "" || symbology == "auto" {
+		symbology = "code128"
+		if ValidEAN13(code) {
+			symbology = "ean13"
+		}
+	}
+
+	switch symbology {
+	case "ean13":
+		if !ValidEAN13(code) {
+			return nil, ErrInvalidCheckDigit
+		}
+		return ean.Encode(code)
+	case "code128":
+		return code128.Encode(code)
+	default:
+		return nil, fmt.Errorf("unsupported symbology %q", symbology)
+	}
+}
+
+// WriteBarcodePNG scales bc to width x height pixels and writes it as PNG.
+func WriteBarcodePNG(w io.Writer, bc barcode.Barcode, width, height int) error {
+	scaled, err := barcode.Scale(bc, width, height)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, scaled)
+}
+
+// WriteBarcodeSVG writes bc as an SVG with one rect per bar, so it stays
+// sharp at any print size.
+func WriteBarcodeSVG(w io.Writer, bc barcode.Barcode, width, height int) error {
+	modules := bc.Bounds().Dx()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" preserveAspectRatio="none" shape-rendering="crispEdges">`,
+		width, height, modules, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/>`, modules, height)
+	for _, bar := range bars(bc) {
+		fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d"/>`, bar[0], bar[1], height)
+	}
+	b.WriteString(`</svg>`)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// bars returns the start and width, in modules, of every dark bar.
+func bars(bc barcode.Barcode) [][2]int {
+	var result [][2]int
+	bounds := bc.Bounds()
+	start := -1
+	for x := bounds.Min.X; x <= bounds.Max.X; x++ {
+		dark := x < bounds.Max.X && isDark(bc.At(x, bounds.Min.Y))
+		if dark && start < 0 {
+			start = x
+		}
+		if !dark && start >= 0 {
+			result = append(result, [2]int{start - bounds.Min.X, x - start})
+			start = -1
+		}
+	}
+	return result
+}
+
+func isDark(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r+g+b < 3*0x8000
+}
+
+// Label is a single shelf label on a printable sheet.
+type Label struct {
+	Name    string
+	Detail  string
+	Price   float64
+	Code    string
+	Barcode barcode.Barcode
+}
+
+// Sheet layout for A4 with 3 x 7 labels of 63.5 x 38.1 mm (Avery L7160).
+const (
+	sheetColumns    = 3
+	sheetRows       = 7
+	labelWidth      = 63.5
+	labelHeight     = 38.1
+	sheetMarginLeft = 7.2
+	sheetMarginTop  = 15.1
+	labelGap        = 2.5
+	labelPadding    = 3.0
+)
+
+// WriteLabelSheet renders labels onto A4 label sheets as a PDF. Barcodes are
+// drawn as vector rectangles.
+func WriteLabelSheet(w io.Writer, labels []Label, currency string) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := sheetColumns * sheetRows
+	for i, label := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		slot := i % perPage
+		x := sheetMarginLeft + float64(slot%sheetColumns)*(labelWidth+labelGap)
+		y := sheetMarginTop + float64(slot/sheetColumns)*labelHeight
+		innerWidth := labelWidth - 2*labelPadding
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetXY(x+labelPadding, y+labelPadding)
+		pdf.CellFormat(innerWidth, 4, tr(fitText(pdf, label.Name, innerWidth)), "", 0, "L", false, 0, "")
+
+		if label.Detail != "" {
+			pdf.SetFont("Helvetica", "", 7)
+			pdf.SetXY(x+labelPadding, y+labelPadding+4)
+			pdf.CellFormat(innerWidth, 3.5, tr(fitText(pdf, label.Detail, innerWidth)), "", 0, "L", false, 0, "")
+		}
+
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.SetXY(x+labelPadding, y+labelPadding+8)
+		pdf.CellFormat(innerWidth, 5, tr(FormatPrice(label.Price, currency)), "", 0, "R", false, 0, "")
+
+		barY := y + labelPadding + 14
+		barHeight := 12.0
+		drawBars(pdf, label.Barcode, x+labelPadding, barY, innerWidth, barHeight)
+
+		pdf.SetFont("Courier", "", 7)
+		pdf.SetXY(x+labelPadding, barY+barHeight+0.5)
+		pdf.CellFormat(innerWidth, 3, label.Code, "", 0, "C", false, 0, "")
+	}
+
+	if len(labels) == 0 {
+		pdf.AddPage()
+	}
+
+	return pdf.Output(w)
+}
+
+func drawBars(pdf *fpdf.Fpdf, bc barcode.Barcode, x, y, width, height float64) {
+	modules := float64(bc.Bounds().Dx())
+	if modules == 0 {
+		return
+	}
+
+	pdf.SetFillColor(0, 0, 0)
+	moduleWidth := width / modules
+	for _, bar := range bars(bc) {
+		pdf.Rect(x+float64(bar[0])*moduleWidth, y, float64(bar[1])*moduleWidth, height, "F")
+	}
+}
+
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// FormatPrice formats a price with dot thousand separators, e.g. "Rp 25.000".
+// Fractions are only shown when the price is not a whole number.
+func FormatPrice(price float64, currency string) string {
+	whole := int64(math.Abs(price))
+	fraction := int64(math.Round((math.Abs(price) - float64(whole)) * 100))
+	if fraction == 100 {
+		whole++
+		fraction = 0
+	}
+
+	digits := strconv.FormatInt(whole, 10)
+	var grouped strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(r)
+	}
+
+	result := grouped.String()
+	if fraction > 0 {
+		result += fmt.Sprintf(",%02d", fraction)
+	}
+	if price < 0 {
+		result = "-" + result
+	}
+	if currency != "" {
+		result = currency + " " + result
+	}
+	return result
+}
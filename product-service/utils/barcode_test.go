@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"400638133393", '1'},
+		{"003600029145", '2'},
+		{"250012301250", '1'},
+		{"000000000000", '0'},
+	}
+
+	for _, tt := range tests {
+		if got := CheckDigit(tt.digits); got != tt.want {
+			t.Errorf("CheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestValidEAN13(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"4006381333932", false},
+		{"036000291452", false},
+		{"400638133393A", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidEAN13(tt.code); got != tt.want {
+			t.Errorf("ValidEAN13(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestParseScannedCode(t *testing.T) {
+	t.Setenv("WEIGHT_BARCODE_PREFIXES", "")
+	t.Setenv("BARCODE_PRICE_DECIMALS", "")
+
+	price := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name       string
+		code       string
+		candidates []string
+		weighted   bool
+		price      *float64
+		weightKg   *float64
+		err        error
+	}{
+		{
+			name:       "custom code",
+			code:       " SKU-001 ",
+			candidates: []string{"SKU-001"},
+		},
+		{
+			name:       "short numeric code",
+			code:       "12345",
+			candidates: []string{"12345"},
+		},
+		{
+			name:       "EAN-13",
+			code:       "4006381333931",
+			candidates: []string{"4006381333931"},
+		},
+		{
+			name:       "UPC-A is padded to EAN-13",
+			code:       "036000291452",
+			candidates: []string{"036000291452", "0036000291452"},
+		},
+		{
+			name:       "weight prefix",
+			code:       "2500123012501",
+			candidates: []string{"2500123012501", "2500123", "00123"},
+			weighted:   true,
+			weightKg:   price(1.25),
+		},
+		{
+			name:       "price prefix",
+			code:       "2012345150003",
+			candidates: []string{"2012345150003", "2012345", "12345"},
+			weighted:   true,
+			price:      price(15000),
+		},
+		{
+			name:       "bad EAN-13 check digit keeps the raw code",
+			code:       "4006381333932",
+			candidates: []string{"4006381333932"},
+			err:        ErrInvalidCheckDigit,
+		},
+		{
+			name:       "bad UPC-A check digit is a numeric SKU",
+			code:       "100000000001",
+			candidates: []string{"100000000001"},
+			err:        ErrInvalidCheckDigit,
+		},
+		{
+			name:       "bad check digit in the weighted range is not decoded",
+			code:       "2500123012509",
+			candidates: []string{"2500123012509"},
+			err:        ErrInvalidCheckDigit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanned, err := ParseScannedCode(tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(scanned.Candidates, tt.candidates) {
+				t.Errorf("Candidates = %q, want %q", scanned.Candidates, tt.candidates)
+			}
+			if scanned.Weighted != tt.weighted {
+				t.Errorf("Weighted = %v, want %v", scanned.Weighted, tt.weighted)
+			}
+			if !reflect.DeepEqual(scanned.Price, tt.price) {
+				t.Errorf("Price = %v, want %v", deref(scanned.Price), deref(tt.price))
+			}
+			if !reflect.DeepEqual(scanned.WeightKg, tt.weightKg) {
+				t.Errorf("WeightKg = %v, want %v", deref(scanned.WeightKg), deref(tt.weightKg))
+			}
+		})
+	}
+}
+
+func TestParseScannedCodeConfiguredPrefixes(t *testing.T) {
+	t.Setenv("WEIGHT_BARCODE_PREFIXES", "20, 21")
+	t.Setenv("BARCODE_PRICE_DECIMALS", "2")
+
+	weighed, err := ParseScannedCode("2012345150003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weighed.WeightKg == nil || *weighed.WeightKg != 15 || weighed.Price != nil {
+		t.Errorf("prefix 20 = weight %v price %v, want weight 15", deref(weighed.WeightKg), deref(weighed.Price))
+	}
+
+	priced, err := ParseScannedCode("2500123012501")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priced.Price == nil || *priced.Price != 12.5 || priced.WeightKg != nil {
+		t.Errorf("prefix 25 = price %v weight %v, want price 12.5", deref(priced.Price), deref(priced.WeightKg))
+	}
+}
+
+func deref(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -29,7 +29,13 @@ func InitDB() *gorm.DB {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	err = db.AutoMigrate(&models.User{})
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Shift{},
+		&models.TimeEntry{},
+		&models.TimeBreak{},
+		&models.OutboxEvent{},
+	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
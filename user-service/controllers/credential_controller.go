@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/user-service/dto"
+	"github.com/ridhotamma/yourkasa/user-service/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// CredentialController serves the internal API that auth-service uses instead
+// of reading the users table directly.
+type CredentialController struct {
+	db *gorm.DB
+}
+
+func NewCredentialController(db *gorm.DB) *CredentialController {
+	return &CredentialController{db: db}
+}
+
+func (c *CredentialController) Verify(ctx *gin.Context) {
+	var input dto.VerifyCredentialsDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := c.db.Where("email = ?", input.Email).First(&user).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	// Update last logged in
+	c.db.Model(&user).Update("last_logged_in", time.Now())
+
+	ctx.JSON(http.StatusOK, dto.UserIdentityDTO{
+		ID:    user.ID,
+		Email: user.Email,
+		Role:  string(user.Role),
+	})
+}
+
+func (c *CredentialController) GetIdentity(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var user models.User
+	if err := c.db.First(&user, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.UserIdentityDTO{
+		ID:    user.ID,
+		Email: user.Email,
+		Role:  string(user.Role),
+	})
+}
+
+func (c *CredentialController) ChangePassword(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.ChangePasswordDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := c.db.First(&user, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Verify current password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	if err := c.db.Model(&user).Update("password_hash", string(hashedPassword)).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
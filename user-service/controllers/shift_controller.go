@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/user-service/dto"
+	"github.com/ridhotamma/yourkasa/user-service/models"
+	"gorm.io/gorm"
+)
+
+type ShiftController struct {
+	db *gorm.DB
+}
+
+func NewShiftController(db *gorm.DB) *ShiftController {
+	return &ShiftController{db: db}
+}
+
+func (c *ShiftController) Create(ctx *gin.Context) {
+	var input dto.CreateShiftDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Verify employee exists
+	var user models.User
+	if err := c.db.First(&user, input.UserID).Error; err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+		return
+	}
+
+	overlaps, err := shiftOverlaps(c.db, input.UserID, input.StartAt, input.EndAt, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check shift overlaps"})
+		return
+	}
+	if overlaps {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Shift overlaps an existing shift for this user"})
+		return
+	}
+
+	shift := models.Shift{
+		UserID:  input.UserID,
+		Outlet:  input.Outlet,
+		Role:    input.Role,
+		StartAt: input.StartAt,
+		EndAt:   input.EndAt,
+		Notes:   input.Notes,
+	}
+
+	if err := c.db.Create(&shift).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shift"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "Shift created successfully", "id": shift.ID})
+}
+
+func (c *ShiftController) Update(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var input dto.UpdateShiftDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var shift models.Shift
+	if err := c.db.First(&shift, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+
+	startAt, endAt := shift.StartAt, shift.EndAt
+	if input.StartAt != nil {
+		startAt = *input.StartAt
+	}
+	if input.EndAt != nil {
+		endAt = *input.EndAt
+	}
+	if !endAt.After(startAt) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Shift end must be after its start"})
+		return
+	}
+	overlaps, err := shiftOverlaps(c.db, shift.UserID, startAt, endAt, shift.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check shift overlaps"})
+		return
+	}
+	if overlaps {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Shift overlaps an existing shift for this user"})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"start_at": startAt,
+		"end_at":   endAt,
+	}
+	if input.Outlet != "" {
+		updates["outlet"] = input.Outlet
+	}
+	if input.Role != "" {
+		updates["role"] = input.Role
+	}
+	if input.Notes != "" {
+		updates["notes"] = input.Notes
+	}
+
+	if err := c.db.Model(&shift).Updates(updates).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shift"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Shift updated successfully"})
+}
+
+func (c *ShiftController) Delete(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if err := c.db.Delete(&models.Shift{}, id).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete shift"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Shift deleted successfully"})
+}
+
+func (c *ShiftController) GetByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	var shift models.Shift
+	if err := c.db.First(&shift, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shift)
+}
+
+func (c *ShiftController) List(ctx *gin.Context) {
+	query := c.db.Model(&models.Shift{})
+
+	if userID := ctx.Query("userId"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if outlet := ctx.Query("outlet"); outlet != "" {
+		query = query.Where("outlet = ?", outlet)
+	}
+
+	from, to, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query = query.Where("start_at >= ? AND start_at < ?", from, to)
+
+	var shifts []models.Shift
+	if err := query.Order("start_at asc").Find(&shifts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shifts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shifts)
+}
+
+func (c *ShiftController) ListMine(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	from, to, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := c.db.Where("user_id = ? AND start_at >= ? AND start_at < ?", userID, from, to).
+		Order("start_at asc").
+		Find(&shifts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shifts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shifts)
+}
+
+// Helper functions
+func shiftOverlaps(db *gorm.DB, userID uint, startAt, endAt time.Time, excludeID uint) (bool, error) {
+	var exists bool
+	err := db.Model(&models.Shift{}).
+		Select("count(*) > 0").
+		Where("user_id = ? AND id <> ? AND start_at < ? AND end_at > ?", userID, excludeID, endAt, startAt).
+		Find(&exists).Error
+	return exists, err
+}
@@ -0,0 +1,472 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ridhotamma/yourkasa/user-service/dto"
+	"github.com/ridhotamma/yourkasa/user-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateLayout = "2006-01-02"
+
+type TimeClockController struct {
+	db *gorm.DB
+}
+
+func NewTimeClockController(db *gorm.DB) *TimeClockController {
+	return &TimeClockController{db: db}
+}
+
+func (c *TimeClockController) ClockIn(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	var input dto.ClockInDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := c.db.Begin()
+
+	// Lock the user so concurrent clock-ins cannot both open an entry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&models.User{}, userID).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if _, err := findOpenEntry(tx, userID); err == nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Already clocked in"})
+		return
+	}
+
+	entry := models.TimeEntry{
+		UserID:    userID,
+		ShiftID:   input.ShiftID,
+		Outlet:    input.Outlet,
+		ClockInAt: time.Now(),
+		Notes:     input.Notes,
+	}
+
+	// Link the scheduled shift, falling back to its outlet
+	if input.ShiftID != nil {
+		var shift models.Shift
+		if err := tx.Where("id = ? AND user_id = ?", *input.ShiftID, userID).First(&shift).Error; err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Shift not found"})
+			return
+		}
+		if entry.Outlet == "" {
+			entry.Outlet = shift.Outlet
+		}
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock in"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock in"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+func (c *TimeClockController) ClockOut(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	var input dto.ClockOutDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := findOpenEntry(c.db, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Not clocked in"})
+		return
+	}
+
+	now := time.Now()
+	tx := c.db.Begin()
+
+	// Close any break left running
+	if err := tx.Model(&models.TimeBreak{}).
+		Where("time_entry_id = ? AND end_at IS NULL", entry.ID).
+		Update("end_at", now).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end break"})
+		return
+	}
+
+	updates := map[string]interface{}{"clock_out_at": now}
+	if input.Notes != "" {
+		updates["notes"] = input.Notes
+	}
+
+	if err := tx.Model(entry).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock out"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clock out"})
+		return
+	}
+
+	c.db.Preload("Breaks").First(entry, entry.ID)
+	ctx.JSON(http.StatusOK, entry)
+}
+
+func (c *TimeClockController) StartBreak(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	var input dto.StartBreakDTO
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := findOpenEntry(c.db, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Not clocked in"})
+		return
+	}
+
+	for _, b := range entry.Breaks {
+		if b.EndAt == nil {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "A break is already in progress"})
+			return
+		}
+	}
+
+	timeBreak := models.TimeBreak{
+		TimeEntryID: entry.ID,
+		StartAt:     time.Now(),
+		Paid:        input.Paid,
+	}
+
+	if err := c.db.Create(&timeBreak).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start break"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, timeBreak)
+}
+
+func (c *TimeClockController) EndBreak(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	entry, err := findOpenEntry(c.db, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Not clocked in"})
+		return
+	}
+
+	var timeBreak models.TimeBreak
+	if err := c.db.Where("time_entry_id = ? AND end_at IS NULL", entry.ID).First(&timeBreak).Error; err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No break in progress"})
+		return
+	}
+
+	if err := c.db.Model(&timeBreak).Update("end_at", time.Now()).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end break"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timeBreak)
+}
+
+func (c *TimeClockController) Current(ctx *gin.Context) {
+	userID := ctx.GetUint("userId")
+
+	entry, err := findOpenEntry(c.db, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusOK, gin.H{"clockedIn": false})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entry"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"clockedIn": true, "entry": entry})
+}
+
+func (c *TimeClockController) ListEntries(ctx *gin.Context) {
+	from, to, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := c.db.Model(&models.TimeEntry{}).Where("clock_in_at >= ? AND clock_in_at < ?", from, to)
+	if userID := ctx.Query("userId"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if outlet := ctx.Query("outlet"); outlet != "" {
+		query = query.Where("outlet = ?", outlet)
+	}
+
+	var entries []models.TimeEntry
+	if err := query.Preload("Breaks").Order("clock_in_at asc").Find(&entries).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (c *TimeClockController) MyTimesheet(ctx *gin.Context) {
+	c.renderTimesheet(ctx, ctx.GetUint("userId"))
+}
+
+func (c *TimeClockController) Timesheet(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("userId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	c.renderTimesheet(ctx, uint(userID))
+}
+
+func (c *TimeClockController) renderTimesheet(ctx *gin.Context, userID uint) {
+	from, to, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := c.db.First(&user, userID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var entries []models.TimeEntry
+	if err := c.db.Where("user_id = ? AND clock_in_at >= ? AND clock_in_at < ?", userID, from, to).
+		Preload("Breaks").
+		Order("clock_in_at asc").
+		Find(&entries).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := c.db.Where("user_id = ? AND start_at >= ? AND start_at < ?", userID, from, to).
+		Find(&shifts).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shifts"})
+		return
+	}
+
+	timesheet := buildTimesheet(user, from, to, entries, shifts, time.Now())
+
+	if ctx.Query("format") == "csv" {
+		writeTimesheetCSV(ctx, timesheet)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timesheet)
+}
+
+// Helper functions
+func findOpenEntry(db *gorm.DB, userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := db.Where("user_id = ? AND clock_out_at IS NULL", userID).
+		Preload("Breaks").
+		First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// parseDateRange reads the inclusive from/to dates (YYYY-MM-DD) from the query
+// and returns them as a half-open [from, to) interval. It defaults to the
+// current month up to and including today.
+func parseDateRange(ctx *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	if v := ctx.Query("from"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date, expected YYYY-MM-DD")
+		}
+		from = t
+	}
+	if v := ctx.Query("to"); v != "" {
+		t, err := time.ParseInLocation(dateLayout, v, time.Local)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date, expected YYYY-MM-DD")
+		}
+		to = t
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to date must not be before from date")
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// overtimeThreshold is the number of hours per day after which worked time
+// counts as overtime, configurable through OVERTIME_DAILY_HOURS.
+func overtimeThreshold() float64 {
+	if v := os.Getenv("OVERTIME_DAILY_HOURS"); v != "" {
+		if hours, err := strconv.ParseFloat(v, 64); err == nil && hours > 0 {
+			return hours
+		}
+	}
+	return 8
+}
+
+func buildTimesheet(user models.User, from, to time.Time, entries []models.TimeEntry, shifts []models.Shift, now time.Time) dto.TimesheetDTO {
+	threshold := overtimeThreshold()
+	days := map[string]*dto.TimesheetDayDTO{}
+	var order []string
+
+	dayFor := func(t time.Time) *dto.TimesheetDayDTO {
+		key := t.In(time.Local).Format(dateLayout)
+		if day, ok := days[key]; ok {
+			return day
+		}
+		day := &dto.TimesheetDayDTO{Date: key}
+		days[key] = day
+		order = append(order, key)
+		return day
+	}
+
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		dayFor(d)
+	}
+
+	for _, shift := range shifts {
+		dayFor(shift.StartAt).ScheduledHours += shift.EndAt.Sub(shift.StartAt).Hours()
+	}
+
+	for _, entry := range entries {
+		day := dayFor(entry.ClockInAt)
+
+		end := now
+		if entry.ClockOutAt != nil {
+			end = *entry.ClockOutAt
+		} else {
+			day.OpenEntryExists = true
+		}
+
+		var unpaidBreaks float64
+		for _, b := range entry.Breaks {
+			breakEnd := end
+			if b.EndAt != nil {
+				breakEnd = *b.EndAt
+			}
+			hours := breakEnd.Sub(b.StartAt).Hours()
+			day.BreakHours += hours
+			if !b.Paid {
+				unpaidBreaks += hours
+			}
+		}
+
+		day.WorkedHours += end.Sub(entry.ClockInAt).Hours() - unpaidBreaks
+
+		clockIn := entry.ClockInAt.In(time.Local).Format("15:04")
+		if day.FirstClockIn == "" || clockIn < day.FirstClockIn {
+			day.FirstClockIn = clockIn
+		}
+		if entry.ClockOutAt != nil {
+			clockOut := entry.ClockOutAt.In(time.Local).Format("15:04")
+			if clockOut > day.LastClockOut {
+				day.LastClockOut = clockOut
+			}
+		}
+	}
+
+	timesheet := dto.TimesheetDTO{
+		UserID:    user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		From:      from.Format(dateLayout),
+		To:        to.AddDate(0, 0, -1).Format(dateLayout),
+		Days:      []dto.TimesheetDayDTO{},
+	}
+
+	for _, key := range order {
+		day := days[key]
+		day.RegularHours = day.WorkedHours
+		if day.WorkedHours > threshold {
+			day.RegularHours = threshold
+			day.OvertimeHours = day.WorkedHours - threshold
+		}
+
+		day.ScheduledHours = roundHours(day.ScheduledHours)
+		day.WorkedHours = roundHours(day.WorkedHours)
+		day.BreakHours = roundHours(day.BreakHours)
+		day.RegularHours = roundHours(day.RegularHours)
+		day.OvertimeHours = roundHours(day.OvertimeHours)
+
+		timesheet.ScheduledHours += day.ScheduledHours
+		timesheet.WorkedHours += day.WorkedHours
+		timesheet.BreakHours += day.BreakHours
+		timesheet.RegularHours += day.RegularHours
+		timesheet.OvertimeHours += day.OvertimeHours
+		timesheet.Days = append(timesheet.Days, *day)
+	}
+
+	timesheet.ScheduledHours = roundHours(timesheet.ScheduledHours)
+	timesheet.WorkedHours = roundHours(timesheet.WorkedHours)
+	timesheet.BreakHours = roundHours(timesheet.BreakHours)
+	timesheet.RegularHours = roundHours(timesheet.RegularHours)
+	timesheet.OvertimeHours = roundHours(timesheet.OvertimeHours)
+
+	return timesheet
+}
+
+func roundHours(hours float64) float64 {
+	return float64(int64(hours*100+0.5)) / 100
+}
+
+func writeTimesheetCSV(ctx *gin.Context, timesheet dto.TimesheetDTO) {
+	filename := fmt.Sprintf("timesheet_%d_%s_%s.csv", timesheet.UserID, timesheet.From, timesheet.To)
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+
+	hours := func(h float64) string { return strconv.FormatFloat(h, 'f', 2, 64) }
+	name := timesheet.FirstName + " " + timesheet.LastName
+
+	w := csv.NewWriter(ctx.Writer)
+	w.Write([]string{"employee_id", "employee_name", "email", "date", "first_clock_in", "last_clock_out",
+		"scheduled_hours", "worked_hours", "break_hours", "regular_hours", "overtime_hours"})
+	for _, day := range timesheet.Days {
+		w.Write([]string{
+			strconv.FormatUint(uint64(timesheet.UserID), 10), name, timesheet.Email, day.Date,
+			day.FirstClockIn, day.LastClockOut,
+			hours(day.ScheduledHours), hours(day.WorkedHours), hours(day.BreakHours),
+			hours(day.RegularHours), hours(day.OvertimeHours),
+		})
+	}
+	w.Write([]string{
+		strconv.FormatUint(uint64(timesheet.UserID), 10), name, timesheet.Email, "TOTAL", "", "",
+		hours(timesheet.ScheduledHours), hours(timesheet.WorkedHours), hours(timesheet.BreakHours),
+		hours(timesheet.RegularHours), hours(timesheet.OvertimeHours),
+	})
+	w.Flush()
+}
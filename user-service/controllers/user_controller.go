@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/ridhotamma/yourkasa/user-service/dto"
+	"github.com/ridhotamma/yourkasa/user-service/events"
 	"github.com/ridhotamma/yourkasa/user-service/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -40,11 +41,26 @@ func (c *UserController) Create(ctx *gin.Context) {
 		Role:              models.Role(input.Role),
 	}
 
-	if err := c.db.Create(&user).Error; err != nil {
+	tx := c.db.Begin()
+	if err := tx.Create(&user).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
+	if err := events.Record(tx, events.UserCreated, user.ID, events.UserCreatedPayload{
+		UserID:    user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      string(user.Role),
+	}); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record user event"})
+		return
+	}
+
+	tx.Commit()
 	ctx.JSON(http.StatusCreated, gin.H{"message": "User created successfully", "id": user.ID})
 }
 
@@ -62,6 +78,7 @@ func (c *UserController) Update(ctx *gin.Context) {
 		return
 	}
 
+	oldRole := user.Role
 	updates := map[string]interface{}{}
 	if input.FirstName != "" {
 		updates["first_name"] = input.FirstName
@@ -76,11 +93,27 @@ func (c *UserController) Update(ctx *gin.Context) {
 		updates["role"] = input.Role
 	}
 
-	if err := c.db.Model(&user).Updates(updates).Error; err != nil {
+	tx := c.db.Begin()
+	if err := tx.Model(&user).Updates(updates).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
 
+	// Notify other services so they can drop sessions carrying the old role
+	if input.Role != "" && input.Role != string(oldRole) {
+		if err := events.Record(tx, events.UserRoleChanged, user.ID, events.UserRoleChangedPayload{
+			UserID:  user.ID,
+			OldRole: string(oldRole),
+			NewRole: input.Role,
+		}); err != nil {
+			tx.Rollback()
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record user event"})
+			return
+		}
+	}
+
+	tx.Commit()
 	ctx.JSON(http.StatusOK, gin.H{"message": "User updated successfully"})
 }
 
@@ -130,11 +163,30 @@ func (c *UserController) GetByID(ctx *gin.Context) {
 
 func (c *UserController) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
-	if err := c.db.Delete(&models.User{}, id).Error; err != nil {
+	var user models.User
+	if err := c.db.First(&user, id).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	tx := c.db.Begin()
+	if err := tx.Delete(&user).Error; err != nil {
+		tx.Rollback()
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
 
+	if err := events.Record(tx, events.UserDeleted, user.ID, events.UserDeletedPayload{
+		UserID: user.ID,
+		Email:  user.Email,
+	}); err != nil {
+		tx.Rollback()
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record user event"})
+		return
+	}
+
+	tx.Commit()
+
 	ctx.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
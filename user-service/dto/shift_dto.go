@@ -0,0 +1,61 @@
+package dto
+
+import "time"
+
+type CreateShiftDTO struct {
+	UserID  uint      `json:"userId" binding:"required"`
+	Outlet  string    `json:"outlet" binding:"required"`
+	Role    string    `json:"role" binding:"required"`
+	StartAt time.Time `json:"startAt" binding:"required"`
+	EndAt   time.Time `json:"endAt" binding:"required,gtfield=StartAt"`
+	Notes   string    `json:"notes"`
+}
+
+type UpdateShiftDTO struct {
+	Outlet  string     `json:"outlet"`
+	Role    string     `json:"role"`
+	StartAt *time.Time `json:"startAt"`
+	EndAt   *time.Time `json:"endAt"`
+	Notes   string     `json:"notes"`
+}
+
+type ClockInDTO struct {
+	ShiftID *uint  `json:"shiftId"`
+	Outlet  string `json:"outlet"`
+	Notes   string `json:"notes"`
+}
+
+type ClockOutDTO struct {
+	Notes string `json:"notes"`
+}
+
+type StartBreakDTO struct {
+	Paid bool `json:"paid"`
+}
+
+type TimesheetDayDTO struct {
+	Date            string  `json:"date"`
+	FirstClockIn    string  `json:"firstClockIn"`
+	LastClockOut    string  `json:"lastClockOut"`
+	ScheduledHours  float64 `json:"scheduledHours"`
+	WorkedHours     float64 `json:"workedHours"`
+	BreakHours      float64 `json:"breakHours"`
+	RegularHours    float64 `json:"regularHours"`
+	OvertimeHours   float64 `json:"overtimeHours"`
+	OpenEntryExists bool    `json:"openEntryExists"`
+}
+
+type TimesheetDTO struct {
+	UserID         uint              `json:"userId"`
+	FirstName      string            `json:"firstName"`
+	LastName       string            `json:"lastName"`
+	Email          string            `json:"email"`
+	From           string            `json:"from"`
+	To             string            `json:"to"`
+	Days           []TimesheetDayDTO `json:"days"`
+	ScheduledHours float64           `json:"scheduledHours"`
+	WorkedHours    float64           `json:"workedHours"`
+	BreakHours     float64           `json:"breakHours"`
+	RegularHours   float64           `json:"regularHours"`
+	OvertimeHours  float64           `json:"overtimeHours"`
+}
@@ -37,3 +37,20 @@ type UserDetailDTO struct {
 	UpdatedAt         time.Time  `json:"updatedAt"`
 	LastLoggedIn      *time.Time `json:"lastLoggedIn"`
 }
+
+type VerifyCredentialsDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ChangePasswordDTO struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+}
+
+// UserIdentityDTO is the minimal view of a user shared with other services
+type UserIdentityDTO struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
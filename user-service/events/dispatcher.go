@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/user-service/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	dispatchBatchSize = 100
+	maxRetryDelay     = time.Hour
+	publishTimeout    = 10 * time.Second
+	claimLease        = dispatchBatchSize * publishTimeout // Long enough to publish a full batch
+)
+
+// Dispatcher relays unpublished outbox events to a transport in the order they
+// were recorded. A failed event is retried with exponential backoff and holds
+// back the events after it so consumers never see them out of order.
+type Dispatcher struct {
+	db        *gorm.DB
+	transport Transport
+	interval  time.Duration
+}
+
+func NewDispatcher(db *gorm.DB, transport Transport, interval time.Duration) *Dispatcher {
+	return &Dispatcher{db: db, transport: transport, interval: interval}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.dispatch(ctx); err != nil {
+			log.Printf("Failed to dispatch outbox events: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch claims a batch of due events in a short transaction, publishes them
+// without holding any row locks, then records each outcome in its own update.
+func (d *Dispatcher) dispatch(ctx context.Context) error {
+	rows, err := d.claim(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+		err := d.transport.Publish(publishCtx, fromOutbox(row))
+		cancel()
+
+		now := time.Now()
+		if err != nil {
+			log.Printf("Failed to publish event %s (%s): %v", row.EventID, row.EventType, err)
+			if err := d.db.Model(&row).Updates(map[string]interface{}{
+				"attempts":     row.Attempts + 1,
+				"next_attempt": now.Add(retryDelay(row.Attempts + 1)),
+				"last_error":   err.Error(),
+			}).Error; err != nil {
+				return err
+			}
+			return d.release(rows[i+1:])
+		}
+
+		if err := d.db.Model(&row).Updates(map[string]interface{}{
+			"published_at": now,
+			"attempts":     row.Attempts + 1,
+			"last_error":   "",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claim leases the leading run of due events by pushing their next attempt
+// past the lease. Other dispatchers wait on the row locks for the length of
+// this transaction only, then see the lease and back off, so events are never
+// published twice or out of order. A dispatcher that dies mid-batch leaves its
+// claimed events to be retried once the lease runs out.
+func (d *Dispatcher) claim(ctx context.Context) ([]models.OutboxEvent, error) {
+	var claimed []models.OutboxEvent
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("published_at IS NULL").
+			Order("id asc").
+			Limit(dispatchBatchSize).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		ids := make([]uint, 0, len(rows))
+		for _, row := range rows {
+			if row.NextAttempt.After(now) {
+				break
+			}
+			ids = append(ids, row.ID)
+			claimed = append(claimed, row)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt", now.Add(claimLease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// release hands back claimed events that were not attempted.
+func (d *Dispatcher) release(rows []models.OutboxEvent) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return d.db.Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Update("next_attempt", time.Now()).Error
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := time.Second << uint(attempts)
+	if delay <= 0 || delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
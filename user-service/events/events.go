@@ -0,0 +1,90 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ridhotamma/yourkasa/user-service/models"
+	"gorm.io/gorm"
+)
+
+const (
+	UserCreated     = "user.created"
+	UserRoleChanged = "user.role_changed"
+	UserDeleted     = "user.deleted"
+)
+
+// Event is the envelope delivered to every transport.
+type Event struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID uint            `json:"aggregateId"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+type UserCreatedPayload struct {
+	UserID    uint   `json:"userId"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Role      string `json:"role"`
+}
+
+type UserRoleChangedPayload struct {
+	UserID  uint   `json:"userId"`
+	OldRole string `json:"oldRole"`
+	NewRole string `json:"newRole"`
+}
+
+type UserDeletedPayload struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+}
+
+// Record stores an event in the outbox. Pass the transaction that performs the
+// change so the event is only published if the change commits.
+func Record(tx *gorm.DB, eventType string, aggregateID uint, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	id, err := newEventID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return tx.Create(&models.OutboxEvent{
+		EventID:     id,
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     string(data),
+		OccurredAt:  now,
+		NextAttempt: now,
+	}).Error
+}
+
+func fromOutbox(row models.OutboxEvent) Event {
+	return Event{
+		ID:          row.EventID,
+		Type:        row.EventType,
+		AggregateID: row.AggregateID,
+		OccurredAt:  row.OccurredAt,
+		Payload:     json.RawMessage(row.Payload),
+	}
+}
+
+// newEventID returns a random RFC 4122 version 4 UUID.
+func newEventID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
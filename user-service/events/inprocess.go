@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+type Handler func(ctx context.Context, event Event) error
+
+// InProcessTransport calls handlers registered in the same process. Handlers
+// subscribed to "*" receive every event.
+type InProcessTransport struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewInProcessTransport() *InProcessTransport {
+	return &InProcessTransport{handlers: map[string][]Handler{}}
+}
+
+func (t *InProcessTransport) Subscribe(eventType string, handler Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[eventType] = append(t.handlers[eventType], handler)
+}
+
+func (t *InProcessTransport) Publish(ctx context.Context, event Event) error {
+	t.mu.RLock()
+	handlers := append(append([]Handler{}, t.handlers[event.Type]...), t.handlers["*"]...)
+	t.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *InProcessTransport) Close() error {
+	return nil
+}
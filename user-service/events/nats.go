@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSTransport publishes each event to the subject "<prefix>.<event type>",
+// e.g. yourkasa.user.deleted.
+type NATSTransport struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+func NewNATSTransport(url, prefix string) (*NATSTransport, error) {
+	conn, err := nats.Connect(url, nats.Name("user-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSTransport{conn: conn, prefix: prefix}, nil
+}
+
+func (t *NATSTransport) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(t.prefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+
+	if err := t.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return t.conn.FlushWithContext(ctx)
+}
+
+func (t *NATSTransport) Close() error {
+	return t.conn.Drain()
+}
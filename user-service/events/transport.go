@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Transport delivers a single event to its consumers. Implementations must be
+// safe to call again with the same event, since the outbox retries on error.
+type Transport interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// NewTransportFromEnv builds the transport selected by EVENT_TRANSPORT
+// (inprocess, webhook or nats). It defaults to the in-process transport.
+func NewTransportFromEnv() (Transport, error) {
+	switch strings.ToLower(os.Getenv("EVENT_TRANSPORT")) {
+	case "", "inprocess":
+		return NewInProcessTransport(), nil
+	case "webhook":
+		var urls []string
+		for _, u := range strings.Split(os.Getenv("EVENT_WEBHOOK_URLS"), ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) == 0 {
+			return nil, fmt.Errorf("EVENT_WEBHOOK_URLS is required for the webhook transport")
+		}
+		return NewWebhookTransport(urls, os.Getenv("EVENT_WEBHOOK_SECRET")), nil
+	case "nats":
+		url := os.Getenv("NATS_URL")
+		if url == "" {
+			return nil, fmt.Errorf("NATS_URL is required for the nats transport")
+		}
+		prefix := os.Getenv("NATS_SUBJECT_PREFIX")
+		if prefix == "" {
+			prefix = "yourkasa"
+		}
+		return NewNATSTransport(url, prefix)
+	default:
+		return nil, fmt.Errorf("unknown EVENT_TRANSPORT %q", os.Getenv("EVENT_TRANSPORT"))
+	}
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookTransport POSTs each event as JSON to every configured URL. When a
+// secret is set the body is signed with HMAC-SHA256 in the X-Signature header.
+type WebhookTransport struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookTransport(urls []string, secret string) *WebhookTransport {
+	return &WebhookTransport{
+		urls:   urls,
+		secret: secret,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (t *WebhookTransport) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range t.urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Event-ID", event.ID)
+		req.Header.Set("X-Event-Type", event.Type)
+		if t.secret != "" {
+			req.Header.Set("X-Signature", "sha256="+Sign(t.secret, body))
+		}
+
+		resp, err := t.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("webhook %s: %w", url, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("webhook %s: unexpected status %d", url, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+func (t *WebhookTransport) Close() error {
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body using secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
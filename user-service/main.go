@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/ridhotamma/yourkasa/user-service/config"
+	"github.com/ridhotamma/yourkasa/user-service/events"
 	"github.com/ridhotamma/yourkasa/user-service/routes"
 	"github.com/ridhotamma/yourkasa/user-service/utils"
 )
@@ -49,6 +52,22 @@ func prometheusMiddleware() gin.HandlerFunc {
 
 func main() {
 	db := config.InitDB()
+
+	transport, err := events.NewTransportFromEnv()
+	if err != nil {
+		log.Fatal("Failed to set up event transport:", err)
+	}
+	defer transport.Close()
+
+	if inProcess, ok := transport.(*events.InProcessTransport); ok {
+		inProcess.Subscribe("*", func(_ context.Context, event events.Event) error {
+			log.Printf("Event %s %s for user %d", event.ID, event.Type, event.AggregateID)
+			return nil
+		})
+	}
+
+	go events.NewDispatcher(db, transport, 2*time.Second).Run(context.Background())
+
 	r := gin.Default()
 
 	r.Use(prometheusMiddleware())
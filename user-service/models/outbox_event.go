@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// that caused it and delivered to consumers asynchronously by the dispatcher.
+type OutboxEvent struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	EventID     string     `json:"eventId" gorm:"type:varchar(36);uniqueIndex;not null"`
+	EventType   string     `json:"eventType" gorm:"type:varchar(100);not null;index"`
+	AggregateID uint       `json:"aggregateId" gorm:"not null;index"`
+	Payload     string     `json:"payload" gorm:"type:jsonb;not null"`
+	OccurredAt  time.Time  `json:"occurredAt" gorm:"not null"`
+	PublishedAt *time.Time `json:"publishedAt" gorm:"index"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	NextAttempt time.Time  `json:"nextAttempt" gorm:"not null;index"`
+	LastError   string     `json:"lastError"`
+}
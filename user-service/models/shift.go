@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Shift struct {
+	gorm.Model
+	UserID  uint      `json:"userId" gorm:"not null;index"`
+	User    User      `json:"-"`
+	Outlet  string    `json:"outlet" gorm:"type:varchar(100);not null"`
+	Role    string    `json:"role" gorm:"type:varchar(50);not null"` // Role worked during the shift, e.g. cashier, barista
+	StartAt time.Time `json:"startAt" gorm:"not null;index"`
+	EndAt   time.Time `json:"endAt" gorm:"not null"`
+	Notes   string    `json:"notes"`
+}
+
+type TimeEntry struct {
+	gorm.Model
+	UserID     uint        `json:"userId" gorm:"not null;index;uniqueIndex:idx_time_entries_open,where:clock_out_at IS NULL AND deleted_at IS NULL"` // At most one open entry per user
+	User       User        `json:"-"`
+	ShiftID    *uint       `json:"shiftId"`
+	Shift      *Shift      `json:"shift,omitempty"`
+	Outlet     string      `json:"outlet" gorm:"type:varchar(100)"`
+	ClockInAt  time.Time   `json:"clockInAt" gorm:"not null;index"`
+	ClockOutAt *time.Time  `json:"clockOutAt"` // Nil while the employee is still on the clock
+	Breaks     []TimeBreak `json:"breaks"`
+	Notes      string      `json:"notes"`
+}
+
+type TimeBreak struct {
+	gorm.Model
+	TimeEntryID uint       `json:"timeEntryId" gorm:"not null;index"`
+	StartAt     time.Time  `json:"startAt" gorm:"not null"`
+	EndAt       *time.Time `json:"endAt"` // Nil while the break is in progress
+	Paid        bool       `json:"paid" gorm:"default:false"`
+}
@@ -9,6 +9,18 @@ import (
 
 func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 	userController := controllers.NewUserController(db)
+	shiftController := controllers.NewShiftController(db)
+	timeClockController := controllers.NewTimeClockController(db)
+	credentialController := controllers.NewCredentialController(db)
+
+	// Service-to-service routes, not exposed through the gateway
+	internal := r.Group("/internal")
+	internal.Use(middleware.RequireServiceToken())
+	{
+		internal.POST("/credentials/verify", credentialController.Verify)
+		internal.GET("/users/:id", credentialController.GetIdentity)
+		internal.PUT("/users/:id/password", credentialController.ChangePassword)
+	}
 
 	api := r.Group("/api/v1")
 	{
@@ -27,5 +39,52 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB) {
 				admin.DELETE("/:id", userController.Delete)
 			}
 		}
+
+		// Shift schedule routes
+		shifts := api.Group("/shifts")
+		shifts.Use(middleware.AuthMiddleware())
+		{
+			shifts.GET("/me", shiftController.ListMine)
+
+			managers := shifts.Group("/")
+			managers.Use(middleware.RequireRole("admin", "owner"))
+			{
+				managers.GET("/", shiftController.List)
+				managers.GET("/:id", shiftController.GetByID)
+				managers.POST("/", shiftController.Create)
+				managers.PUT("/:id", shiftController.Update)
+				managers.DELETE("/:id", shiftController.Delete)
+			}
+		}
+
+		// Time clock routes
+		timeclock := api.Group("/timeclock")
+		timeclock.Use(middleware.AuthMiddleware())
+		{
+			timeclock.GET("/current", timeClockController.Current)
+			timeclock.POST("/clock-in", timeClockController.ClockIn)
+			timeclock.POST("/clock-out", timeClockController.ClockOut)
+			timeclock.POST("/breaks/start", timeClockController.StartBreak)
+			timeclock.POST("/breaks/end", timeClockController.EndBreak)
+
+			managers := timeclock.Group("/")
+			managers.Use(middleware.RequireRole("admin", "owner"))
+			{
+				managers.GET("/entries", timeClockController.ListEntries)
+			}
+		}
+
+		// Timesheet routes
+		timesheets := api.Group("/timesheets")
+		timesheets.Use(middleware.AuthMiddleware())
+		{
+			timesheets.GET("/me", timeClockController.MyTimesheet)
+
+			managers := timesheets.Group("/")
+			managers.Use(middleware.RequireRole("admin", "owner"))
+			{
+				managers.GET("/:userId", timeClockController.Timesheet)
+			}
+		}
 	}
 }